@@ -0,0 +1,48 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+)
+
+// ID is the resolver for the id field.
+func (r *appPasswordResolver) ID(ctx context.Context, obj *models.AppPassword) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Password is the resolver for the password field.
+func (r *appPasswordResolver) Password(ctx context.Context, obj *models.AppPassword) (*string, error) {
+	if obj.Password == "" {
+		return nil, nil
+	}
+
+	return &obj.Password, nil
+}
+
+// CreateAppPassword is the resolver for the createAppPassword field.
+func (r *mutationResolver) CreateAppPassword(ctx context.Context, name string) (*models.AppPassword, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.AppPassword.Create(name, jwt.ID.String())
+}
+
+// DeleteAppPassword is the resolver for the deleteAppPassword field.
+func (r *mutationResolver) DeleteAppPassword(ctx context.Context, appPasswordID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.AppPassword.Delete(appPasswordID, jwt.ID.String())
+}
+
+// AppPasswords is the resolver for the appPasswords field.
+func (r *queryResolver) AppPasswords(ctx context.Context) ([]*models.AppPassword, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.AppPassword.List(jwt.ID.String())
+}
+
+// AppPassword returns generated.AppPasswordResolver implementation.
+func (r *Resolver) AppPassword() generated.AppPasswordResolver { return &appPasswordResolver{r} }
+
+type appPasswordResolver struct{ *Resolver }
@@ -0,0 +1,59 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+)
+
+// AssignTodo is the resolver for the assignTodo field.
+func (r *mutationResolver) AssignTodo(ctx context.Context, todoID string, userID string) (*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todo, err := r.UseCase.Todo.Assign(todoID, userID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	setUndoToken(ctx, todo.UndoToken)
+	return todo, nil
+}
+
+// UnassignTodo is the resolver for the unassignTodo field.
+func (r *mutationResolver) UnassignTodo(ctx context.Context, todoID string) (*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todo, err := r.UseCase.Todo.Unassign(todoID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	setUndoToken(ctx, todo.UndoToken)
+	return todo, nil
+}
+
+// AssignedToMe is the resolver for the assignedToMe field.
+func (r *queryResolver) AssignedToMe(ctx context.Context) ([]*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := todoUseCase.AssignedTo(jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// Assignee is the resolver for the assignee field.
+func (r *todoResolver) Assignee(ctx context.Context, obj *models.Todo) (*models.User, error) {
+	if obj.Assignee != nil || obj.AssigneeID == nil {
+		return obj.Assignee, nil
+	}
+
+	return r.UseCase.User.GetByID(obj.AssigneeID.String())
+}
@@ -0,0 +1,61 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// ID is the resolver for the id field.
+func (r *attachmentResolver) ID(ctx context.Context, obj *models.Attachment) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// URL is the resolver for the url field.
+func (r *attachmentResolver) URL(ctx context.Context, obj *models.Attachment) (string, error) {
+	return r.UseCase.Attachment.URL(obj)
+}
+
+// AttachFile is the resolver for the attachFile field.
+func (r *mutationResolver) AttachFile(ctx context.Context, todoID string, file graphql.Upload) (*models.Attachment, error) {
+	jwt := interactor.CtxValue(ctx)
+	attachment, err := r.UseCase.Attachment.Upload(todoID, file, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// DeleteAttachment is the resolver for the deleteAttachment field.
+func (r *mutationResolver) DeleteAttachment(ctx context.Context, attachmentID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	isDelete, err := r.UseCase.Attachment.Delete(attachmentID, jwt.ID.String())
+	if err != nil {
+		return isDelete, err
+	}
+
+	return isDelete, nil
+}
+
+// Attachments is the resolver for the attachments field.
+func (r *todoResolver) Attachments(ctx context.Context, obj *models.Todo) ([]*models.Attachment, error) {
+	jwt := interactor.CtxValue(ctx)
+	attachments, err := r.UseCase.Attachment.List(obj.ID.String(), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// Attachment returns generated.AttachmentResolver implementation.
+func (r *Resolver) Attachment() generated.AttachmentResolver { return &attachmentResolver{r} }
+
+type attachmentResolver struct{ *Resolver }
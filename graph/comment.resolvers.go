@@ -0,0 +1,84 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+	"todo-service/utils"
+)
+
+// ID is the resolver for the id field.
+func (r *commentResolver) ID(ctx context.Context, obj *models.Comment) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// CreateComment is the resolver for the createComment field.
+func (r *mutationResolver) CreateComment(ctx context.Context, todoID string, input model.NewComment) (*models.Comment, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	comment, err := r.UseCase.Comment.Create(todoID, input, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// UpdateComment is the resolver for the updateComment field.
+func (r *mutationResolver) UpdateComment(ctx context.Context, commentID string, input model.UpdateComment) (*models.Comment, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	comment, err := r.UseCase.Comment.Update(commentID, input, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, commentID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	isDelete, err := r.UseCase.Comment.Delete(commentID, jwt.ID.String())
+	if err != nil {
+		return isDelete, err
+	}
+
+	return isDelete, nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *todoResolver) Comments(ctx context.Context, obj *models.Todo, first *int, after *string, last *int, before *string) (*models.CommentConnection, error) {
+	jwt := interactor.CtxValue(ctx)
+	page := models.PageArgs{
+		First:  first,
+		After:  after,
+		Last:   last,
+		Before: before,
+	}
+
+	comments, err := r.UseCase.Comment.Paginate(obj.ID.String(), page, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// Comment returns generated.CommentResolver implementation.
+func (r *Resolver) Comment() generated.CommentResolver { return &commentResolver{r} }
+
+type commentResolver struct{ *Resolver }
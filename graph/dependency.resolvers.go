@@ -0,0 +1,54 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+)
+
+// AddTodoDependency is the resolver for the addTodoDependency field.
+func (r *mutationResolver) AddTodoDependency(ctx context.Context, blockerID string, blockedID string) (*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todo, err := r.UseCase.Todo.AddDependency(blockerID, blockedID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// RemoveTodoDependency is the resolver for the removeTodoDependency field.
+func (r *mutationResolver) RemoveTodoDependency(ctx context.Context, blockerID string, blockedID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	isRemove, err := r.UseCase.Todo.RemoveDependency(blockerID, blockedID, jwt.ID.String())
+	if err != nil {
+		return isRemove, err
+	}
+
+	return isRemove, nil
+}
+
+// BlockedBy is the resolver for the blockedBy field.
+func (r *todoResolver) BlockedBy(ctx context.Context, obj *models.Todo) ([]*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todos, err := r.UseCase.Todo.BlockedBy(obj.ID.String(), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// Blocks is the resolver for the blocks field.
+func (r *todoResolver) Blocks(ctx context.Context, obj *models.Todo) ([]*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todos, err := r.UseCase.Todo.Blocks(obj.ID.String(), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
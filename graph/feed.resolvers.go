@@ -0,0 +1,43 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+)
+
+// URL is the resolver for the url field.
+func (r *calendarFeedResolver) URL(ctx context.Context, obj *models.CalendarFeed) (*string, error) {
+	if obj.URL == "" {
+		return nil, nil
+	}
+
+	return &obj.URL, nil
+}
+
+// RegenerateCalendarFeed is the resolver for the regenerateCalendarFeed field.
+func (r *mutationResolver) RegenerateCalendarFeed(ctx context.Context) (*models.CalendarFeed, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.CalendarFeed.Regenerate(jwt.ID.String())
+}
+
+// RevokeCalendarFeed is the resolver for the revokeCalendarFeed field.
+func (r *mutationResolver) RevokeCalendarFeed(ctx context.Context) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.CalendarFeed.Revoke(jwt.ID.String())
+}
+
+// CalendarFeed is the resolver for the calendarFeed field.
+func (r *queryResolver) CalendarFeed(ctx context.Context) (*models.CalendarFeed, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.CalendarFeed.Get(jwt.ID.String())
+}
+
+// CalendarFeed returns generated.CalendarFeedResolver implementation.
+func (r *Resolver) CalendarFeed() generated.CalendarFeedResolver { return &calendarFeedResolver{r} }
+
+type calendarFeedResolver struct{ *Resolver }
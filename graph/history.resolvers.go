@@ -0,0 +1,41 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+)
+
+// History is the resolver for the history field.
+func (r *todoResolver) History(ctx context.Context, obj *models.Todo) ([]*models.TodoEvent, error) {
+	jwt := interactor.CtxValue(ctx)
+	events, err := r.UseCase.Todo.History(obj.ID.String(), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ID is the resolver for the id field.
+func (r *todoEventResolver) ID(ctx context.Context, obj *models.TodoEvent) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Field is the resolver for the field field.
+func (r *todoEventResolver) Field(ctx context.Context, obj *models.TodoEvent) (*string, error) {
+	if obj.Field == "" {
+		return nil, nil
+	}
+
+	return &obj.Field, nil
+}
+
+// TodoEvent returns generated.TodoEventResolver implementation.
+func (r *Resolver) TodoEvent() generated.TodoEventResolver { return &todoEventResolver{r} }
+
+type todoEventResolver struct{ *Resolver }
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// loaderWait is how long a loader collects keys before it fetches them, long
+// enough for the resolvers of the items of a list to ask for theirs.
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// loaders are the loaders of an operation, by the field they load.
+type loaders struct {
+	mu     sync.Mutex
+	byName map[string]*loader
+}
+
+// WithLoaders returns a context in which the lookups a field makes for every
+// item of a list are batched into a single fetch.
+func WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{byName: map[string]*loader{}})
+}
+
+// loader batches the keys asked for within loaderWait of each other.
+type loader struct {
+	fetch func(keys []string) (map[string]interface{}, error)
+	mu    sync.Mutex
+	batch *loaderBatch
+}
+
+type loaderBatch struct {
+	keys   []string
+	seen   map[string]bool
+	done   chan struct{}
+	values map[string]interface{}
+	err    error
+}
+
+// load returns the value fetch finds for key, fetching it together with the
+// keys the other resolvers of the operation ask the loader of name for. The
+// fetch of the first resolver to ask is used for the whole batch. Without
+// loaders in the context the key is fetched on its own.
+func load(ctx context.Context, name string, key string, fetch func(keys []string) (map[string]interface{}, error)) (interface{}, bool, error) {
+	ls, _ := ctx.Value(loadersKey{}).(*loaders)
+	if ls == nil {
+		values, err := fetch([]string{key})
+		if err != nil {
+			return nil, false, err
+		}
+
+		value, ok := values[key]
+		return value, ok, nil
+	}
+
+	ls.mu.Lock()
+	l := ls.byName[name]
+	if l == nil {
+		l = &loader{fetch: fetch}
+		ls.byName[name] = l
+	}
+	ls.mu.Unlock()
+
+	return l.load(key)
+}
+
+func (l *loader) load(key string) (interface{}, bool, error) {
+	l.mu.Lock()
+	batch := l.batch
+	if batch == nil {
+		batch = &loaderBatch{seen: map[string]bool{}, done: make(chan struct{})}
+		l.batch = batch
+		go l.run(batch)
+	}
+	if !batch.seen[key] {
+		batch.seen[key] = true
+		batch.keys = append(batch.keys, key)
+	}
+	l.mu.Unlock()
+
+	<-batch.done
+	if batch.err != nil {
+		return nil, false, batch.err
+	}
+
+	value, ok := batch.values[key]
+	return value, ok, nil
+}
+
+// run closes the batch to new keys after loaderWait and fetches its keys.
+func (l *loader) run(batch *loaderBatch) {
+	time.Sleep(loaderWait)
+
+	l.mu.Lock()
+	l.batch = nil
+	l.mu.Unlock()
+
+	batch.values, batch.err = l.fetch(batch.keys)
+	close(batch.done)
+}
@@ -2,19 +2,67 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"todo-service/src/models"
+)
+
 type Auth struct {
 	SignIn *SignInResult `json:"signIn"`
 	SignUp *SignUpResult `json:"signUp"`
 }
 
+type BulkTodoResult struct {
+	ID      string  `json:"id"`
+	Success bool    `json:"success"`
+	Error   *string `json:"error"`
+}
+
+type NewComment struct {
+	Body string `json:"body" validate:"required,min=1,max=10000"`
+}
+
+type NewTimeEntry struct {
+	Started time.Time `json:"started"`
+	Stopped time.Time `json:"stopped"`
+	Note    *string   `json:"note" validate:"omitempty,max=255"`
+}
+
 type NewTodo struct {
-	Text string `json:"text"`
+	Text     string               `json:"text"`
+	ParentID *string              `json:"parentId"`
+	DueAt    *time.Time           `json:"dueAt"`
+	Rrule    *string              `json:"rrule"`
+	Priority *models.TodoPriority `json:"priority"`
+	Tags     []string             `json:"tags" validate:"omitempty,max=32,dive,max=64"`
+	Parse    *bool                `json:"parse"`
+}
+
+type NewTodoTemplate struct {
+	Name  string                   `json:"name" validate:"required,min=1,max=128"`
+	Items []*TodoTemplateItemInput `json:"items" validate:"required,min=1,dive"`
 }
 
 type NewUser struct {
-	Name     string `json:"name" validate:"required,min=2,max=128"`
-	Email    string `json:"email" validate:"required,email,max=255"`
-	Password string `json:"password" validate:"required,min=6,max=64"`
+	Name     string  `json:"name" validate:"required,min=2,max=128"`
+	Email    string  `json:"email" validate:"required,email,max=255"`
+	Password string  `json:"password" validate:"required,min=6,max=64"`
+	Timezone *string `json:"timezone" validate:"omitempty,timezone"`
+}
+
+type NewWorkspace struct {
+	Name string `json:"name" validate:"required,min=1,max=128"`
+}
+
+type ParsedTodo struct {
+	Text     string              `json:"text"`
+	DueAt    *time.Time          `json:"dueAt"`
+	Priority models.TodoPriority `json:"priority"`
+	Tags     []string            `json:"tags"`
+	Rrule    *string             `json:"rrule"`
 }
 
 type SignInResult struct {
@@ -25,3 +73,219 @@ type SignInResult struct {
 type SignUpResult struct {
 	IsCreated bool `json:"isCreated"`
 }
+
+type TemplateVariable struct {
+	Name  string `json:"name" validate:"required,min=1,max=64"`
+	Value string `json:"value"`
+}
+
+type TodoColumnMapping struct {
+	Column string           `json:"column" validate:"required,max=255"`
+	Field  models.TodoField `json:"field"`
+}
+
+type TodoFilter struct {
+	Done          *bool      `json:"done"`
+	TextContains  *string    `json:"textContains"`
+	CreatedAfter  *time.Time `json:"createdAfter"`
+	CreatedBefore *time.Time `json:"createdBefore"`
+	UpdatedAfter  *time.Time `json:"updatedAfter"`
+	UpdatedBefore *time.Time `json:"updatedBefore"`
+	DueAfter      *time.Time `json:"dueAfter"`
+	DueBefore     *time.Time `json:"dueBefore"`
+	HasDueDate    *bool      `json:"hasDueDate"`
+	ParentID      *string    `json:"parentId"`
+	RootsOnly     *bool      `json:"rootsOnly"`
+	Recurring     *bool      `json:"recurring"`
+	SeriesID      *string    `json:"seriesId"`
+	Tag           *string    `json:"tag"`
+}
+
+type TodoImportResult struct {
+	DryRun   bool                  `json:"dryRun"`
+	Imported int                   `json:"imported"`
+	Updated  int                   `json:"updated"`
+	Failed   int                   `json:"failed"`
+	Errors   []*TodoImportRowError `json:"errors"`
+	Todos    []*models.Todo        `json:"todos"`
+}
+
+type TodoImportRowError struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+type TodoOrder struct {
+	Field     TodoSortField `json:"field"`
+	Direction SortDirection `json:"direction"`
+}
+
+type TodoPatch struct {
+	Text  *string    `json:"text" validate:"omitempty,min=1,max=255"`
+	Done  *bool      `json:"done"`
+	DueAt *time.Time `json:"dueAt"`
+}
+
+type TodoTemplateItemInput struct {
+	Text      string                   `json:"text" validate:"required,min=1,max=255"`
+	DueOffset *int                     `json:"dueOffset"`
+	Rrule     *string                  `json:"rrule"`
+	Priority  *models.TodoPriority     `json:"priority"`
+	Tags      []string                 `json:"tags" validate:"omitempty,max=32,dive,max=64"`
+	Children  []*TodoTemplateItemInput `json:"children" validate:"omitempty,dive"`
+}
+
+type UpdateComment struct {
+	Body string `json:"body" validate:"required,min=1,max=10000"`
+}
+
+type UpdateTimeEntry struct {
+	Started *time.Time `json:"started"`
+	Stopped *time.Time `json:"stopped"`
+	Note    *string    `json:"note" validate:"omitempty,max=255"`
+}
+
+type UpdateTodo struct {
+	Text     *string              `json:"text" validate:"omitempty,min=1,max=255"`
+	DueAt    *time.Time           `json:"dueAt"`
+	Rrule    *string              `json:"rrule"`
+	Priority *models.TodoPriority `json:"priority"`
+	Tags     []string             `json:"tags" validate:"omitempty,max=32,dive,max=64"`
+}
+
+type WorkflowStatusInput struct {
+	Name        string                `json:"name" validate:"required,min=1,max=64"`
+	Category    models.StatusCategory `json:"category"`
+	Transitions []string              `json:"transitions"`
+}
+
+type EditScope string
+
+const (
+	EditScopeThisOccurrence    EditScope = "THIS_OCCURRENCE"
+	EditScopeFutureOccurrences EditScope = "FUTURE_OCCURRENCES"
+)
+
+var AllEditScope = []EditScope{
+	EditScopeThisOccurrence,
+	EditScopeFutureOccurrences,
+}
+
+func (e EditScope) IsValid() bool {
+	switch e {
+	case EditScopeThisOccurrence, EditScopeFutureOccurrences:
+		return true
+	}
+	return false
+}
+
+func (e EditScope) String() string {
+	return string(e)
+}
+
+func (e *EditScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EditScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EditScope", str)
+	}
+	return nil
+}
+
+func (e EditScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TodoSortField string
+
+const (
+	TodoSortFieldCreated TodoSortField = "CREATED"
+	TodoSortFieldUpdated TodoSortField = "UPDATED"
+	TodoSortFieldText    TodoSortField = "TEXT"
+	TodoSortFieldDone    TodoSortField = "DONE"
+	TodoSortFieldDueAt   TodoSortField = "DUE_AT"
+	TodoSortFieldManual  TodoSortField = "MANUAL"
+)
+
+var AllTodoSortField = []TodoSortField{
+	TodoSortFieldCreated,
+	TodoSortFieldUpdated,
+	TodoSortFieldText,
+	TodoSortFieldDone,
+	TodoSortFieldDueAt,
+	TodoSortFieldManual,
+}
+
+func (e TodoSortField) IsValid() bool {
+	switch e {
+	case TodoSortFieldCreated, TodoSortFieldUpdated, TodoSortFieldText, TodoSortFieldDone, TodoSortFieldDueAt, TodoSortFieldManual:
+		return true
+	}
+	return false
+}
+
+func (e TodoSortField) String() string {
+	return string(e)
+}
+
+func (e *TodoSortField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TodoSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TodoSortField", str)
+	}
+	return nil
+}
+
+func (e TodoSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,21 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/model"
+	"todo-service/src/usecase/interactor"
+)
+
+// ParseTodo is the resolver for the parseTodo field.
+func (r *queryResolver) ParseTodo(ctx context.Context, text string) (*model.ParsedTodo, error) {
+	jwt := interactor.CtxValue(ctx)
+	parsed, err := r.UseCase.Todo.Parse(text, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
@@ -2,9 +2,55 @@
 package graph
 
 import (
+	"context"
 	"todo-service/src/registry"
+	"todo-service/src/usecase/interactor"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.uber.org/zap"
 )
 
 type Resolver struct {
 	UseCase registry.UseCase
+	Logger  *zap.Logger
+}
+
+// todoUseCase returns the todo use case of the workspace selected for the
+// request, for the resolvers that list or add todos.
+func (r *Resolver) todoUseCase(ctx context.Context) (interactor.TodoInteractor, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.Todo.InWorkspace(interactor.WorkspaceCtxValue(ctx), jwt.ID.String())
+}
+
+// Response extensions that map the name of a mutation in the response to
+// the undo token it returned or to the warnings it raised.
+const (
+	undoTokensExtension = "undoTokens"
+	warningsExtension   = "warnings"
+)
+
+// setUndoToken adds the undo token of the mutation being resolved to the
+// response.
+func setUndoToken(ctx context.Context, token string) {
+	if token != "" {
+		setFieldExtension(ctx, undoTokensExtension, token)
+	}
+}
+
+// setWarnings adds the warnings of the mutation being resolved to the
+// response.
+func setWarnings(ctx context.Context, warnings []string) {
+	if len(warnings) > 0 {
+		setFieldExtension(ctx, warningsExtension, warnings)
+	}
+}
+
+func setFieldExtension(ctx context.Context, extension string, value interface{}) {
+	values, _ := graphql.GetExtension(ctx, extension).(map[string]interface{})
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	values[graphql.GetFieldContext(ctx).Field.Alias] = value
+	graphql.RegisterExtension(ctx, extension, values)
 }
@@ -0,0 +1,94 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+)
+
+// ShareTodos is the resolver for the shareTodos field.
+func (r *mutationResolver) ShareTodos(ctx context.Context, todoIds []string, email string, role models.TodoRole) ([]*models.Share, error) {
+	jwt := interactor.CtxValue(ctx)
+	shares, err := r.UseCase.Share.Share(todoIds, email, role, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// UnshareTodo is the resolver for the unshareTodo field.
+func (r *mutationResolver) UnshareTodo(ctx context.Context, todoID string, userID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	isDelete, err := r.UseCase.Share.Unshare(todoID, userID, jwt.ID.String())
+	if err != nil {
+		return isDelete, err
+	}
+
+	return isDelete, nil
+}
+
+// ID is the resolver for the id field.
+func (r *shareResolver) ID(ctx context.Context, obj *models.Share) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// TodoID is the resolver for the todoId field.
+func (r *shareResolver) TodoID(ctx context.Context, obj *models.Share) (string, error) {
+	return obj.TodoID.String(), nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *todoResolver) Owner(ctx context.Context, obj *models.Todo) (*models.User, error) {
+	return obj.User, nil
+}
+
+// MyRole is the resolver for the myRole field.
+func (r *todoResolver) MyRole(ctx context.Context, obj *models.Todo) (models.TodoRole, error) {
+	jwt := interactor.CtxValue(ctx)
+	if obj.WorkspaceID == nil && obj.UserID == jwt.ID {
+		return models.TodoRoleOwner, nil
+	}
+
+	role, ok, err := load(ctx, "myRole", obj.ID.String(), func(ids []string) (map[string]interface{}, error) {
+		roles, err := r.UseCase.Todo.Roles(ids, jwt.ID.String())
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]interface{}, len(roles))
+		for id, role := range roles {
+			values[id] = role
+		}
+		return values, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", models.ErrTodoNotFound
+	}
+
+	return role.(models.TodoRole), nil
+}
+
+// Shares is the resolver for the shares field.
+func (r *todoResolver) Shares(ctx context.Context, obj *models.Todo) ([]*models.Share, error) {
+	jwt := interactor.CtxValue(ctx)
+	shares, err := r.UseCase.Share.List(obj.ID.String(), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// Share returns generated.ShareResolver implementation.
+func (r *Resolver) Share() generated.ShareResolver { return &shareResolver{r} }
+
+type shareResolver struct{ *Resolver }
@@ -0,0 +1,118 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+	"todo-service/graph/generated"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+	"todo-service/utils"
+)
+
+// CreateTodoTemplate is the resolver for the createTodoTemplate field.
+func (r *mutationResolver) CreateTodoTemplate(ctx context.Context, input model.NewTodoTemplate) (*models.TodoTemplate, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	template, err := r.UseCase.Template.Create(interactor.WorkspaceCtxValue(ctx), input, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// SaveTodoAsTemplate is the resolver for the saveTodoAsTemplate field.
+func (r *mutationResolver) SaveTodoAsTemplate(ctx context.Context, todoID string, name string) (*models.TodoTemplate, error) {
+	jwt := interactor.CtxValue(ctx)
+	template, err := r.UseCase.Template.SaveTodo(todoID, name, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// DeleteTodoTemplate is the resolver for the deleteTodoTemplate field.
+func (r *mutationResolver) DeleteTodoTemplate(ctx context.Context, templateID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.Template.Delete(templateID, jwt.ID.String())
+}
+
+// InstantiateTemplate is the resolver for the instantiateTemplate field.
+func (r *mutationResolver) InstantiateTemplate(ctx context.Context, templateID string, variables []*model.TemplateVariable, start *time.Time) ([]*models.Todo, error) {
+	for _, variable := range variables {
+		if err := utils.Validate(variable); err != nil {
+			return nil, err
+		}
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	todos, err := r.UseCase.Template.Instantiate(templateID, variables, start, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// TodoTemplates is the resolver for the todoTemplates field.
+func (r *queryResolver) TodoTemplates(ctx context.Context) ([]*models.TodoTemplate, error) {
+	jwt := interactor.CtxValue(ctx)
+	templates, err := r.UseCase.Template.List(interactor.WorkspaceCtxValue(ctx), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// ID is the resolver for the id field.
+func (r *todoTemplateResolver) ID(ctx context.Context, obj *models.TodoTemplate) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Rrule is the resolver for the rrule field.
+func (r *todoTemplateItemResolver) Rrule(ctx context.Context, obj *models.TemplateItem) (*string, error) {
+	if obj.RRule == "" {
+		return nil, nil
+	}
+
+	return &obj.RRule, nil
+}
+
+// Priority is the resolver for the priority field.
+func (r *todoTemplateItemResolver) Priority(ctx context.Context, obj *models.TemplateItem) (models.TodoPriority, error) {
+	if obj.Priority == "" {
+		return models.TodoPriorityNone, nil
+	}
+
+	return obj.Priority, nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *todoTemplateItemResolver) Tags(ctx context.Context, obj *models.TemplateItem) ([]string, error) {
+	if obj.Tags == nil {
+		return []string{}, nil
+	}
+
+	return obj.Tags, nil
+}
+
+// TodoTemplate returns generated.TodoTemplateResolver implementation.
+func (r *Resolver) TodoTemplate() generated.TodoTemplateResolver { return &todoTemplateResolver{r} }
+
+// TodoTemplateItem returns generated.TodoTemplateItemResolver implementation.
+func (r *Resolver) TodoTemplateItem() generated.TodoTemplateItemResolver {
+	return &todoTemplateItemResolver{r}
+}
+
+type todoTemplateResolver struct{ *Resolver }
+type todoTemplateItemResolver struct{ *Resolver }
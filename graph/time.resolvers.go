@@ -0,0 +1,143 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+	"todo-service/graph/generated"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+	"todo-service/utils"
+)
+
+// StartTimer is the resolver for the startTimer field.
+func (r *mutationResolver) StartTimer(ctx context.Context, todoID string) (*models.TimeEntry, error) {
+	jwt := interactor.CtxValue(ctx)
+	entry, err := r.UseCase.TimeEntry.Start(todoID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// StopTimer is the resolver for the stopTimer field.
+func (r *mutationResolver) StopTimer(ctx context.Context) (*models.TimeEntry, error) {
+	jwt := interactor.CtxValue(ctx)
+	entry, err := r.UseCase.TimeEntry.Stop(jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// AddTimeEntry is the resolver for the addTimeEntry field.
+func (r *mutationResolver) AddTimeEntry(ctx context.Context, todoID string, input model.NewTimeEntry) (*models.TimeEntry, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	entry, err := r.UseCase.TimeEntry.Create(todoID, input, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// UpdateTimeEntry is the resolver for the updateTimeEntry field.
+func (r *mutationResolver) UpdateTimeEntry(ctx context.Context, timeEntryID string, input model.UpdateTimeEntry) (*models.TimeEntry, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	entry, err := r.UseCase.TimeEntry.Update(timeEntryID, input, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// DeleteTimeEntry is the resolver for the deleteTimeEntry field.
+func (r *mutationResolver) DeleteTimeEntry(ctx context.Context, timeEntryID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	return r.UseCase.TimeEntry.Delete(timeEntryID, jwt.ID.String())
+}
+
+// RunningTimer is the resolver for the runningTimer field.
+func (r *queryResolver) RunningTimer(ctx context.Context) (*models.TimeEntry, error) {
+	jwt := interactor.CtxValue(ctx)
+	entry, err := r.UseCase.TimeEntry.Running(jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// TimeReport is the resolver for the timeReport field.
+func (r *queryResolver) TimeReport(ctx context.Context, from time.Time, to time.Time, groupBy models.TimeReportGroup) ([]*models.TimeReportRow, error) {
+	jwt := interactor.CtxValue(ctx)
+	rows, err := r.UseCase.TimeEntry.Report(from, to, groupBy, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// ID is the resolver for the id field.
+func (r *timeEntryResolver) ID(ctx context.Context, obj *models.TimeEntry) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Seconds is the resolver for the seconds field.
+func (r *timeEntryResolver) Seconds(ctx context.Context, obj *models.TimeEntry) (int, error) {
+	return int(obj.Duration(time.Now()) / time.Second), nil
+}
+
+// TimeSpent is the resolver for the timeSpent field.
+func (r *todoResolver) TimeSpent(ctx context.Context, obj *models.Todo) (int, error) {
+	seconds, _, err := load(ctx, "timeSpent", obj.ID.String(), func(ids []string) (map[string]interface{}, error) {
+		spent, err := r.UseCase.TimeEntry.TimeSpent(ids)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]interface{}, len(spent))
+		for id, seconds := range spent {
+			values[id] = seconds
+		}
+		return values, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	spent, _ := seconds.(int64)
+	return int(spent), nil
+}
+
+// TimeEntries is the resolver for the timeEntries field.
+func (r *todoResolver) TimeEntries(ctx context.Context, obj *models.Todo) ([]*models.TimeEntry, error) {
+	entries, err := r.UseCase.TimeEntry.ListByTodo(obj.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// TimeEntry returns generated.TimeEntryResolver implementation.
+func (r *Resolver) TimeEntry() generated.TimeEntryResolver { return &timeEntryResolver{r} }
+
+type timeEntryResolver struct{ *Resolver }
@@ -5,20 +5,52 @@ package graph
 
 import (
 	"context"
+	"time"
 	"todo-service/graph/generated"
 	"todo-service/graph/model"
 	"todo-service/src/models"
 	"todo-service/src/usecase/interactor"
+	"todo-service/utils"
+
+	"go.uber.org/zap"
 )
 
 // CreateTodo is the resolver for the createTodo field.
 func (r *mutationResolver) CreateTodo(ctx context.Context, input model.NewTodo) (*models.Todo, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := todoUseCase.Create(input, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	setUndoToken(ctx, todo.UndoToken)
+	return todo, nil
+}
+
+// UpdateTodo is the resolver for the updateTodo field.
+func (r *mutationResolver) UpdateTodo(ctx context.Context, todoID string, input model.UpdateTodo, scope model.EditScope) (*models.Todo, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
 	jwt := interactor.CtxValue(ctx)
-	todo, err := r.UseCase.Todo.Create(input, jwt.ID.String())
+	todo, err := r.UseCase.Todo.Update(todoID, input, scope, jwt.ID.String())
 	if err != nil {
 		return nil, err
 	}
 
+	setUndoToken(ctx, todo.UndoToken)
 	return todo, nil
 }
 
@@ -30,31 +62,199 @@ func (r *mutationResolver) MarkCompleteTodo(ctx context.Context, todoID string)
 		return nil, err
 	}
 
+	setUndoToken(ctx, todo.UndoToken)
+	setWarnings(ctx, todo.Warnings)
 	return todo, nil
 }
 
 // DeleteTodo is the resolver for the deleteTodo field.
 func (r *mutationResolver) DeleteTodo(ctx context.Context, todoID string) (bool, error) {
 	jwt := interactor.CtxValue(ctx)
-	isDelete, err := r.UseCase.Todo.Delete(todoID, jwt.ID.String())
+	isDelete, token, err := r.UseCase.Todo.Delete(todoID, jwt.ID.String())
 	if err != nil {
 		return isDelete, err
 	}
 
+	setUndoToken(ctx, token)
 	return isDelete, nil
 }
 
-// Todos is the resolver for the todos field.
-func (r *queryResolver) Todos(ctx context.Context) ([]*models.Todo, error) {
+// RestoreTodo is the resolver for the restoreTodo field.
+func (r *mutationResolver) RestoreTodo(ctx context.Context, todoID string) (*models.Todo, error) {
 	jwt := interactor.CtxValue(ctx)
-	todo, err := r.UseCase.Todo.List(jwt.ID.String())
+	todoUseCase, err := r.todoUseCase(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	todo, err := todoUseCase.Restore(todoID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	setUndoToken(ctx, todo.UndoToken)
 	return todo, nil
 }
 
+// EmptyTrash is the resolver for the emptyTrash field.
+func (r *mutationResolver) EmptyTrash(ctx context.Context) (int, error) {
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := todoUseCase.EmptyTrash(jwt.ID.String())
+	if err != nil {
+		return 0, err
+	}
+
+	// Attachments of the emptied todos go with them. Blobs that can't be
+	// removed now are retried by the trash purge job.
+	if _, err := r.UseCase.Attachment.Cleanup(); err != nil {
+		r.Logger.Error("Removing attachments failed.", zap.Error(err))
+		setWarnings(ctx, []string{models.ErrAttachmentCleanup.Message})
+	}
+
+	return count, nil
+}
+
+// BulkUpdateTodos is the resolver for the bulkUpdateTodos field.
+func (r *mutationResolver) BulkUpdateTodos(ctx context.Context, ids []string, patch model.TodoPatch) ([]*model.BulkTodoResult, error) {
+	err := utils.Validate(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := todoUseCase.BulkUpdate(ids, patch, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// BulkDeleteTodos is the resolver for the bulkDeleteTodos field.
+func (r *mutationResolver) BulkDeleteTodos(ctx context.Context, ids []string) ([]*model.BulkTodoResult, error) {
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := todoUseCase.BulkDelete(ids, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// MoveTodo is the resolver for the moveTodo field.
+func (r *mutationResolver) MoveTodo(ctx context.Context, todoID string, beforeID *string, afterID *string) (*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := todoUseCase.Move(todoID, beforeID, afterID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	setUndoToken(ctx, todo.UndoToken)
+	return todo, nil
+}
+
+// Undo is the resolver for the undo field.
+func (r *mutationResolver) Undo(ctx context.Context, token string) (*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todo, err := r.UseCase.Todo.Undo(token, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// Todos is the resolver for the todos field.
+func (r *queryResolver) Todos(ctx context.Context, filter *model.TodoFilter, orderBy []*model.TodoOrder, first *int, after *string, last *int, before *string) (*models.TodoConnection, error) {
+	jwt := interactor.CtxValue(ctx)
+	page := models.PageArgs{
+		First:  first,
+		After:  after,
+		Last:   last,
+		Before: before,
+	}
+
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := todoUseCase.Paginate(page, filter, orderBy, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// TodoTree is the resolver for the todoTree field.
+func (r *queryResolver) TodoTree(ctx context.Context, rootID *string) ([]*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := todoUseCase.Tree(rootID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// SearchTodos is the resolver for the searchTodos field.
+func (r *queryResolver) SearchTodos(ctx context.Context, query string, first *int) ([]*models.TodoSearchResult, error) {
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := todoUseCase.Search(query, first, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// TrashedTodos is the resolver for the trashedTodos field.
+func (r *queryResolver) TrashedTodos(ctx context.Context) ([]*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todoUseCase, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := todoUseCase.Trashed(jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
 // ID is the resolver for the id field.
 func (r *todoResolver) ID(ctx context.Context, obj *models.Todo) (string, error) {
 	return obj.ID.String(), nil
@@ -65,6 +265,107 @@ func (r *todoResolver) User(ctx context.Context, obj *models.Todo) (*models.User
 	return obj.User, nil
 }
 
+// ParentID is the resolver for the parentId field.
+func (r *todoResolver) ParentID(ctx context.Context, obj *models.Todo) (*string, error) {
+	if obj.ParentID == nil {
+		return nil, nil
+	}
+
+	parentID := obj.ParentID.String()
+	return &parentID, nil
+}
+
+// Children is the resolver for the children field.
+func (r *todoResolver) Children(ctx context.Context, obj *models.Todo) ([]*models.Todo, error) {
+	if obj.Children != nil {
+		return obj.Children, nil
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	children, err := r.UseCase.Todo.ListChildren(obj.ID.String(), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return children, nil
+}
+
+// Progress is the resolver for the progress field.
+func (r *todoResolver) Progress(ctx context.Context, obj *models.Todo) (float64, error) {
+	jwt := interactor.CtxValue(ctx)
+	if obj.Children != nil {
+		return obj.Progress(), nil
+	}
+
+	progress, ok, err := load(ctx, "progress", obj.ID.String(), func(ids []string) (map[string]interface{}, error) {
+		progress, err := r.UseCase.Todo.Progress(ids, jwt.ID.String())
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]interface{}, len(progress))
+		for id, value := range progress {
+			values[id] = value
+		}
+		return values, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return obj.Progress(), nil
+	}
+
+	return progress.(float64), nil
+}
+
+// Rrule is the resolver for the rrule field.
+func (r *todoResolver) Rrule(ctx context.Context, obj *models.Todo) (*string, error) {
+	if obj.RRule == "" {
+		return nil, nil
+	}
+
+	return &obj.RRule, nil
+}
+
+// SeriesID is the resolver for the seriesId field.
+func (r *todoResolver) SeriesID(ctx context.Context, obj *models.Todo) (*string, error) {
+	if obj.SeriesID == nil {
+		return nil, nil
+	}
+
+	seriesID := obj.SeriesID.String()
+	return &seriesID, nil
+}
+
+// DeletedAt is the resolver for the deletedAt field.
+func (r *todoResolver) DeletedAt(ctx context.Context, obj *models.Todo) (*time.Time, error) {
+	if !obj.Deleted.Valid {
+		return nil, nil
+	}
+
+	return &obj.Deleted.Time, nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *todoResolver) Tags(ctx context.Context, obj *models.Todo) ([]string, error) {
+	if obj.Tags == nil {
+		return []string{}, nil
+	}
+
+	return obj.Tags, nil
+}
+
+// UndoToken is the resolver for the undoToken field.
+func (r *todoResolver) UndoToken(ctx context.Context, obj *models.Todo) (*string, error) {
+	if obj.UndoToken == "" {
+		return nil, nil
+	}
+
+	return &obj.UndoToken, nil
+}
+
 // Todo returns generated.TodoResolver implementation.
 func (r *Resolver) Todo() generated.TodoResolver { return &todoResolver{r} }
 
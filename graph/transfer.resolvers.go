@@ -0,0 +1,53 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"strings"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+	"todo-service/utils"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// ImportTodos is the resolver for the importTodos field.
+func (r *mutationResolver) ImportTodos(ctx context.Context, file graphql.Upload, format models.TodoFileFormat, mapping []*model.TodoColumnMapping, dryRun *bool) (*model.TodoImportResult, error) {
+	for _, m := range mapping {
+		if err := utils.Validate(m); err != nil {
+			return nil, err
+		}
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	todos, err := r.todoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := todos.Import(file.File, format, mapping, dryRun != nil && *dryRun, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ExportTodos is the resolver for the exportTodos field.
+func (r *queryResolver) ExportTodos(ctx context.Context, format models.TodoFileFormat) (string, error) {
+	jwt := interactor.CtxValue(ctx)
+	todos, err := r.todoUseCase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var export strings.Builder
+	if err := todos.Export(format, &export, jwt.ID.String()); err != nil {
+		return "", err
+	}
+
+	return export.String(), nil
+}
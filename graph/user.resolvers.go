@@ -7,8 +7,31 @@ import (
 	"context"
 	"todo-service/graph/generated"
 	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
 )
 
+// SetTimezone is the resolver for the setTimezone field.
+func (r *mutationResolver) SetTimezone(ctx context.Context, timezone string) (*models.User, error) {
+	jwt := interactor.CtxValue(ctx)
+	user, err := r.UseCase.User.SetTimezone(jwt.ID.String(), timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetSearchLanguage is the resolver for the setSearchLanguage field.
+func (r *mutationResolver) SetSearchLanguage(ctx context.Context, language string) (*models.User, error) {
+	jwt := interactor.CtxValue(ctx)
+	user, err := r.UseCase.User.SetSearchLanguage(jwt.ID.String(), language)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // ID is the resolver for the id field.
 func (r *userResolver) ID(ctx context.Context, obj *models.User) (string, error) {
 	return obj.ID.String(), nil
@@ -0,0 +1,83 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+	"todo-service/utils"
+)
+
+// SetWorkflow is the resolver for the setWorkflow field.
+func (r *mutationResolver) SetWorkflow(ctx context.Context, statuses []*model.WorkflowStatusInput) ([]*models.WorkflowStatus, error) {
+	for _, status := range statuses {
+		if err := utils.Validate(status); err != nil {
+			return nil, err
+		}
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	workflow, err := r.UseCase.Workflow.Set(interactor.WorkspaceCtxValue(ctx), statuses, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// TransitionTodo is the resolver for the transitionTodo field.
+func (r *mutationResolver) TransitionTodo(ctx context.Context, todoID string, status string) (*models.Todo, error) {
+	jwt := interactor.CtxValue(ctx)
+	todo, err := r.UseCase.Todo.Transition(todoID, status, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	setUndoToken(ctx, todo.UndoToken)
+	setWarnings(ctx, todo.Warnings)
+	return todo, nil
+}
+
+// Workflow is the resolver for the workflow field.
+func (r *queryResolver) Workflow(ctx context.Context) ([]*models.WorkflowStatus, error) {
+	jwt := interactor.CtxValue(ctx)
+	workflow, err := r.UseCase.Workflow.Get(interactor.WorkspaceCtxValue(ctx), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// Status is the resolver for the status field.
+func (r *todoResolver) Status(ctx context.Context, obj *models.Todo) (*models.WorkflowStatus, error) {
+	return r.UseCase.Workflow.Status(obj)
+}
+
+// ID is the resolver for the id field.
+func (r *workflowStatusResolver) ID(ctx context.Context, obj *models.WorkflowStatus) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Transitions is the resolver for the transitions field.
+func (r *workflowStatusResolver) Transitions(ctx context.Context, obj *models.WorkflowStatus) ([]*models.WorkflowStatus, error) {
+	statuses := make([]*models.WorkflowStatus, 0, len(obj.Transitions))
+	for _, transition := range obj.Transitions {
+		if transition.To != nil {
+			statuses = append(statuses, transition.To)
+		}
+	}
+
+	return statuses, nil
+}
+
+// WorkflowStatus returns generated.WorkflowStatusResolver implementation.
+func (r *Resolver) WorkflowStatus() generated.WorkflowStatusResolver {
+	return &workflowStatusResolver{r}
+}
+
+type workflowStatusResolver struct{ *Resolver }
@@ -0,0 +1,148 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"todo-service/graph/generated"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+	"todo-service/utils"
+)
+
+// CreateWorkspace is the resolver for the createWorkspace field.
+func (r *mutationResolver) CreateWorkspace(ctx context.Context, input model.NewWorkspace) (*models.Workspace, error) {
+	err := utils.Validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt := interactor.CtxValue(ctx)
+	workspace, err := r.UseCase.Workspace.Create(input, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// InviteToWorkspace is the resolver for the inviteToWorkspace field.
+func (r *mutationResolver) InviteToWorkspace(ctx context.Context, workspaceID string, email string, role models.WorkspaceRole) (*models.WorkspaceInvitation, error) {
+	jwt := interactor.CtxValue(ctx)
+	invitation, err := r.UseCase.Workspace.Invite(workspaceID, email, role, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// AcceptWorkspaceInvitation is the resolver for the acceptWorkspaceInvitation field.
+func (r *mutationResolver) AcceptWorkspaceInvitation(ctx context.Context, invitationID string) (*models.Workspace, error) {
+	jwt := interactor.CtxValue(ctx)
+	workspace, err := r.UseCase.Workspace.AcceptInvitation(invitationID, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// SetWorkspaceMemberRole is the resolver for the setWorkspaceMemberRole field.
+func (r *mutationResolver) SetWorkspaceMemberRole(ctx context.Context, workspaceID string, userID string, role models.WorkspaceRole) (*models.WorkspaceMember, error) {
+	jwt := interactor.CtxValue(ctx)
+	member, err := r.UseCase.Workspace.SetMemberRole(workspaceID, userID, role, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// RemoveWorkspaceMember is the resolver for the removeWorkspaceMember field.
+func (r *mutationResolver) RemoveWorkspaceMember(ctx context.Context, workspaceID string, userID string) (bool, error) {
+	jwt := interactor.CtxValue(ctx)
+	isDelete, err := r.UseCase.Workspace.RemoveMember(workspaceID, userID, jwt.ID.String())
+	if err != nil {
+		return isDelete, err
+	}
+
+	return isDelete, nil
+}
+
+// SetWorkspaceSearchLanguage is the resolver for the setWorkspaceSearchLanguage field.
+func (r *mutationResolver) SetWorkspaceSearchLanguage(ctx context.Context, workspaceID string, language string) (*models.Workspace, error) {
+	jwt := interactor.CtxValue(ctx)
+	workspace, err := r.UseCase.Workspace.SetSearchLanguage(workspaceID, language, jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// Workspaces is the resolver for the workspaces field.
+func (r *queryResolver) Workspaces(ctx context.Context) ([]*models.Workspace, error) {
+	jwt := interactor.CtxValue(ctx)
+	workspaces, err := r.UseCase.Workspace.List(jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return workspaces, nil
+}
+
+// WorkspaceInvitations is the resolver for the workspaceInvitations field.
+func (r *queryResolver) WorkspaceInvitations(ctx context.Context) ([]*models.WorkspaceInvitation, error) {
+	jwt := interactor.CtxValue(ctx)
+	invitations, err := r.UseCase.Workspace.Invitations(jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// WorkspaceID is the resolver for the workspaceId field.
+func (r *todoResolver) WorkspaceID(ctx context.Context, obj *models.Todo) (*string, error) {
+	if obj.WorkspaceID == nil {
+		return nil, nil
+	}
+
+	workspaceID := obj.WorkspaceID.String()
+	return &workspaceID, nil
+}
+
+// ID is the resolver for the id field.
+func (r *workspaceResolver) ID(ctx context.Context, obj *models.Workspace) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Members is the resolver for the members field.
+func (r *workspaceResolver) Members(ctx context.Context, obj *models.Workspace) ([]*models.WorkspaceMember, error) {
+	jwt := interactor.CtxValue(ctx)
+	members, err := r.UseCase.Workspace.Members(obj.ID.String(), jwt.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// ID is the resolver for the id field.
+func (r *workspaceInvitationResolver) ID(ctx context.Context, obj *models.WorkspaceInvitation) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Workspace returns generated.WorkspaceResolver implementation.
+func (r *Resolver) Workspace() generated.WorkspaceResolver { return &workspaceResolver{r} }
+
+// WorkspaceInvitation returns generated.WorkspaceInvitationResolver implementation.
+func (r *Resolver) WorkspaceInvitation() generated.WorkspaceInvitationResolver {
+	return &workspaceInvitationResolver{r}
+}
+
+type workspaceResolver struct{ *Resolver }
+type workspaceInvitationResolver struct{ *Resolver }
@@ -0,0 +1,281 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+)
+
+var (
+	propResourceType   = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName    = xml.Name{Space: nsDAV, Local: "displayname"}
+	propUserPrincipal  = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL   = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propOwner          = xml.Name{Space: nsDAV, Local: "owner"}
+	propPrivileges     = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propReports        = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propSyncToken      = xml.Name{Space: nsDAV, Local: "sync-token"}
+	propETag           = xml.Name{Space: nsDAV, Local: "getetag"}
+	propContentType    = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propHomeSet        = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propUserAddressSet = xml.Name{Space: nsCalDAV, Local: "calendar-user-address-set"}
+	propComponentSet   = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData   = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propCTag           = xml.Name{Space: nsCS, Local: "getctag"}
+)
+
+// privileges are the privileges of the user on calendars and objects: every
+// todo of a list can be read and changed.
+const privileges = `<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>` +
+	`<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege>` +
+	`<d:privilege><d:unbind/></d:privilege>`
+
+const supportedReports = `<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>` +
+	`<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>` +
+	`<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>`
+
+// propValue is a property a resource has. Its value is only computed when it
+// is asked for, and hidden properties are left out of allprop.
+type propValue struct {
+	name   xml.Name
+	value  func() (string, error)
+	hidden bool
+}
+
+func constant(name xml.Name, inner string) propValue {
+	return propValue{name: name, value: func() (string, error) { return inner, nil }}
+}
+
+// propRequest is what properties a PROPFIND or REPORT asks for: the given
+// names, every property for allprop, or only the names for propname.
+type propRequest struct {
+	names     []xml.Name
+	all       bool
+	namesOnly bool
+}
+
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *element  `xml:"DAV: allprop"`
+	PropName *element  `xml:"DAV: propname"`
+	Prop     *propList `xml:"DAV: prop"`
+}
+
+// props writes the response of a resource with the properties asked for.
+func (m *multistatus) props(href string, values []propValue, req *propRequest) error {
+	found := make([]property, 0)
+	missing := make([]xml.Name, 0)
+
+	if req.all || req.namesOnly {
+		for _, v := range values {
+			if v.hidden {
+				continue
+			}
+			inner := ""
+			if !req.namesOnly {
+				var err error
+				if inner, err = v.value(); err != nil {
+					return err
+				}
+			}
+			found = append(found, property{v.name, inner})
+		}
+
+		m.response(href, found, missing)
+		return nil
+	}
+
+	for _, name := range req.names {
+		ok := false
+		for _, v := range values {
+			if v.name != name {
+				continue
+			}
+
+			inner, err := v.value()
+			if err != nil {
+				return err
+			}
+			found, ok = append(found, property{name, inner}), true
+			break
+		}
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	m.response(href, found, missing)
+	return nil
+}
+
+// common are the properties of every resource.
+func (s *session) common(values ...propValue) []propValue {
+	return append(values, constant(propUserPrincipal, href(principalPath)))
+}
+
+func (s *session) rootProps() []propValue {
+	return s.common(
+		constant(propResourceType, `<d:collection/>`),
+		constant(propHomeSet, href(homePath)),
+	)
+}
+
+func (s *session) principalProps() []propValue {
+	return s.common(
+		constant(propResourceType, `<d:principal/>`),
+		constant(propDisplayName, escape(s.user.Name)),
+		constant(propPrincipalURL, href(principalPath)),
+		constant(propHomeSet, href(homePath)),
+		constant(propUserAddressSet, href("mailto:"+s.user.Email)),
+	)
+}
+
+func (s *session) homeProps() []propValue {
+	return s.common(
+		constant(propResourceType, `<d:collection/>`),
+		constant(propOwner, href(principalPath)),
+	)
+}
+
+func (s *session) calendarProps(name string, todos interactor.TodoInteractor) []propValue {
+	token := func() (string, error) {
+		token, err := todos.CalendarSyncToken(s.user.ID.String())
+		return escape(token), err
+	}
+
+	return s.common(
+		constant(propResourceType, `<d:collection/><c:calendar/>`),
+		constant(propDisplayName, escape(name)),
+		constant(propOwner, href(principalPath)),
+		constant(propPrivileges, privileges),
+		constant(propReports, supportedReports),
+		constant(propComponentSet, `<c:comp name="VTODO"/>`),
+		propValue{name: propSyncToken, value: token},
+		propValue{name: propCTag, value: token},
+	)
+}
+
+func (s *session) objectProps(object *models.CalendarObject) []propValue {
+	return s.common(
+		constant(propResourceType, ""),
+		constant(propETag, escape(object.ETag)),
+		constant(propContentType, calendarContentType),
+		constant(propPrivileges, privileges),
+		propValue{name: propCalendarData, value: func() (string, error) { return escape(object.Data), nil }, hidden: true},
+	)
+}
+
+// readPropfind reads the properties a PROPFIND asks for, every property for
+// an empty body.
+func readPropfind(r *http.Request) (*propRequest, bool) {
+	var body propfindRequest
+	err := xml.NewDecoder(r.Body).Decode(&body)
+	if err == io.EOF {
+		return &propRequest{all: true}, true
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	switch {
+	case body.PropName != nil:
+		return &propRequest{namesOnly: true}, true
+	case body.Prop != nil:
+		return &propRequest{names: body.Prop.names()}, true
+	default:
+		return &propRequest{all: true}, true
+	}
+}
+
+// propfind answers the properties of a resource and, at a depth of 1, of its
+// members. An infinite depth is answered like a depth of 1.
+func (s *session) propfind(w http.ResponseWriter, r *http.Request, res *resource) {
+	req, ok := readPropfind(r)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	members := r.Header.Get("Depth") != "0"
+
+	switch res.kind {
+	case kindRoot:
+		m := newMultistatus(w)
+		_ = m.props(Prefix+"/", s.rootProps(), req)
+		_ = m.close("")
+
+	case kindPrincipal:
+		m := newMultistatus(w)
+		_ = m.props(principalPath, s.principalProps(), req)
+		_ = m.close("")
+
+	case kindHome:
+		lists, names, err := s.calendars()
+		if err != nil {
+			fail(w, err)
+			return
+		}
+
+		m := newMultistatus(w)
+		if err := m.props(homePath, s.homeProps(), req); err != nil || !members {
+			_ = m.close("")
+			return
+		}
+		for _, list := range lists {
+			todos, err := s.todos(list)
+			if err != nil {
+				break
+			}
+			if err := m.props(calendarHref(list), s.calendarProps(names[list], todos), req); err != nil {
+				break
+			}
+		}
+		_ = m.close("")
+
+	case kindCalendar:
+		_, names, err := s.calendars()
+		if err != nil {
+			fail(w, err)
+			return
+		}
+		name, ok := names[res.list]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		todos, err := s.todos(res.list)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+
+		m := newMultistatus(w)
+		if err := m.props(calendarHref(res.list), s.calendarProps(name, todos), req); err != nil || !members {
+			_ = m.close("")
+			return
+		}
+		_ = todos.CalendarObjects(s.user.ID.String(), func(object *models.CalendarObject) error {
+			return m.props(objectHref(res.list, object.UID), s.objectProps(object), req)
+		})
+		_ = m.close("")
+
+	case kindObject:
+		todos, err := s.todos(res.list)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+
+		object, err := todos.CalendarObject(res.uid, s.user.ID.String())
+		if err != nil {
+			fail(w, err)
+			return
+		}
+
+		m := newMultistatus(w)
+		_ = m.props(objectHref(res.list, object.UID), s.objectProps(object), req)
+		_ = m.close("")
+	}
+}
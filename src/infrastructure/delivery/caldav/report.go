@@ -0,0 +1,304 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+	"todo-service/src/models"
+	"todo-service/utils/ical"
+)
+
+const timeRangeFormat = "20060102T150405Z"
+
+// reportRequest is the body of a calendar-query, a calendar-multiget or a
+// sync-collection report.
+type reportRequest struct {
+	XMLName   xml.Name
+	AllProp   *element  `xml:"DAV: allprop"`
+	Prop      *propList `xml:"DAV: prop"`
+	Hrefs     []string  `xml:"DAV: href"`
+	Filter    *filter   `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	SyncToken string    `xml:"DAV: sync-token"`
+}
+
+type filter struct {
+	CompFilter *compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *element     `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	PropFilters  []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *element   `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+type textMatch struct {
+	Value  string `xml:",chardata"`
+	Negate string `xml:"negate-condition,attr"`
+}
+
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+func (req *reportRequest) props() *propRequest {
+	if req.Prop == nil {
+		return &propRequest{all: true}
+	}
+	return &propRequest{names: req.Prop.names()}
+}
+
+// report answers the reports of a calendar. A calendar-multiget may also be
+// sent to an object of the calendar.
+func (s *session) report(w http.ResponseWriter, r *http.Request, res *resource) {
+	var req reportRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if res.kind != kindCalendar && !(res.kind == kindObject && req.XMLName.Local == "calendar-multiget") {
+		davError(w, http.StatusForbidden, `<d:supported-report/>`)
+		return
+	}
+
+	todos, err := s.todos(res.list)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	userId := s.user.ID.String()
+
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		m := newMultistatus(w)
+		_ = todos.CalendarObjects(userId, func(object *models.CalendarObject) error {
+			if req.Filter != nil && req.Filter.CompFilter != nil && !req.Filter.CompFilter.matchCalendar(object.Todo) {
+				return nil
+			}
+			return m.props(objectHref(res.list, object.UID), s.objectProps(object), req.props())
+		})
+		_ = m.close("")
+
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		m := newMultistatus(w)
+		for _, link := range req.Hrefs {
+			target, ok := parsePath(strings.TrimSpace(link))
+			if !ok || target.kind != kindObject || target.list != res.list {
+				m.status(link, http.StatusNotFound)
+				continue
+			}
+
+			object, err := todos.CalendarObject(target.uid, userId)
+			if err != nil {
+				m.status(link, http.StatusNotFound)
+				continue
+			}
+			if err := m.props(objectHref(res.list, object.UID), s.objectProps(object), req.props()); err != nil {
+				break
+			}
+		}
+		_ = m.close("")
+
+	case xml.Name{Space: nsDAV, Local: "sync-collection"}:
+		// The response only starts with the first change, so that an invalid
+		// token can still be refused with 403.
+		var m *multistatus
+		token, err := todos.CalendarChanges(req.SyncToken, userId, func(object *models.CalendarObject) error {
+			if m == nil {
+				m = newMultistatus(w)
+			}
+
+			if object.Deleted {
+				m.status(objectHref(res.list, object.UID), http.StatusNotFound)
+				return nil
+			}
+			return m.props(objectHref(res.list, object.UID), s.objectProps(object), req.props())
+		})
+		if m == nil {
+			if err != nil {
+				fail(w, err)
+				return
+			}
+			m = newMultistatus(w)
+		}
+		if err != nil {
+			token = ""
+		}
+		_ = m.close(token)
+
+	default:
+		davError(w, http.StatusForbidden, `<d:supported-report/>`)
+	}
+}
+
+// matchCalendar tells whether the calendar of a todo passes a comp-filter,
+// which starts at the VCALENDAR.
+func (f *compFilter) matchCalendar(todo *models.Todo) bool {
+	if !strings.EqualFold(f.Name, "VCALENDAR") || f.IsNotDefined != nil {
+		return false
+	}
+
+	for _, cf := range f.CompFilters {
+		if !cf.matchTodo(todo) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchTodo tells whether the VTODO of a todo passes a comp-filter. Todos
+// have no other components, like VEVENT or VALARM.
+func (f *compFilter) matchTodo(todo *models.Todo) bool {
+	isTodo := strings.EqualFold(f.Name, "VTODO")
+	if f.IsNotDefined != nil {
+		return !isTodo
+	}
+	if !isTodo {
+		return false
+	}
+
+	if f.TimeRange != nil && !f.TimeRange.matchTodo(todo) {
+		return false
+	}
+
+	for _, pf := range f.PropFilters {
+		if !pf.match(todo) {
+			return false
+		}
+	}
+
+	for _, cf := range f.CompFilters {
+		if cf.IsNotDefined == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *propFilter) match(todo *models.Todo) bool {
+	value, at, defined := todoProperty(todo, strings.ToUpper(f.Name))
+	if f.IsNotDefined != nil {
+		return !defined
+	}
+	if !defined {
+		return false
+	}
+
+	if f.TimeRange != nil && (at == nil || !f.TimeRange.contains(*at)) {
+		return false
+	}
+
+	if f.TextMatch != nil {
+		matched := strings.Contains(strings.ToLower(value), strings.ToLower(f.TextMatch.Value))
+		if matched == strings.EqualFold(f.TextMatch.Negate, "yes") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// todoProperty returns the value of a property of the VTODO of a todo, and
+// its time for properties with a date, as far as the property is defined.
+func todoProperty(todo *models.Todo, name string) (string, *time.Time, bool) {
+	switch name {
+	case "UID":
+		return todo.CalendarUID(), nil, true
+	case "SUMMARY":
+		return todo.Text, nil, true
+	case "STATUS":
+		if todo.Done {
+			return ical.StatusCompleted, nil, true
+		}
+		return ical.StatusNeedsAction, nil, true
+	case "DUE":
+		return timeProperty(todo.DueAt)
+	case "COMPLETED":
+		if !todo.Done {
+			return "", nil, false
+		}
+		return timeProperty(todo.CompletedAt)
+	case "CREATED":
+		return timeProperty(&todo.Created)
+	case "RRULE":
+		return todo.RRule, nil, todo.RRule != ""
+	case "CATEGORIES":
+		return strings.Join(todo.Tags, ","), nil, len(todo.Tags) > 0
+	case "RELATED-TO":
+		return todo.ParentUID, nil, todo.ParentUID != ""
+	case "PRIORITY":
+		return "", nil, todo.Priority != "" && todo.Priority != models.TodoPriorityNone
+	default:
+		return "", nil, false
+	}
+}
+
+func timeProperty(t *time.Time) (string, *time.Time, bool) {
+	if t == nil {
+		return "", nil, false
+	}
+	return t.UTC().Format(timeRangeFormat), t, true
+}
+
+// matchTodo tells whether a todo overlaps the time range as in section 9.9
+// of RFC 4791: by its due date, otherwise by its creation and completion.
+func (tr *timeRange) matchTodo(todo *models.Todo) bool {
+	start, end, ok := tr.bounds()
+	if !ok {
+		return false
+	}
+
+	switch {
+	case todo.DueAt != nil:
+		return tr.contains(*todo.DueAt)
+	case todo.Done && todo.CompletedAt != nil:
+		return (!start.After(todo.Created) || !start.After(*todo.CompletedAt)) &&
+			(!end.Before(todo.Created) || !end.Before(*todo.CompletedAt))
+	default:
+		return end.After(todo.Created)
+	}
+}
+
+// contains tells whether a time is within the range, which includes its
+// start but not its end.
+func (tr *timeRange) contains(t time.Time) bool {
+	start, end, ok := tr.bounds()
+	return ok && !t.Before(start) && t.Before(end)
+}
+
+// bounds returns the start and end of the range, the range is open on a side
+// without a value.
+func (tr *timeRange) bounds() (time.Time, time.Time, bool) {
+	start, end := time.Unix(0, 0).AddDate(-2000, 0, 0), time.Unix(0, 0).AddDate(8000, 0, 0)
+
+	if tr.Start != "" {
+		t, err := time.Parse(timeRangeFormat, tr.Start)
+		if err != nil {
+			return start, end, false
+		}
+		start = t
+	}
+
+	if tr.End != "" {
+		t, err := time.Parse(timeRangeFormat, tr.End)
+		if err != nil {
+			return start, end, false
+		}
+		end = t
+	}
+
+	return start, end, true
+}
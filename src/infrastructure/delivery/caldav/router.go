@@ -0,0 +1,352 @@
+// Package caldav serves the todo lists of a user to CalDAV clients as
+// calendars of VTODO components, as defined by RFC 4791 and RFC 6578. The
+// personal todos and every workspace of the user are one calendar each, and
+// every todo is a calendar object named after its calendar UID. Clients sign
+// in with HTTP basic credentials: the email of the user and an app password.
+package caldav
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"todo-service/src/models"
+	"todo-service/src/registry"
+	"todo-service/src/usecase/interactor"
+
+	"github.com/labstack/echo"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	// Prefix is the path every CalDAV resource is found below.
+	Prefix = "/caldav"
+	// WellKnownPath redirects clients to Prefix as in RFC 6764.
+	WellKnownPath = "/.well-known/caldav"
+
+	principalPath = Prefix + "/principal/"
+	homePath      = Prefix + "/calendars/"
+	// personalList names the calendar of the personal todos, every other
+	// calendar is named after the id of its workspace.
+	personalList = "personal"
+	objectSuffix = ".ics"
+
+	// maxObjectSize is the largest calendar object a client may put.
+	maxObjectSize = 1 << 20
+)
+
+var allowedMethods = strings.Join([]string{
+	http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, "PROPFIND", "REPORT",
+}, ", ")
+
+// NewCalDAVRouter serves CalDAV below Prefix. Echo routes only a fixed set of
+// methods and REPORT isn't one of them, so CalDAV requests are picked up
+// before routing, which also keeps them clear of the bearer token middleware.
+func NewCalDAVRouter(e *echo.Echo, useCase registry.UseCase) {
+	h := &handler{useCase}
+
+	e.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			if path == WellKnownPath || path == Prefix || strings.HasPrefix(path, Prefix+"/") {
+				h.ServeHTTP(c.Response(), c.Request())
+				return nil
+			}
+
+			return next(c)
+		}
+	})
+}
+
+type handler struct {
+	useCase registry.UseCase
+}
+
+// session is a request of a signed in user.
+type session struct {
+	useCase registry.UseCase
+	user    *models.User
+}
+
+type resourceKind int
+
+const (
+	kindRoot resourceKind = iota
+	kindPrincipal
+	kindHome
+	kindCalendar
+	kindObject
+)
+
+// resource is what the path of a request points to. list is the calendar of
+// a calendar or an object, uid the calendar UID of an object.
+type resource struct {
+	kind resourceKind
+	list string
+	uid  string
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == WellKnownPath {
+		http.Redirect(w, r, Prefix+"/", http.StatusMovedPermanently)
+		return
+	}
+
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", allowedMethods)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	email, password, ok := r.BasicAuth()
+	if !ok {
+		unauthorized(w)
+		return
+	}
+
+	user, err := h.useCase.AppPassword.Authenticate(email, password)
+	if err == models.ErrAppPasswordInvalid {
+		unauthorized(w)
+		return
+	}
+	if err != nil {
+		fail(w, err)
+		return
+	}
+
+	res, ok := parsePath(r.URL.EscapedPath())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s := &session{h.useCase, user}
+	switch r.Method {
+	case "PROPFIND":
+		s.propfind(w, r, res)
+	case "REPORT":
+		s.report(w, r, res)
+	case http.MethodGet, http.MethodHead:
+		s.get(w, r, res)
+	case http.MethodPut:
+		s.put(w, r, res)
+	case http.MethodDelete:
+		s.delete(w, r, res)
+	default:
+		w.Header().Set("Allow", allowedMethods)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// parsePath resolves the escaped path of a request below Prefix.
+func parsePath(path string) (*resource, bool) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, Prefix), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		return &resource{kind: kindRoot}, true
+	case len(parts) == 1 && parts[0] == "principal":
+		return &resource{kind: kindPrincipal}, true
+	case parts[0] != "calendars":
+		return nil, false
+	case len(parts) == 1:
+		return &resource{kind: kindHome}, true
+	case len(parts) == 2:
+		return &resource{kind: kindCalendar, list: parts[1]}, true
+	case len(parts) == 3 && strings.HasSuffix(parts[2], objectSuffix):
+		uid, err := url.PathUnescape(strings.TrimSuffix(parts[2], objectSuffix))
+		if err != nil || uid == "" {
+			return nil, false
+		}
+		return &resource{kind: kindObject, list: parts[1], uid: uid}, true
+	default:
+		return nil, false
+	}
+}
+
+func calendarHref(list string) string {
+	return homePath + list + "/"
+}
+
+func objectHref(list string, uid string) string {
+	return calendarHref(list) + url.PathEscape(uid) + objectSuffix
+}
+
+// todos returns the todos of a calendar of the user.
+func (s *session) todos(list string) (interactor.TodoInteractor, error) {
+	workspaceId := list
+	if list == personalList {
+		workspaceId = ""
+	}
+
+	return s.useCase.Todo.InWorkspace(workspaceId, s.user.ID.String())
+}
+
+// calendars lists the calendars of the user by their names: the personal
+// todos first, then every workspace of the user.
+func (s *session) calendars() ([]string, map[string]string, error) {
+	workspaces, err := s.useCase.Workspace.List(s.user.ID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lists := []string{personalList}
+	names := map[string]string{personalList: "Todos"}
+	for _, workspace := range workspaces {
+		lists = append(lists, workspace.ID.String())
+		names[workspace.ID.String()] = workspace.Name
+	}
+
+	return lists, names, nil
+}
+
+func (s *session) get(w http.ResponseWriter, r *http.Request, res *resource) {
+	if res.kind != kindObject {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	todos, err := s.todos(res.list)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+
+	object, err := todos.CalendarObject(res.uid, s.user.ID.String())
+	if err != nil {
+		fail(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", calendarContentType)
+	w.Header().Set("ETag", object.ETag)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write([]byte(object.Data))
+	}
+}
+
+// put creates or replaces a calendar object, as long as If-Match and
+// If-None-Match hold for its current ETag. No ETag is returned since the
+// object is stored as a todo and reads back differently.
+func (s *session) put(w http.ResponseWriter, r *http.Request, res *resource) {
+	if res.kind != kindObject {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	todos, err := s.todos(res.list)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+
+	if !s.preconditions(w, r, todos, res) {
+		return
+	}
+
+	_, created, err := todos.PutCalendarObject(res.uid, http.MaxBytesReader(w, r.Body, maxObjectSize), s.user.ID.String())
+	switch {
+	case err == models.ErrImportFile:
+		davError(w, http.StatusBadRequest, `<c:valid-calendar-data/>`)
+	case errors.As(err, new(*gqlerror.Error)):
+		davError(w, http.StatusForbidden, `<c:valid-calendar-object-resource/>`)
+	case err != nil:
+		fail(w, err)
+	case created:
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *session) delete(w http.ResponseWriter, r *http.Request, res *resource) {
+	if res.kind != kindObject {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	todos, err := s.todos(res.list)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+
+	if !s.preconditions(w, r, todos, res) {
+		return
+	}
+
+	deleted, err := todos.DeleteCalendarObject(res.uid, s.user.ID.String())
+	switch {
+	case errors.As(err, new(*gqlerror.Error)):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		fail(w, err)
+	case !deleted:
+		http.NotFound(w, r)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// preconditions checks If-Match and If-None-Match against the current ETag
+// of an object and answers 412 when they don't hold.
+func (s *session) preconditions(w http.ResponseWriter, r *http.Request, todos interactor.TodoInteractor, res *resource) bool {
+	ifMatch, ifNoneMatch := r.Header.Get("If-Match"), r.Header.Get("If-None-Match")
+	if ifMatch == "" && ifNoneMatch == "" {
+		return true
+	}
+
+	etag := ""
+	object, err := todos.CalendarObject(res.uid, s.user.ID.String())
+	if err != nil && err != models.ErrCalendarObjectNotFound {
+		fail(w, err)
+		return false
+	}
+	if err == nil {
+		etag = object.ETag
+	}
+
+	if (ifMatch != "" && !matchETag(ifMatch, etag)) || (ifNoneMatch != "" && matchETag(ifNoneMatch, etag)) {
+		http.Error(w, http.StatusText(http.StatusPreconditionFailed), http.StatusPreconditionFailed)
+		return false
+	}
+
+	return true
+}
+
+// matchETag tells whether an If-Match or If-None-Match header matches the
+// ETag of an object, which is empty when there is no object.
+func matchETag(header string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="todo-service", charset="UTF-8"`)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// fail answers the errors of the interactors, 404 for what the user can't
+// find and 500 for everything unexpected.
+func fail(w http.ResponseWriter, err error) {
+	switch err {
+	case models.ErrWorkspaceNotFound, models.ErrCalendarObjectNotFound:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case models.ErrCalendarSyncToken:
+		davError(w, http.StatusForbidden, `<d:valid-sync-token/>`)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,144 @@
+package caldav
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	// nsCS is the namespace of the extensions of Apple's calendar server,
+	// like getctag.
+	nsCS = "http://calendarserver.org/ns/"
+
+	calendarContentType = "text/calendar; charset=utf-8; component=vtodo"
+	xmlContentType      = "application/xml; charset=utf-8"
+)
+
+// prefixes are the prefixes responses declare for the known namespaces.
+var prefixes = map[string]string{nsDAV: "d", nsCalDAV: "c", nsCS: "cs"}
+
+const xmlns = `xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/"`
+
+// element is an XML element of a request that only matters by its name.
+type element struct {
+	XMLName xml.Name
+}
+
+type propList struct {
+	Names []element `xml:",any"`
+}
+
+func (p *propList) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+
+	names := make([]xml.Name, len(p.Names))
+	for i, name := range p.Names {
+		names[i] = name.XMLName
+	}
+	return names
+}
+
+// property is a property of a response, with its value as inner XML.
+type property struct {
+	name  xml.Name
+	inner string
+}
+
+// multistatus streams the 207 response of a PROPFIND or REPORT.
+type multistatus struct {
+	w *bufio.Writer
+}
+
+func newMultistatus(w http.ResponseWriter) *multistatus {
+	w.Header().Set("Content-Type", xmlContentType)
+	w.WriteHeader(http.StatusMultiStatus)
+
+	m := &multistatus{bufio.NewWriter(w)}
+	m.w.WriteString(xml.Header + `<d:multistatus ` + xmlns + `>`)
+	return m
+}
+
+// response writes the properties of a resource: found with their values and
+// missing as not found.
+func (m *multistatus) response(href string, found []property, missing []xml.Name) {
+	m.w.WriteString(`<d:response><d:href>` + escape(href) + `</d:href>`)
+	if len(found) > 0 {
+		m.w.WriteString(`<d:propstat><d:prop>`)
+		for _, prop := range found {
+			writeElement(m.w, prop.name, prop.inner)
+		}
+		m.w.WriteString(`</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>`)
+	}
+	if len(missing) > 0 {
+		m.w.WriteString(`<d:propstat><d:prop>`)
+		for _, name := range missing {
+			writeElement(m.w, name, "")
+		}
+		m.w.WriteString(`</d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>`)
+	}
+	m.w.WriteString(`</d:response>`)
+}
+
+// status writes a response without properties, like a deleted object.
+func (m *multistatus) status(href string, code int) {
+	m.w.WriteString(`<d:response><d:href>` + escape(href) + `</d:href><d:status>` + statusLine(code) +
+		`</d:status></d:response>`)
+}
+
+// close ends the response, with the sync token of a sync-collection report
+// when it is not empty.
+func (m *multistatus) close(syncToken string) error {
+	if syncToken != "" {
+		m.w.WriteString(`<d:sync-token>` + escape(syncToken) + `</d:sync-token>`)
+	}
+	m.w.WriteString(`</d:multistatus>`)
+	return m.w.Flush()
+}
+
+// writeElement writes an element in the prefix of its namespace, or with a
+// namespace of its own when the namespace has no prefix.
+func writeElement(w io.StringWriter, name xml.Name, inner string) {
+	tag, attr := name.Local, ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag, attr = "x:"+name.Local, ` xmlns:x="`+escape(name.Space)+`"`
+	}
+
+	if inner == "" {
+		w.WriteString("<" + tag + attr + "/>")
+		return
+	}
+	w.WriteString("<" + tag + attr + ">" + inner + "</" + tag + ">")
+}
+
+// davError answers a failed precondition of RFC 4918 or RFC 4791, given as
+// the element inside DAV:error.
+func davError(w http.ResponseWriter, code int, condition string) {
+	w.Header().Set("Content-Type", xmlContentType)
+	w.WriteHeader(code)
+	_, _ = io.WriteString(w, xml.Header+`<d:error `+xmlns+`>`+condition+`</d:error>`)
+}
+
+func statusLine(code int) string {
+	return "HTTP/1.1 " + strconv.Itoa(code) + " " + http.StatusText(code)
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// href is the inner XML of a property holding a link.
+func href(link string) string {
+	return `<d:href>` + escape(link) + `</d:href>`
+}
@@ -1,20 +1,41 @@
 package graphql
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
 	"todo-service/graph"
 	"todo-service/graph/generated"
+	"todo-service/src/models"
 	"todo-service/src/registry"
+	"todo-service/src/usecase/interactor"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
-func NewGraphqlRouter(e *echo.Echo, useCase registry.UseCase) {
+// exportContentTypes are the content types of the formats of an export.
+var exportContentTypes = map[models.TodoFileFormat]string{
+	models.TodoFileFormatCSV:  "text/csv; charset=utf-8",
+	models.TodoFileFormatJSON: echo.MIMEApplicationJSONCharsetUTF8,
+	models.TodoFileFormatICS:  "text/calendar; charset=utf-8",
+	// todo.txt files are plain text by name.
+	models.TodoFileFormatTodoTxt: "text/plain; charset=utf-8",
+}
+
+func NewGraphqlRouter(e *echo.Echo, useCase registry.UseCase, logger *zap.Logger) {
 
 	// CORS
 	e.Use(middleware.CORS())
@@ -25,18 +46,124 @@ func NewGraphqlRouter(e *echo.Echo, useCase registry.UseCase) {
 		// Resolvers
 		Resolvers: &graph.Resolver{
 			UseCase: useCase,
+			Logger:  logger,
 		},
 	}
 
 	gqConf.Directives.Auth = Auth
 
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(gqConf))
+	// Same setup as handler.NewDefaultServer, with multipart uploads limited
+	// to the attachment size plus some room for the rest of the form.
+	srv := handler.New(generated.NewExecutableSchema(gqConf))
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{
+		MaxUploadSize: viper.GetInt64("attachments.max_size") + 1<<20,
+		MaxMemory:     32 << 20,
+	})
+	srv.SetQueryCache(lru.New(1000))
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New(100),
+	})
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(graph.WithLoaders(ctx))
+	})
 
 	apiV1 := e.Group("/api/v1")
 	{
 		apiV1.GET("/health-check", func(c echo.Context) error {
 			return c.String(http.StatusOK, viper.GetString("server_name"))
 		})
+
+		// Signed attachment downloads of the local blob store
+		apiV1.GET("/files/*", func(c echo.Context) error {
+			attachment, content, err := useCase.Attachment.Open(c.Param("*"), c.QueryParam("expires"), c.QueryParam("signature"))
+			if err != nil {
+				return c.String(http.StatusNotFound, "not found")
+			}
+			defer content.Close()
+
+			c.Response().Header().Set(echo.HeaderContentDisposition,
+				mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+			c.Response().Header().Set(echo.HeaderContentType, attachment.ContentType)
+			c.Response().WriteHeader(http.StatusOK)
+
+			_, err = io.Copy(c.Response(), content)
+			return err
+		})
+
+		// Streamed export of the todos of the user or of the workspace in the
+		// X-Workspace-ID header, ?format=csv, ?format=json, ?format=ics or
+		// ?format=todo_txt
+		apiV1.GET("/todos/export", func(c echo.Context) error {
+			jwt := interactor.CtxValue(c.Request().Context())
+			if jwt == nil {
+				return c.String(http.StatusUnauthorized, "unauthorized")
+			}
+
+			format := models.TodoFileFormat(strings.ToUpper(c.QueryParam("format")))
+			contentType, ok := exportContentTypes[format]
+			if !ok {
+				return c.String(http.StatusBadRequest, models.ErrTransferFormat.Message)
+			}
+
+			todos, err := useCase.Todo.InWorkspace(interactor.WorkspaceCtxValue(c.Request().Context()), jwt.ID.String())
+			if err != nil {
+				return c.String(http.StatusNotFound, "not found")
+			}
+
+			filename := "todos." + strings.ToLower(string(format))
+			if format == models.TodoFileFormatTodoTxt {
+				filename = "todo.txt"
+			}
+			c.Response().Header().Set(echo.HeaderContentDisposition,
+				mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+			c.Response().Header().Set(echo.HeaderContentType, contentType)
+			c.Response().WriteHeader(http.StatusOK)
+
+			return todos.Export(format, c.Response(), jwt.ID.String())
+		})
+
+		// Calendar subscription to the open todos behind the secret token of a
+		// feed link, without a bearer token. ?workspace=<id> selects the todos
+		// of a workspace and ?tag=<tag> the todos with a tag.
+		apiV1.GET("/feeds/:token", func(c echo.Context) error {
+			token := c.Param("token")
+			if !strings.HasSuffix(token, models.CalendarFeedExtension) {
+				return c.String(http.StatusNotFound, "not found")
+			}
+
+			version, write, err := useCase.CalendarFeed.Open(strings.TrimSuffix(token, models.CalendarFeedExtension),
+				c.QueryParam("workspace"), c.QueryParam("tag"))
+			if err == models.ErrCalendarFeedNotFound || err == models.ErrWorkspaceNotFound {
+				return c.String(http.StatusNotFound, "not found")
+			}
+			if err != nil {
+				return err
+			}
+
+			modified := version.Updated.UTC().Truncate(time.Second)
+			etag := fmt.Sprintf(`"%x-%x"`, version.Updated.UnixNano(), version.Count)
+			c.Response().Header().Set("ETag", etag)
+			if !modified.IsZero() {
+				c.Response().Header().Set(echo.HeaderLastModified, modified.Format(http.TimeFormat))
+			}
+			c.Response().Header().Set("Cache-Control", "private, no-cache")
+
+			if notModified(c.Request(), etag, modified) {
+				return c.NoContent(http.StatusNotModified)
+			}
+
+			c.Response().Header().Set(echo.HeaderContentType, exportContentTypes[models.TodoFileFormatICS])
+			c.Response().WriteHeader(http.StatusOK)
+
+			return write(c.Response())
+		})
 	}
 
 	// Main handler
@@ -57,3 +184,25 @@ func NewGraphqlRouter(e *echo.Echo, useCase registry.UseCase) {
 		return nil
 	})
 }
+
+// notModified tells whether the client already has the version of a response
+// with the given ETag and modification time. If-None-Match takes precedence
+// over If-Modified-Since as in RFC 9110.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+
+	return !modified.After(since)
+}
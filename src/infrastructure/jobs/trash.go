@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"time"
+	"todo-service/src/usecase/interactor"
+
+	"go.uber.org/zap"
+)
+
+// PurgeTrash removes expired todos from the trash, along with their
+// attachments, every interval until the context is cancelled.
+func PurgeTrash(ctx context.Context, logger *zap.Logger, todos interactor.TodoInteractor,
+	attachments interactor.AttachmentInteractor, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := todos.PurgeTrash()
+		if err != nil {
+			logger.Error("Purging trash failed.", zap.Error(err))
+		} else if purged > 0 {
+			logger.Info("Purged trash.", zap.Int64("todos", purged))
+		}
+
+		removed, err := attachments.Cleanup()
+		if err != nil {
+			logger.Error("Removing attachments failed.", zap.Error(err))
+		} else if removed > 0 {
+			logger.Info("Removed attachments.", zap.Int("attachments", removed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
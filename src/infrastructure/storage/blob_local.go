@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// LocalBlobStore keeps blobs as files below a directory. Its signed links
+// point at the files route of this service.
+type LocalBlobStore struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalBlobStore(dir string, baseURL string, secret string) *LocalBlobStore {
+	return &LocalBlobStore{
+		dir:     dir,
+		baseURL: baseURL,
+		secret:  []byte(secret),
+	}
+}
+
+// path maps a key below the store directory, so that a key can never point
+// outside of it.
+func (s *LocalBlobStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.Clean("/"+key))
+}
+
+func (s *LocalBlobStore) Put(key string, content io.Reader, size int64, contentType string) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, io.LimitReader(content, size)); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalBlobStore) Get(key string) (io.ReadCloser, error) {
+	return os.Open(s.path(key))
+}
+
+func (s *LocalBlobStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalBlobStore) SignedURL(key string, filename string, lifetime time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(lifetime).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+
+	return s.baseURL + "/api/v1/files/" + key + "?" + query.Encode(), nil
+}
+
+func (s *LocalBlobStore) Verify(key string, expires string, signature string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
+
+func (s *LocalBlobStore) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3BlobStore keeps blobs in a bucket of any S3 compatible service. Its
+// signed links are presigned requests that go straight to that service.
+type S3BlobStore struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3BlobStore(endpoint string, accessKey string, secretKey string, region string, bucket string, secure bool) (*S3BlobStore, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: secure,
+		Region: region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3BlobStore{
+		client: client,
+		bucket: bucket,
+	}, nil
+}
+
+func (s *S3BlobStore) Put(key string, content io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(context.Background(), s.bucket, key, content, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+
+	return err
+}
+
+func (s *S3BlobStore) Get(key string) (io.ReadCloser, error) {
+	return s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+}
+
+func (s *S3BlobStore) Delete(key string) error {
+	return s.client.RemoveObject(context.Background(), s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3BlobStore) SignedURL(key string, filename string, lifetime time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	signed, err := s.client.PresignedGetObject(context.Background(), s.bucket, key, lifetime, params)
+	if err != nil {
+		return "", err
+	}
+
+	return signed.String(), nil
+}
+
+// Verify always fails: downloads are signed and served by the S3 service.
+func (s *S3BlobStore) Verify(key string, expires string, signature string) bool {
+	return false
+}
@@ -35,10 +35,62 @@ func InitPostgres(logger *zap.Logger) *gorm.DB {
 	if err != nil {
 		panic(err)
 	}
+	err = db.AutoMigrate(&models.Workspace{}, &models.WorkspaceMember{}, &models.WorkspaceInvitation{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.WorkflowStatus{}, &models.WorkflowTransition{})
+	if err != nil {
+		panic(err)
+	}
 	err = db.AutoMigrate(&models.Todo{})
 	if err != nil {
 		panic(err)
 	}
+	err = db.AutoMigrate(&models.Attachment{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.Comment{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.Share{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.TodoEvent{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.TodoUndo{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.TrashEmptied{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.TodoDependency{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.TimeEntry{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.TodoTemplate{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.CalendarFeed{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.AppPassword{})
+	if err != nil {
+		panic(err)
+	}
 
 	return db
 }
@@ -0,0 +1,11 @@
+package presenter
+
+type appPasswordPresenter struct {
+}
+
+type AppPasswordPresenter interface {
+}
+
+func NewAppPasswordPresenter() AppPasswordPresenter {
+	return &appPasswordPresenter{}
+}
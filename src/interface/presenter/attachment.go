@@ -0,0 +1,11 @@
+package presenter
+
+type attachmentPresenter struct {
+}
+
+type AttachmentPresenter interface {
+}
+
+func NewAttachmentPresenter() AttachmentPresenter {
+	return &attachmentPresenter{}
+}
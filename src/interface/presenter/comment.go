@@ -0,0 +1,11 @@
+package presenter
+
+type commentPresenter struct {
+}
+
+type CommentPresenter interface {
+}
+
+func NewCommentPresenter() CommentPresenter {
+	return &commentPresenter{}
+}
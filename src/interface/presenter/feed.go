@@ -0,0 +1,11 @@
+package presenter
+
+type calendarFeedPresenter struct {
+}
+
+type CalendarFeedPresenter interface {
+}
+
+func NewCalendarFeedPresenter() CalendarFeedPresenter {
+	return &calendarFeedPresenter{}
+}
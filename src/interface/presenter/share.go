@@ -0,0 +1,11 @@
+package presenter
+
+type sharePresenter struct {
+}
+
+type SharePresenter interface {
+}
+
+func NewSharePresenter() SharePresenter {
+	return &sharePresenter{}
+}
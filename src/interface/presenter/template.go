@@ -0,0 +1,11 @@
+package presenter
+
+type todoTemplatePresenter struct {
+}
+
+type TodoTemplatePresenter interface {
+}
+
+func NewTodoTemplatePresenter() TodoTemplatePresenter {
+	return &todoTemplatePresenter{}
+}
@@ -0,0 +1,11 @@
+package presenter
+
+type timeEntryPresenter struct {
+}
+
+type TimeEntryPresenter interface {
+}
+
+func NewTimeEntryPresenter() TimeEntryPresenter {
+	return &timeEntryPresenter{}
+}
@@ -0,0 +1,11 @@
+package presenter
+
+type workflowPresenter struct {
+}
+
+type WorkflowPresenter interface {
+}
+
+func NewWorkflowPresenter() WorkflowPresenter {
+	return &workflowPresenter{}
+}
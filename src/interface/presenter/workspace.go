@@ -0,0 +1,11 @@
+package presenter
+
+type workspacePresenter struct {
+}
+
+type WorkspacePresenter interface {
+}
+
+func NewWorkspacePresenter() WorkspacePresenter {
+	return &workspacePresenter{}
+}
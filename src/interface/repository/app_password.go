@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"gorm.io/gorm"
+)
+
+type appPasswordRepository struct {
+	db *gorm.DB
+}
+
+type AppPasswordRepository interface {
+	Create(password *models.AppPassword) error
+	List(userId string) ([]*models.AppPassword, error)
+	GetByHash(hash string) (*models.AppPassword, error)
+	Touch(id string, at time.Time) error
+	Delete(id string, userId string) (bool, error)
+	WithTx(tx *gorm.DB) usecaseRepository.AppPasswordRepository
+}
+
+func NewAppPasswordRepository(db *gorm.DB) AppPasswordRepository {
+	return &appPasswordRepository{db}
+}
+
+func (pr *appPasswordRepository) Create(password *models.AppPassword) error {
+
+	if err := pr.db.Omit("User").Create(password).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (pr *appPasswordRepository) List(userId string) ([]*models.AppPassword, error) {
+
+	var passwords []*models.AppPassword
+	if err := pr.db.Where("user_id = ?", userId).Order("created").Find(&passwords).Error; err != nil {
+		return nil, err
+	}
+
+	return passwords, nil
+}
+
+// GetByHash returns the app password with the given hash together with its
+// user.
+func (pr *appPasswordRepository) GetByHash(hash string) (*models.AppPassword, error) {
+
+	var password models.AppPassword
+	if err := pr.db.Where("password_hash = ?", hash).Preload("User").Take(&password).Error; err != nil {
+		return nil, err
+	}
+
+	return &password, nil
+}
+
+func (pr *appPasswordRepository) Touch(id string, at time.Time) error {
+
+	if err := pr.db.Model(&models.AppPassword{}).Where("id = ?", id).UpdateColumn("last_used", at).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (pr *appPasswordRepository) Delete(id string, userId string) (bool, error) {
+
+	res := pr.db.Where("id = ? AND user_id = ?", id, userId).Delete(&models.AppPassword{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (pr *appPasswordRepository) WithTx(tx *gorm.DB) usecaseRepository.AppPasswordRepository {
+	return &appPasswordRepository{tx}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	GetByID(id string) (*models.Attachment, error)
+	GetByKey(key string) (*models.Attachment, error)
+	ListByTodo(todoId string) ([]*models.Attachment, error)
+	ListOrphaned(limit int) ([]*models.Attachment, error)
+	Delete(id string) error
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db}
+}
+
+func (ar *attachmentRepository) Create(attachment *models.Attachment) error {
+
+	if err := ar.db.Create(attachment).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ar *attachmentRepository) GetByID(id string) (*models.Attachment, error) {
+
+	var attachment models.Attachment
+	if err := ar.db.Where("id = ?", id).Take(&attachment).Error; err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (ar *attachmentRepository) GetByKey(key string) (*models.Attachment, error) {
+
+	var attachment models.Attachment
+	if err := ar.db.Where("key = ?", key).Take(&attachment).Error; err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (ar *attachmentRepository) ListByTodo(todoId string) ([]*models.Attachment, error) {
+
+	var attachments []*models.Attachment
+	if err := ar.db.Where("todo_id = ?", todoId).Order("created").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// ListOrphaned returns attachments whose todo has been permanently deleted.
+// Todos that are only in the trash keep their attachments.
+func (ar *attachmentRepository) ListOrphaned(limit int) ([]*models.Attachment, error) {
+
+	var attachments []*models.Attachment
+	if err := ar.db.Where("NOT EXISTS (SELECT 1 FROM todos WHERE todos.id = attachments.todo_id)").
+		Limit(limit).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (ar *attachmentRepository) Delete(id string) error {
+
+	if err := ar.db.Where("id = ?", id).Delete(&models.Attachment{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
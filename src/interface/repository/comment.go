@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+type CommentRepository interface {
+	Create(comment *models.Comment) error
+	GetByID(id string) (*models.Comment, error)
+	Paginate(todoId string, page models.PageArgs) (*models.CommentConnection, error)
+	Update(id string, body string) error
+	Delete(id string) (bool, error)
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db}
+}
+
+// commentKeys orders the comments of a todo from the oldest to the newest.
+var commentKeys = []sortKey{
+	{expr: "created", time: true},
+	{expr: "id"},
+}
+
+// withCommentCount selects the number of comments of each todo along with
+// the todo, so that listing todos doesn't count comments todo by todo.
+func withCommentCount(db *gorm.DB) *gorm.DB {
+	return db.Select("todos.*, (SELECT count(*) FROM comments WHERE comments.todo_id = todos.id) AS comment_count")
+}
+
+func (cr *commentRepository) Create(comment *models.Comment) error {
+
+	if err := cr.db.Omit("Todo", "User").Create(comment).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (cr *commentRepository) GetByID(id string) (*models.Comment, error) {
+
+	var comment models.Comment
+	if err := cr.db.Where("id = ?", id).Preload("User").Take(&comment).Error; err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (cr *commentRepository) Paginate(todoId string, page models.PageArgs) (*models.CommentConnection, error) {
+	q := cr.db.Model((*models.Comment)(nil)).Where("todo_id = ?", todoId)
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	for _, bound := range []struct {
+		cursor   *string
+		backward bool
+	}{{page.After, false}, {page.Before, true}} {
+		if bound.cursor == nil {
+			continue
+		}
+
+		values, err := decodeCursor(commentKeys, *bound.cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		cond, args := keyset(commentKeys, values, bound.backward)
+		q = q.Where(cond, args...)
+	}
+
+	backward := page.Last != nil
+	limit := 0
+	if backward {
+		limit = *page.Last
+	} else if page.First != nil {
+		limit = *page.First
+	}
+
+	var comments []*models.Comment
+	if err := q.Order(orderBy(commentKeys, backward)).Limit(limit + 1).Preload("User").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	more := len(comments) > limit
+	if more {
+		comments = comments[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+			comments[i], comments[j] = comments[j], comments[i]
+		}
+	}
+
+	conn := &models.CommentConnection{
+		Edges: make([]*models.CommentEdge, len(comments)),
+		PageInfo: &models.PageInfo{
+			HasNextPage:     more && !backward || backward && page.Before != nil,
+			HasPreviousPage: more && backward || !backward && page.After != nil,
+		},
+		TotalCount: total,
+	}
+
+	for i, comment := range comments {
+		conn.Edges[i] = &models.CommentEdge{
+			Cursor: encodeCursorValues(commentKeys, []interface{}{comment.Created, comment.ID.String()}),
+			Node:   comment,
+		}
+	}
+
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn, nil
+}
+
+func (cr *commentRepository) Update(id string, body string) error {
+
+	if err := cr.db.Model((*models.Comment)(nil)).Where("id = ?", id).Update("body", body).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (cr *commentRepository) Delete(id string) (bool, error) {
+
+	res := cr.db.Where("id = ?", id).Delete(&models.Comment{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
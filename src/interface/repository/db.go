@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+type dbRepository struct {
+	db *gorm.DB
+}
+
+type DBRepository interface {
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+func NewDBRepository(db *gorm.DB) DBRepository {
+	return &dbRepository{db}
+}
+
+// Transaction runs fn in a database transaction. Repositories join it
+// through their WithTx method.
+func (dr *dbRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return dr.db.Transaction(fn)
+}
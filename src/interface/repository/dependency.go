@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type todoDependencyRepository struct {
+	db *gorm.DB
+}
+
+type TodoDependencyRepository interface {
+	Add(dependency *models.TodoDependency) error
+	Remove(blockerId string, blockedId string) (bool, error)
+	ListBlockers(todoId string) ([]*models.Todo, error)
+	ListBlocked(todoId string) ([]*models.Todo, error)
+	ListByBlockers(blockerIds []string) ([]*models.TodoDependency, error)
+	ListOpenlyBlocked(todoIds []string) ([]uuid.UUID, error)
+	Lock() error
+	WithTx(tx *gorm.DB) usecaseRepository.TodoDependencyRepository
+}
+
+// dependencyLock is the key of the advisory lock that serializes changes to
+// the dependency graph, so that two edges added at once can't form a cycle.
+const dependencyLock = 4107
+
+func NewTodoDependencyRepository(db *gorm.DB) TodoDependencyRepository {
+	return &todoDependencyRepository{db}
+}
+
+func (dr *todoDependencyRepository) Add(dependency *models.TodoDependency) error {
+
+	if err := dr.db.Omit("Blocker", "Blocked").Clauses(clause.OnConflict{DoNothing: true}).
+		Create(dependency).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (dr *todoDependencyRepository) Remove(blockerId string, blockedId string) (bool, error) {
+
+	res := dr.db.Where("blocker_id = ? AND blocked_id = ?", blockerId, blockedId).Delete(&models.TodoDependency{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// ListBlockers returns the todos that block a todo, leaving out those in the
+// trash.
+func (dr *todoDependencyRepository) ListBlockers(todoId string) ([]*models.Todo, error) {
+
+	var todos []*models.Todo
+	if err := dr.db.Joins("INNER JOIN todo_dependencies d ON d.blocker_id = todos.id").Where("d.blocked_id = ?", todoId).
+		Order("d.created").Preload("User").Preload("Assignee").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// ListBlocked returns the todos a todo blocks, leaving out those in the trash.
+func (dr *todoDependencyRepository) ListBlocked(todoId string) ([]*models.Todo, error) {
+
+	var todos []*models.Todo
+	if err := dr.db.Joins("INNER JOIN todo_dependencies d ON d.blocked_id = todos.id").Where("d.blocker_id = ?", todoId).
+		Order("d.created").Preload("User").Preload("Assignee").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// ListByBlockers returns the edges leaving the given todos, including those
+// of todos in the trash, which can still be restored.
+func (dr *todoDependencyRepository) ListByBlockers(blockerIds []string) ([]*models.TodoDependency, error) {
+
+	var dependencies []*models.TodoDependency
+	if err := dr.db.Where("blocker_id IN ?", blockerIds).Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+
+	return dependencies, nil
+}
+
+// ListOpenlyBlocked returns which of the given todos are blocked by at least
+// one open todo that isn't in the trash.
+func (dr *todoDependencyRepository) ListOpenlyBlocked(todoIds []string) ([]uuid.UUID, error) {
+
+	var ids []uuid.UUID
+	if err := dr.db.Model((*models.TodoDependency)(nil)).Distinct("todo_dependencies.blocked_id").
+		Joins("INNER JOIN todos t ON t.id = todo_dependencies.blocker_id AND t.deleted IS NULL AND NOT t.done").
+		Where("todo_dependencies.blocked_id IN ?", todoIds).Pluck("todo_dependencies.blocked_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Lock holds the lock on the dependency graph until the transaction of the
+// repository ends.
+func (dr *todoDependencyRepository) Lock() error {
+
+	if err := dr.db.Exec("SELECT pg_advisory_xact_lock(?)", dependencyLock).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (dr *todoDependencyRepository) WithTx(tx *gorm.DB) usecaseRepository.TodoDependencyRepository {
+	return &todoDependencyRepository{tx}
+}
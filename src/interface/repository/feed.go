@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+type CalendarFeedRepository interface {
+	Upsert(feed *models.CalendarFeed) error
+	GetByUser(userId string) (*models.CalendarFeed, error)
+	GetByTokenHash(hash string) (*models.CalendarFeed, error)
+	Delete(userId string) (bool, error)
+	WithTx(tx *gorm.DB) usecaseRepository.CalendarFeedRepository
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db}
+}
+
+// Upsert stores the feed of the user, replacing the token of an existing
+// feed so that its old link stops working.
+func (fr *calendarFeedRepository) Upsert(feed *models.CalendarFeed) error {
+
+	if err := fr.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created", "updated"}),
+	}).Create(feed).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (fr *calendarFeedRepository) GetByUser(userId string) (*models.CalendarFeed, error) {
+
+	var feed models.CalendarFeed
+	if err := fr.db.Where("user_id = ?", userId).Take(&feed).Error; err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+func (fr *calendarFeedRepository) GetByTokenHash(hash string) (*models.CalendarFeed, error) {
+
+	var feed models.CalendarFeed
+	if err := fr.db.Where("token_hash = ?", hash).Take(&feed).Error; err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+func (fr *calendarFeedRepository) Delete(userId string) (bool, error) {
+
+	res := fr.db.Where("user_id = ?", userId).Delete(&models.CalendarFeed{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (fr *calendarFeedRepository) WithTx(tx *gorm.DB) usecaseRepository.CalendarFeedRepository {
+	return &calendarFeedRepository{tx}
+}
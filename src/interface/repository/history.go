@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"gorm.io/gorm"
+)
+
+type todoEventRepository struct {
+	db *gorm.DB
+}
+
+// TodoEventRepository has no way to change or remove events: the history of
+// a todo only goes away together with the todo.
+type TodoEventRepository interface {
+	Create(events []*models.TodoEvent) error
+	ListByTodo(todoId string) ([]*models.TodoEvent, error)
+	WithTx(tx *gorm.DB) usecaseRepository.TodoEventRepository
+}
+
+func NewTodoEventRepository(db *gorm.DB) TodoEventRepository {
+	return &todoEventRepository{db}
+}
+
+func (er *todoEventRepository) Create(events []*models.TodoEvent) error {
+
+	if err := er.db.Omit("Todo", "Actor").Create(events).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (er *todoEventRepository) ListByTodo(todoId string) ([]*models.TodoEvent, error) {
+
+	var events []*models.TodoEvent
+	if err := er.db.Where("todo_id = ?", todoId).Preload("Actor").Order("created, field").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (er *todoEventRepository) WithTx(tx *gorm.DB) usecaseRepository.TodoEventRepository {
+	return &todoEventRepository{tx}
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+)
+
+// sortKey is a whitelisted SQL expression a keyset page can be ordered by,
+// together with the accessor that reads the same value from a loaded todo.
+type sortKey struct {
+	expr  string
+	desc  bool
+	time  bool
+	value func(todo *models.Todo) interface{}
+}
+
+// todoIDKey is appended to every order so that the sort key is stable.
+var todoIDKey = sortKey{
+	expr:  "id",
+	value: func(todo *models.Todo) interface{} { return todo.ID.String() },
+}
+
+// noDueDate stands in for a missing due date so that due_at can be used as a
+// keyset sort key; todos without a due date sort last.
+var noDueDate = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// todoSortKeys whitelists the columns todos can be ordered by.
+var todoSortKeys = map[model.TodoSortField]sortKey{
+	model.TodoSortFieldCreated: {
+		expr:  "created",
+		time:  true,
+		value: func(todo *models.Todo) interface{} { return todo.Created },
+	},
+	model.TodoSortFieldUpdated: {
+		expr:  "updated",
+		time:  true,
+		value: func(todo *models.Todo) interface{} { return todo.Updated },
+	},
+	model.TodoSortFieldText: {
+		expr:  "text",
+		value: func(todo *models.Todo) interface{} { return todo.Text },
+	},
+	model.TodoSortFieldDone: {
+		expr:  "done",
+		value: func(todo *models.Todo) interface{} { return todo.Done },
+	},
+	model.TodoSortFieldManual: {
+		expr:  "position",
+		value: func(todo *models.Todo) interface{} { return todo.Position },
+	},
+	model.TodoSortFieldDueAt: {
+		expr: "COALESCE(due_at, '9999-12-31T00:00:00Z')",
+		time: true,
+		value: func(todo *models.Todo) interface{} {
+			if todo.DueAt == nil {
+				return noDueDate
+			}
+			return *todo.DueAt
+		},
+	},
+}
+
+// todoOrder translates the requested order into whitelisted sort keys,
+// defaulting to creation time and always ending with the id.
+func todoOrder(order []*model.TodoOrder) []sortKey {
+	keys := make([]sortKey, 0, len(order)+1)
+	seen := make(map[model.TodoSortField]bool, len(order))
+	for _, o := range order {
+		key, ok := todoSortKeys[o.Field]
+		if !ok || seen[o.Field] {
+			continue
+		}
+		seen[o.Field] = true
+
+		key.desc = o.Direction == model.SortDirectionDesc
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		keys = append(keys, todoSortKeys[model.TodoSortFieldCreated])
+	}
+
+	return append(keys, todoIDKey)
+}
+
+// cursor is the opaque position of an edge. It records the order it was
+// issued for, so that it cannot be replayed against a different order.
+type cursor struct {
+	Order  string        `json:"o"`
+	Values []interface{} `json:"v"`
+}
+
+func orderSignature(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.expr
+		if key.desc {
+			parts[i] += " DESC"
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(keys []sortKey, todo *models.Todo) string {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = key.value(todo)
+	}
+
+	return encodeCursorValues(keys, values)
+}
+
+// encodeCursorValues encodes a cursor from values read in the order of keys,
+// for rows that aren't todos.
+func encodeCursorValues(keys []sortKey, values []interface{}) string {
+	c := cursor{
+		Order:  orderSignature(keys),
+		Values: values,
+	}
+
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(keys []sortKey, encoded string) ([]interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, models.ErrCursorInvalid
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Order != orderSignature(keys) || len(c.Values) != len(keys) {
+		return nil, models.ErrCursorInvalid
+	}
+
+	values := c.Values
+	for i, key := range keys {
+		if !key.time {
+			continue
+		}
+
+		str, ok := values[i].(string)
+		if !ok {
+			return nil, models.ErrCursorInvalid
+		}
+
+		values[i], err = time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return nil, models.ErrCursorInvalid
+		}
+	}
+
+	return values, nil
+}
+
+// keyset builds the condition that selects the rows strictly after the cursor
+// values in the given order, or strictly before them when backward is set.
+func keyset(keys []sortKey, values []interface{}, backward bool) (string, []interface{}) {
+	var or []string
+	var args []interface{}
+	for i, key := range keys {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, keys[j].expr+" = ?")
+			args = append(args, values[j])
+		}
+
+		op := ">"
+		if key.desc != backward {
+			op = "<"
+		}
+		and = append(and, key.expr+" "+op+" ?")
+		args = append(args, values[i])
+
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+
+	return "(" + strings.Join(or, " OR ") + ")", args
+}
+
+func orderBy(keys []sortKey, backward bool) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		dir := "ASC"
+		if key.desc != backward {
+			dir = "DESC"
+		}
+		parts[i] = key.expr + " " + dir
+	}
+
+	return strings.Join(parts, ", ")
+}
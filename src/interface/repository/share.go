@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type shareRepository struct {
+	db *gorm.DB
+}
+
+type ShareRepository interface {
+	Upsert(shares []*models.Share) error
+	ListByTodo(todoId string) ([]*models.Share, error)
+	ListByRecipient(todoIds []string, userId string) ([]*models.Share, error)
+	Delete(todoId string, userId string) (bool, error)
+	Access(todoId string, userId string) (*models.TodoAccess, error)
+	TrashedAccess(todoId string, userId string) (*models.TodoAccess, error)
+	AccessMany(todoIds []string, userId string) (map[string]*models.TodoAccess, error)
+	WithTx(tx *gorm.DB) usecaseRepository.ShareRepository
+}
+
+func NewShareRepository(db *gorm.DB) ShareRepository {
+	return &shareRepository{db}
+}
+
+// Upsert shares the todos, replacing the role of the recipients that already
+// have access to them.
+func (sr *shareRepository) Upsert(shares []*models.Share) error {
+
+	if err := sr.db.Omit("Todo", "User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "todo_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(shares).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sr *shareRepository) ListByTodo(todoId string) ([]*models.Share, error) {
+
+	var shares []*models.Share
+	if err := sr.db.Where("todo_id = ?", todoId).Preload("User").Order("created").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+func (sr *shareRepository) ListByRecipient(todoIds []string, userId string) ([]*models.Share, error) {
+
+	var shares []*models.Share
+	if err := sr.db.Where("todo_id IN ? AND user_id = ?", todoIds, userId).Preload("User").Order("created").
+		Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+func (sr *shareRepository) Delete(todoId string, userId string) (bool, error) {
+
+	res := sr.db.Where("todo_id = ? AND user_id = ?", todoId, userId).Delete(&models.Share{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// todoAccessRow is the owner and workspace of a todo, the role of a user in
+// that workspace and the best role shared with the user on the todo or one of
+// its parents.
+type todoAccessRow struct {
+	TodoID      uuid.UUID
+	OwnerID     uuid.UUID
+	WorkspaceID *uuid.UUID
+	MemberRole  *string
+	SharedRole  *string
+}
+
+// Access gathers everything the role of the user on a todo depends on in a
+// single query, walking up the parents of the todo for shares. It returns nil
+// when the todo doesn't exist or is in the trash.
+func (sr *shareRepository) Access(todoId string, userId string) (*models.TodoAccess, error) {
+
+	id, err := uuid.Parse(todoId)
+	if err != nil {
+		return nil, nil
+	}
+
+	accesses, err := sr.access([]string{id.String()}, userId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return accesses[id.String()], nil
+}
+
+// TrashedAccess is Access for a todo in the trash. Parents are walked up
+// whether or not they were trashed along with it.
+func (sr *shareRepository) TrashedAccess(todoId string, userId string) (*models.TodoAccess, error) {
+
+	id, err := uuid.Parse(todoId)
+	if err != nil {
+		return nil, nil
+	}
+
+	accesses, err := sr.access([]string{id.String()}, userId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return accesses[id.String()], nil
+}
+
+// AccessMany is Access for many todos in one query, keyed by their ids as
+// uuid.UUID.String writes them. Todos that don't exist or are in the trash
+// are left out.
+func (sr *shareRepository) AccessMany(todoIds []string, userId string) (map[string]*models.TodoAccess, error) {
+	return sr.access(todoIds, userId, false)
+}
+
+func (sr *shareRepository) access(todoIds []string, userId string, trashed bool) (map[string]*models.TodoAccess, error) {
+
+	accesses := make(map[string]*models.TodoAccess, len(todoIds))
+	if len(todoIds) == 0 {
+		return accesses, nil
+	}
+
+	todo, parent := "todo.deleted IS NULL", "t.deleted IS NULL"
+	if trashed {
+		todo, parent = "todo.deleted IS NOT NULL", "TRUE"
+	}
+
+	var rows []todoAccessRow
+	if err := sr.db.Raw(`WITH RECURSIVE chain AS (
+			SELECT todo.id AS root_id, todo.id, todo.parent_id FROM todos todo WHERE todo.id IN ? AND `+todo+`
+			UNION ALL
+			SELECT chain.root_id, t.id, t.parent_id FROM todos t INNER JOIN chain ON t.id = chain.parent_id
+			WHERE `+parent+`
+		)
+		SELECT todo.id AS todo_id, todo.user_id AS owner_id, todo.workspace_id,
+			(SELECT m.role FROM workspace_members m
+				WHERE m.workspace_id = todo.workspace_id AND m.user_id = ?) AS member_role,
+			(SELECT s.role FROM shares s INNER JOIN chain ON s.todo_id = chain.id
+				WHERE chain.root_id = todo.id AND s.user_id = ? ORDER BY s.role = ? DESC LIMIT 1) AS shared_role
+		FROM todos todo WHERE todo.id IN ? AND `+todo,
+		todoIds, userId, userId, models.TodoRoleEditor, todoIds).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		access := &models.TodoAccess{OwnerID: row.OwnerID, WorkspaceID: row.WorkspaceID}
+		if row.MemberRole != nil {
+			access.MemberRole = models.WorkspaceRole(*row.MemberRole)
+		}
+		if row.SharedRole != nil {
+			access.SharedRole = models.TodoRole(*row.SharedRole)
+		}
+		accesses[row.TodoID.String()] = access
+	}
+
+	return accesses, nil
+}
+
+func (sr *shareRepository) WithTx(tx *gorm.DB) usecaseRepository.ShareRepository {
+	return &shareRepository{tx}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type todoTemplateRepository struct {
+	db *gorm.DB
+}
+
+type TodoTemplateRepository interface {
+	Create(template *models.TodoTemplate) error
+	GetByID(id string) (*models.TodoTemplate, error)
+	List(workspaceId *uuid.UUID, userId string) ([]*models.TodoTemplate, error)
+	Delete(id string) (bool, error)
+	WithTx(tx *gorm.DB) usecaseRepository.TodoTemplateRepository
+}
+
+func NewTodoTemplateRepository(db *gorm.DB) TodoTemplateRepository {
+	return &todoTemplateRepository{db}
+}
+
+func (tr *todoTemplateRepository) Create(template *models.TodoTemplate) error {
+
+	if err := tr.db.Omit("User", "Workspace").Create(template).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (tr *todoTemplateRepository) GetByID(id string) (*models.TodoTemplate, error) {
+
+	var template models.TodoTemplate
+	if err := tr.db.Where("id = ?", id).Take(&template).Error; err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// List returns the templates of a workspace, or the personal templates of
+// the user when workspaceId is nil.
+func (tr *todoTemplateRepository) List(workspaceId *uuid.UUID, userId string) ([]*models.TodoTemplate, error) {
+
+	q := tr.db.Where("workspace_id = ?", workspaceId)
+	if workspaceId == nil {
+		q = tr.db.Where("user_id = ? AND workspace_id IS NULL", userId)
+	}
+
+	var templates []*models.TodoTemplate
+	if err := q.Order("name").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (tr *todoTemplateRepository) Delete(id string) (bool, error) {
+
+	res := tr.db.Where("id = ?", id).Delete(&models.TodoTemplate{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (tr *todoTemplateRepository) WithTx(tx *gorm.DB) usecaseRepository.TodoTemplateRepository {
+	return &todoTemplateRepository{tx}
+}
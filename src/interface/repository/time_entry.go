@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"time"
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type timeEntryRepository struct {
+	db *gorm.DB
+}
+
+type TimeEntryRepository interface {
+	Create(entry *models.TimeEntry) error
+	GetByID(id string, userId string) (*models.TimeEntry, error)
+	GetRunning(userId string) (*models.TimeEntry, error)
+	Update(id string, userId string, fields map[string]interface{}) error
+	Delete(id string, userId string) (bool, error)
+	ListByTodo(todoId string) ([]*models.TimeEntry, error)
+	ListByUser(userId string, from time.Time, to time.Time) ([]*models.TimeEntry, error)
+	SumByTodos(todoIds []string, now time.Time) (map[string]int64, error)
+	WithTx(tx *gorm.DB) usecaseRepository.TimeEntryRepository
+}
+
+func NewTimeEntryRepository(db *gorm.DB) TimeEntryRepository {
+	return &timeEntryRepository{db}
+}
+
+// withTodo loads the todo of time entries even if it's in the trash, since
+// the time spent on it still counts.
+func withTodo(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("Todo", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+}
+
+func (er *timeEntryRepository) Create(entry *models.TimeEntry) error {
+
+	if err := er.db.Omit("Todo", "User").Create(entry).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByID returns a time entry of the user. Time entries of other users are
+// reported as missing.
+func (er *timeEntryRepository) GetByID(id string, userId string) (*models.TimeEntry, error) {
+
+	var entry models.TimeEntry
+	if err := er.db.Where("id = ? AND user_id = ?", id, userId).Scopes(withTodo).Take(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// GetRunning returns the running timer of the user, or nil when there is none.
+func (er *timeEntryRepository) GetRunning(userId string) (*models.TimeEntry, error) {
+
+	var entries []*models.TimeEntry
+	if err := er.db.Where("user_id = ? AND stopped IS NULL", userId).Scopes(withTodo).Limit(1).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	return entries[0], nil
+}
+
+func (er *timeEntryRepository) Update(id string, userId string, fields map[string]interface{}) error {
+
+	if err := er.db.Model((*models.TimeEntry)(nil)).Where("id = ? AND user_id = ?", id, userId).
+		Updates(fields).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (er *timeEntryRepository) Delete(id string, userId string) (bool, error) {
+
+	res := er.db.Where("id = ? AND user_id = ?", id, userId).Delete(&models.TimeEntry{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (er *timeEntryRepository) ListByTodo(todoId string) ([]*models.TimeEntry, error) {
+
+	var entries []*models.TimeEntry
+	if err := er.db.Where("todo_id = ?", todoId).Scopes(withTodo).Order("started DESC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// ListByUser returns the time entries of the user that overlap the range
+// from to, including a running timer that started before to.
+func (er *timeEntryRepository) ListByUser(userId string, from time.Time, to time.Time) ([]*models.TimeEntry, error) {
+
+	var entries []*models.TimeEntry
+	if err := er.db.Where("user_id = ? AND started < ? AND (stopped IS NULL OR stopped > ?)", userId, to, from).
+		Scopes(withTodo).Order("started").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// timeSpentRow is the time spent on a todo.
+type timeSpentRow struct {
+	TodoID  uuid.UUID
+	Seconds int64
+}
+
+// SumByTodos adds up the seconds every user spent on each of the todos, by
+// todo id, counting running timers up to now. Todos without time entries are
+// left out.
+func (er *timeEntryRepository) SumByTodos(todoIds []string, now time.Time) (map[string]int64, error) {
+
+	seconds := make(map[string]int64, len(todoIds))
+	if len(todoIds) == 0 {
+		return seconds, nil
+	}
+
+	var rows []timeSpentRow
+	if err := er.db.Model((*models.TimeEntry)(nil)).
+		Select("todo_id, COALESCE(SUM(EXTRACT(EPOCH FROM GREATEST(COALESCE(stopped, ?) - started, interval '0'))), 0)::bigint AS seconds", now).
+		Where("todo_id IN ?", todoIds).Group("todo_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		seconds[row.TodoID.String()] = row.Seconds
+	}
+
+	return seconds, nil
+}
+
+func (er *timeEntryRepository) WithTx(tx *gorm.DB) usecaseRepository.TimeEntryRepository {
+	return &timeEntryRepository{tx}
+}
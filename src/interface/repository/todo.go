@@ -1,28 +1,110 @@
 package repository
 
 import (
+	"database/sql"
+	"strings"
 	"time"
 	"todo-service/graph/model"
 	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+	"todo-service/utils/search"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type todoRepository struct {
-	db *gorm.DB
+	db          *gorm.DB
+	workspaceId *uuid.UUID
 }
 
 type TodoRepository interface {
 	Create(input model.NewTodo, userId string) (*models.Todo, error)
-	MarkComplete(id string, userId string) error
-	Delete(id string, userId string) (bool, error)
+	MarkComplete(ids []string, userId string, cascade models.CascadeRule) error
+	Delete(id string, userId string, cascade models.CascadeRule) (bool, error)
 	GetByID(id string, userId string) (*models.Todo, error)
+	GetByUID(uid string, userId string) (*models.Todo, error)
+	ListByIDs(ids []string, userId string) ([]*models.Todo, error)
 	List(userId string) ([]*models.Todo, error)
+	Each(filter *model.TodoFilter, userId string, fn func(todo *models.Todo) error) error
+	EachChanged(since time.Time, userId string, fn func(todo *models.Todo) error) error
+	Version(filter *model.TodoFilter, userId string) (*models.TodoVersion, error)
+	Paginate(page models.PageArgs, filter *model.TodoFilter, order []*model.TodoOrder, userId string) (*models.TodoConnection, error)
+	ListChildren(parentId string, userId string) ([]*models.Todo, error)
+	ListTree(rootId *string, userId string) ([]*models.Todo, error)
+	ListSubtrees(rootIds []string, userId string) ([]*models.Todo, error)
+	ListTrashed(userId string) ([]*models.Todo, error)
+	ListAssigned(assigneeId string, userId string) ([]*models.Todo, error)
+	Restore(id string, userId string) (bool, error)
+	EmptyTrash(userId string, own bool) (int64, error)
+	Purge(before time.Time) (int64, error)
+	Search(query string, limit int, userId string) ([]*models.TodoSearchResult, error)
+	UpdateSearchLanguage(language string, userId string) error
+	Insert(todo *models.Todo) error
+	Update(id string, userId string, fields map[string]interface{}) error
+	UpdateMany(ids []string, userId string, fields map[string]interface{}) error
+	UpdateSeries(seriesId string, from time.Time, userId string, fields map[string]interface{}) error
+	Adjacent(todo *models.Todo, next bool, excludeId string, userId string) (*models.Todo, error)
+	Rebalance(userId string) error
+	WithTx(tx *gorm.DB) usecaseRepository.TodoRepository
+	InWorkspace(workspaceId *uuid.UUID) usecaseRepository.TodoRepository
 }
 
 func NewTodoRepository(db *gorm.DB) TodoRepository {
-	return &todoRepository{db}
+	return &todoRepository{db, nil}
+}
+
+// tenant is the condition that isolates the todos of one tenant from all
+// others: the personal todos of the user, or every todo of the workspace the
+// repository is bound to. Alias prefixes the columns for raw queries.
+func (ur *todoRepository) tenant(alias string, userId string) (string, []interface{}) {
+	if ur.workspaceId != nil {
+		return alias + "workspace_id = ?", []interface{}{*ur.workspaceId}
+	}
+
+	return alias + "workspace_id IS NULL AND " + alias + "user_id = ?", []interface{}{userId}
+}
+
+// tenantId is the id the tenant of the repository is recorded under: the
+// workspace, or the user for their personal todos.
+func (ur *todoRepository) tenantId(userId string) uuid.UUID {
+	if ur.workspaceId != nil {
+		return *ur.workspaceId
+	}
+
+	return uuid.MustParse(userId)
+}
+
+// languageTable is where the text search configuration of the tenant is
+// kept, in the row with the id of the tenant.
+func (ur *todoRepository) languageTable() string {
+	if ur.workspaceId != nil {
+		return "workspaces"
+	}
+
+	return "users"
+}
+
+// searchLanguage is the text search configuration new todos of the tenant
+// are indexed with.
+func (ur *todoRepository) searchLanguage(userId string) (string, error) {
+
+	var language string
+	if err := ur.db.Table(ur.languageTable()).Select("search_language").Where("id = ?", ur.tenantId(userId)).
+		Take(&language).Error; err != nil {
+		return "", err
+	}
+
+	return language, nil
+}
+
+// scoped narrows a query down to the tenant of the repository.
+func (ur *todoRepository) scoped(userId string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		cond, args := ur.tenant("", userId)
+		return db.Where(cond, args...)
+	}
 }
 
 func (ur *todoRepository) Create(input model.NewTodo, userId string) (*models.Todo, error) {
@@ -33,6 +115,47 @@ func (ur *todoRepository) Create(input model.NewTodo, userId string) (*models.To
 		UserID:  uuid.MustParse(userId),
 		Done:    false,
 		Created: time.Now(),
+
+		WorkspaceID: ur.workspaceId,
+	}
+
+	language, err := ur.searchLanguage(userId)
+	if err != nil {
+		return nil, err
+	}
+	todo.SearchLanguage = language
+
+	var last float64
+	if err := ur.db.Model((*models.Todo)(nil)).Select("COALESCE(MAX(position), 0)").Scopes(ur.scoped(userId)).
+		Scan(&last).Error; err != nil {
+		return nil, err
+	}
+	todo.Position = last + models.TodoPositionStep
+
+	if input.ParentID != nil {
+		parentId, err := uuid.Parse(*input.ParentID)
+		if err != nil {
+			return nil, models.ErrTodoParentNotFound
+		}
+		todo.ParentID = &parentId
+	}
+
+	if input.DueAt != nil {
+		todo.DueAt = input.DueAt
+	}
+
+	todo.Priority = models.TodoPriorityNone
+	if input.Priority != nil {
+		todo.Priority = *input.Priority
+	}
+	todo.Tags = models.NormalizeTags(input.Tags)
+
+	if input.Rrule != nil && *input.Rrule != "" {
+		seriesId := uuid.New()
+		todo.RRule = *input.Rrule
+		todo.SeriesID = &seriesId
+		todo.SeriesStart = input.DueAt
+		todo.OccurrenceAt = input.DueAt
 	}
 
 	if err := ur.db.Model(todo).Create(&todo).Error; err != nil {
@@ -42,45 +165,739 @@ func (ur *todoRepository) Create(input model.NewTodo, userId string) (*models.To
 	return &todo, nil
 }
 
-func (ur *todoRepository) MarkComplete(id string, userId string) error {
+func (ur *todoRepository) MarkComplete(ids []string, userId string, cascade models.CascadeRule) error {
+
+	q := ur.db.Model((*models.Todo)(nil)).Where("id IN ?", ids).Scopes(ur.scoped(userId))
+	if cascade == models.CascadeChildren {
+		q = ur.db.Model((*models.Todo)(nil)).Where("id IN (?)", ur.subtree(ids, userId))
+	}
 
-	if err := ur.db.Model((*models.Todo)(nil)).Where("id = ? AND user_id = ?", id, userId).Update("done", true).Error; err != nil {
+	fields := map[string]interface{}{
+		"done":         true,
+		"completed_at": gorm.Expr("COALESCE(completed_at, ?)", time.Now()),
+	}
+	if err := q.Updates(fields).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (ur *todoRepository) Delete(id string, userId string) (bool, error) {
-	var todo models.Todo
-	q := ur.db.Where("id = ? AND user_id = ?", id, userId).Delete(&todo)
-	if q.Error != nil {
-		return false, q.Error
+func (ur *todoRepository) Delete(id string, userId string, cascade models.CascadeRule) (bool, error) {
+	var deleted bool
+	err := ur.db.Transaction(func(tx *gorm.DB) error {
+		var todo models.Todo
+		q := tx.Where("id = ?", id).Scopes(ur.scoped(userId))
+
+		switch cascade {
+		case models.CascadeChildren:
+			q = tx.Where("id IN (?)", ur.subtree([]string{id}, userId))
+
+		case models.CascadeReparent:
+			var parent models.Todo
+			if err := tx.Select("parent_id").Where("id = ?", id).Scopes(ur.scoped(userId)).Take(&parent).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return nil
+				}
+				return err
+			}
+
+			if err := tx.Model((*models.Todo)(nil)).Where("parent_id = ?", id).Scopes(ur.scoped(userId)).
+				Update("parent_id", parent.ParentID).Error; err != nil {
+				return err
+			}
+
+		case models.CascadeRestrict:
+			var children int64
+			if err := tx.Model((*models.Todo)(nil)).Where("parent_id = ?", id).Scopes(ur.scoped(userId)).
+				Count(&children).Error; err != nil {
+				return err
+			}
+
+			if children > 0 {
+				return models.ErrTodoHasChildren
+			}
+		}
+
+		res := q.Delete(&todo)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		deleted = res.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		return false, err
 	}
 
-	if q.RowsAffected == 0 {
-		return false, nil
+	return deleted, nil
+}
+
+// ListByIDs loads the todos of the user with the given ids in one query.
+func (ur *todoRepository) ListByIDs(ids []string, userId string) ([]*models.Todo, error) {
+
+	var todos []*models.Todo
+	if err := ur.db.Model(todos).Where("id IN ?", ids).Scopes(ur.scoped(userId)).Preload("User").Preload("Assignee").
+		Find(&todos).Error; err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	return todos, nil
 }
 
 func (ur *todoRepository) GetByID(id string, userId string) (*models.Todo, error) {
 
 	var todo models.Todo
-	if err := ur.db.Model(todo).Where("id = ? AND user_id = ?", id, userId).Preload("User").Take(&todo).Error; err != nil {
+	if err := ur.db.Model(todo).Where("id = ?", id).Scopes(ur.scoped(userId), withCommentCount).Preload("User").Preload("Assignee").Take(&todo).Error; err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+// GetByUID finds a todo of the user by its calendar UID, see
+// models.Todo.CalendarUID.
+func (ur *todoRepository) GetByUID(uid string, userId string) (*models.Todo, error) {
+
+	var todo models.Todo
+	if err := ur.db.Model(todo).Where("uid = ? OR (uid = '' AND id::text = ?)", uid, uid).Scopes(ur.scoped(userId), withParentUID).
+		Preload("User").Order("created").Take(&todo).Error; err != nil {
 		return nil, err
 	}
 
 	return &todo, nil
 }
 
+// withParentUID fills models.Todo.ParentUID.
+func withParentUID(db *gorm.DB) *gorm.DB {
+	return db.Select("todos.*, COALESCE((SELECT COALESCE(NULLIF(p.uid, ''), p.id::text) FROM todos p WHERE p.id = todos.parent_id), '') AS parent_uid")
+}
+
 func (ur *todoRepository) List(userId string) ([]*models.Todo, error) {
 
 	var todos []*models.Todo
-	if err := ur.db.Model(todos).Where("user_id = ?", userId).Preload("User").Find(&todos).Error; err != nil {
+	if err := ur.db.Model(todos).Scopes(ur.scoped(userId), withCommentCount).Preload("User").Preload("Assignee").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// Each calls fn with the todos of the user that match the filter one by one
+// in their manual order, reading them from a cursor instead of loading them
+// all at once. It stops at the first error of fn.
+func (ur *todoRepository) Each(filter *model.TodoFilter, userId string, fn func(todo *models.Todo) error) error {
+
+	q, err := filterTodos(ur.db.Model((*models.Todo)(nil)).Scopes(ur.scoped(userId), withParentUID), filter)
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.Order("position, id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todo models.Todo
+		if err := ur.db.ScanRows(rows, &todo); err != nil {
+			return err
+		}
+
+		if err := fn(&todo); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// EachChanged calls fn with the todos of the user that were changed or moved
+// to the trash after the given time, trashed todos included, in the order
+// they were changed. It stops at the first error of fn.
+func (ur *todoRepository) EachChanged(since time.Time, userId string, fn func(todo *models.Todo) error) error {
+
+	rows, err := ur.db.Unscoped().Model((*models.Todo)(nil)).Scopes(ur.scoped(userId), withParentUID).
+		Where("updated > ? OR deleted > ?", since, since).Order("GREATEST(updated, deleted), id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todo models.Todo
+		if err := ur.db.ScanRows(rows, &todo); err != nil {
+			return err
+		}
+
+		if err := fn(&todo); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Version counts the todos of the user that match the filter and finds the
+// last time any todo of the tenant was changed or moved to the trash, so that
+// it changes with every change to the todos that match.
+func (ur *todoRepository) Version(filter *model.TodoFilter, userId string) (*models.TodoVersion, error) {
+
+	q, err := filterTodos(ur.db.Model((*models.Todo)(nil)).Scopes(ur.scoped(userId)), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var version models.TodoVersion
+	if err := q.Count(&version.Count).Error; err != nil {
+		return nil, err
+	}
+
+	var updated sql.NullTime
+	if err := ur.db.Unscoped().Model((*models.Todo)(nil)).Scopes(ur.scoped(userId)).
+		Select("MAX(GREATEST(updated, deleted))").Row().Scan(&updated); err != nil {
+		return nil, err
+	}
+	version.Updated = updated.Time
+
+	var emptied models.TrashEmptied
+	if err := ur.db.Where("tenant_id = ?", ur.tenantId(userId)).Limit(1).Find(&emptied).Error; err != nil {
+		return nil, err
+	}
+	version.Emptied = emptied.Emptied
+
+	return &version, nil
+}
+
+func (ur *todoRepository) ListTrashed(userId string) ([]*models.Todo, error) {
+
+	var todos []*models.Todo
+	if err := ur.db.Unscoped().Where("deleted IS NOT NULL").Scopes(ur.scoped(userId), withCommentCount).
+		Order("deleted DESC").Preload("User").Preload("Assignee").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// ListAssigned returns the todos of the tenant that are assigned to a user.
+// Personal todos assigned to the user can only be shared todos of others, so
+// the personal tenant is not narrowed down to the todos of userId here.
+func (ur *todoRepository) ListAssigned(assigneeId string, userId string) ([]*models.Todo, error) {
+
+	q := ur.db.Where("assignee_id = ?", assigneeId)
+	if ur.workspaceId != nil {
+		q = q.Scopes(ur.scoped(userId))
+	} else {
+		q = q.Where("workspace_id IS NULL")
+	}
+
+	var todos []*models.Todo
+	if err := q.Scopes(withCommentCount).Order("due_at ASC NULLS LAST, created").Preload("User").Preload("Assignee").
+		Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// Restore takes a todo out of the trash together with the subtasks that were
+// trashed along with it. A todo whose parent is still in the trash is moved
+// to the top level.
+func (ur *todoRepository) Restore(id string, userId string) (bool, error) {
+	var restored bool
+	err := ur.db.Transaction(func(tx *gorm.DB) error {
+		var todo models.Todo
+		if err := tx.Unscoped().Where("id = ? AND deleted IS NOT NULL", id).Scopes(ur.scoped(userId)).
+			Take(&todo).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Unscoped().Model((*models.Todo)(nil)).Where("id IN (?)", ur.trashedSubtree(&todo)).
+			Update("deleted", nil).Error; err != nil {
+			return err
+		}
+
+		trashed := tx.Unscoped().Model((*models.Todo)(nil)).Select("id").Where("deleted IS NOT NULL")
+		if err := tx.Model((*models.Todo)(nil)).Where("id = ? AND parent_id IN (?)", id, trashed).
+			Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+
+		restored = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return restored, nil
+}
+
+// EmptyTrash permanently deletes the todos in the trash of the tenant, only
+// those userId created when own is set, and records when, so that syncs from
+// before can be told apart.
+func (ur *todoRepository) EmptyTrash(userId string, own bool) (int64, error) {
+
+	var count int64
+	err := ur.db.Transaction(func(tx *gorm.DB) error {
+		q := tx.Unscoped().Where("deleted IS NOT NULL").Scopes(ur.scoped(userId))
+		if own {
+			q = q.Where("user_id = ?", userId)
+		}
+		res := q.Delete(&models.Todo{})
+		if res.Error != nil {
+			return res.Error
+		}
+		count = res.RowsAffected
+		if count == 0 {
+			return nil
+		}
+
+		emptied := models.TrashEmptied{TenantID: ur.tenantId(userId), Emptied: time.Now()}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"emptied"}),
+		}).Create(&emptied).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Purge permanently deletes the todos of every user and workspace that were
+// moved to the trash before the given time.
+func (ur *todoRepository) Purge(before time.Time) (int64, error) {
+
+	res := ur.db.Unscoped().Where("deleted < ?", before).Delete(&models.Todo{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+// filterTodos narrows a todo query down to the given filter. Every value is
+// passed as a bound parameter.
+func filterTodos(q *gorm.DB, filter *model.TodoFilter) (*gorm.DB, error) {
+	if filter == nil {
+		return q, nil
+	}
+
+	if filter.Done != nil {
+		q = q.Where("done = ?", *filter.Done)
+	}
+
+	if filter.TextContains != nil && *filter.TextContains != "" {
+		q = q.Where(`text ILIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(*filter.TextContains)+"%")
+	}
+
+	for _, bound := range []struct {
+		cond  string
+		value *time.Time
+	}{
+		{"created >= ?", filter.CreatedAfter},
+		{"created < ?", filter.CreatedBefore},
+		{"updated >= ?", filter.UpdatedAfter},
+		{"updated < ?", filter.UpdatedBefore},
+		{"due_at >= ?", filter.DueAfter},
+		{"due_at < ?", filter.DueBefore},
+	} {
+		if bound.value != nil {
+			q = q.Where(bound.cond, *bound.value)
+		}
+	}
+
+	if filter.HasDueDate != nil {
+		if *filter.HasDueDate {
+			q = q.Where("due_at IS NOT NULL")
+		} else {
+			q = q.Where("due_at IS NULL")
+		}
+	}
+
+	if filter.ParentID != nil {
+		parentId, err := uuid.Parse(*filter.ParentID)
+		if err != nil {
+			return nil, models.ErrTodoFilterInvalid
+		}
+		q = q.Where("parent_id = ?", parentId)
+	}
+
+	if filter.RootsOnly != nil && *filter.RootsOnly {
+		q = q.Where("parent_id IS NULL")
+	}
+
+	if filter.Recurring != nil {
+		if *filter.Recurring {
+			q = q.Where("series_id IS NOT NULL")
+		} else {
+			q = q.Where("series_id IS NULL")
+		}
+	}
+
+	if filter.Tag != nil {
+		if tags := models.NormalizeTags([]string{*filter.Tag}); len(tags) > 0 {
+			q = q.Where("tags @> ?", tags)
+		}
+	}
+
+	if filter.SeriesID != nil {
+		seriesId, err := uuid.Parse(*filter.SeriesID)
+		if err != nil {
+			return nil, models.ErrTodoFilterInvalid
+		}
+		q = q.Where("series_id = ?", seriesId)
+	}
+
+	return q, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Paginate returns one page of the todos of the tenant using keyset
+// pagination. The personal todos come together with the todos shared with the
+// user. Exactly one of page.First and page.Last is expected to be set.
+func (ur *todoRepository) Paginate(page models.PageArgs, filter *model.TodoFilter, order []*model.TodoOrder, userId string) (*models.TodoConnection, error) {
+	keys := todoOrder(order)
+
+	cond, args := ur.tenant("", userId)
+	if ur.workspaceId == nil {
+		shared := ur.db.Model((*models.Share)(nil)).Select("todo_id").Where("user_id = ?", userId)
+		cond, args = cond+" OR id IN (?)", append(args, shared)
+	}
+
+	q, err := filterTodos(ur.db.Model((*models.Todo)(nil)).Where("("+cond+")", args...), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	for _, bound := range []struct {
+		cursor   *string
+		backward bool
+	}{{page.After, false}, {page.Before, true}} {
+		if bound.cursor == nil {
+			continue
+		}
+
+		values, err := decodeCursor(keys, *bound.cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		cond, args := keyset(keys, values, bound.backward)
+		q = q.Where(cond, args...)
+	}
+
+	backward := page.Last != nil
+	limit := 0
+	if backward {
+		limit = *page.Last
+	} else if page.First != nil {
+		limit = *page.First
+	}
+
+	var todos []*models.Todo
+	if err := q.Order(orderBy(keys, backward)).Limit(limit + 1).Scopes(withCommentCount).Preload("User").Preload("Assignee").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	more := len(todos) > limit
+	if more {
+		todos = todos[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(todos)-1; i < j; i, j = i+1, j-1 {
+			todos[i], todos[j] = todos[j], todos[i]
+		}
+	}
+
+	conn := &models.TodoConnection{
+		Edges: make([]*models.TodoEdge, len(todos)),
+		PageInfo: &models.PageInfo{
+			HasNextPage:     more && !backward || backward && page.Before != nil,
+			HasPreviousPage: more && backward || !backward && page.After != nil,
+		},
+		TotalCount: total,
+	}
+
+	for i, todo := range todos {
+		conn.Edges[i] = &models.TodoEdge{
+			Cursor: encodeCursor(keys, todo),
+			Node:   todo,
+		}
+	}
+
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn, nil
+}
+
+func (ur *todoRepository) ListChildren(parentId string, userId string) ([]*models.Todo, error) {
+
+	var todos []*models.Todo
+	if err := ur.db.Model(todos).Where("parent_id = ?", parentId).Scopes(ur.scoped(userId), withCommentCount).Preload("User").Preload("Assignee").
+		Order("created").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// ListTree loads the subtree under rootId, or every tree of the user when
+// rootId is nil, as a flat list in a single query.
+func (ur *todoRepository) ListTree(rootId *string, userId string) ([]*models.Todo, error) {
+
+	var rootIds []string
+	if rootId != nil {
+		rootIds = []string{*rootId}
+	}
+
+	var todos []*models.Todo
+	if err := ur.db.Model(todos).Where("id IN (?)", ur.subtree(rootIds, userId)).Scopes(withCommentCount).Preload("User").Preload("Assignee").
+		Order("created").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// ListSubtrees loads rootIds and all of their descendants as a flat list in a
+// single query, with only the columns the progress of a todo needs.
+func (ur *todoRepository) ListSubtrees(rootIds []string, userId string) ([]*models.Todo, error) {
+
+	var todos []*models.Todo
+	if len(rootIds) == 0 {
+		return todos, nil
+	}
+
+	if err := ur.db.Model(todos).Select("id", "parent_id", "done").Where("id IN (?)", ur.subtree(rootIds, userId)).
+		Find(&todos).Error; err != nil {
 		return nil, err
 	}
 
 	return todos, nil
 }
+
+// subtree selects the ids of rootIds and all of their descendants with a
+// recursive CTE. Nil rootIds start from every top-level todo of the tenant.
+func (ur *todoRepository) subtree(rootIds []string, userId string) *gorm.DB {
+	tenant, args := ur.tenant("", userId)
+	roots := "parent_id IS NULL"
+	if rootIds != nil {
+		roots, args = "id IN ?", append(args, rootIds)
+	}
+
+	children, childArgs := ur.tenant("t.", userId)
+	return ur.db.Raw(`WITH RECURSIVE tree AS (
+		SELECT id FROM todos WHERE `+tenant+` AND deleted IS NULL AND `+roots+`
+		UNION ALL
+		SELECT t.id FROM todos t INNER JOIN tree ON t.parent_id = tree.id WHERE `+children+` AND t.deleted IS NULL
+	) SELECT id FROM tree`, append(args, childArgs...)...)
+}
+
+// trashedSubtree selects the ids of a trashed todo and every descendant that
+// was moved to the trash in the same delete.
+func (ur *todoRepository) trashedSubtree(todo *models.Todo) *gorm.DB {
+	children, args := ur.tenant("t.", todo.UserID.String())
+	return ur.db.Raw(`WITH RECURSIVE tree AS (
+		SELECT id FROM todos WHERE id = ?
+		UNION ALL
+		SELECT t.id FROM todos t INNER JOIN tree ON t.parent_id = tree.id WHERE `+children+` AND t.deleted = ?
+	) SELECT id FROM tree`, append(append([]interface{}{todo.ID}, args...), todo.Deleted)...)
+}
+
+// todoSearchRow is a todo scanned together with its search rank and snippet.
+type todoSearchRow struct {
+	models.Todo `gorm:"embedded"`
+	Rank        float64
+	Snippet     string
+}
+
+// Search runs a ranked full-text search over the todos of the tenant using
+// the generated search column and the text search configuration of the
+// tenant.
+func (ur *todoRepository) Search(query string, limit int, userId string) ([]*models.TodoSearchResult, error) {
+	tsquery := search.Query(query)
+	if tsquery == "" {
+		return []*models.TodoSearchResult{}, nil
+	}
+
+	tenant, args := ur.tenant("t.", userId)
+
+	var rows []todoSearchRow
+	if err := ur.db.Raw(`SELECT t.*,
+			(SELECT count(*) FROM comments WHERE comments.todo_id = t.id) AS comment_count,
+			ts_rank_cd(t.search, q.query) AS rank,
+			ts_headline(t.search_language, t.text, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS snippet
+		FROM todos t
+		CROSS JOIN (SELECT to_tsquery(search_language, ?) AS query FROM `+ur.languageTable()+` WHERE id = ?) q
+		WHERE `+tenant+` AND t.deleted IS NULL AND t.search @@ q.query
+		ORDER BY rank DESC, t.created DESC
+		LIMIT ?`, append(append([]interface{}{tsquery, ur.tenantId(userId)}, args...), limit)...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	// A raw scan can't preload, so the creators and assignees of the matches
+	// in a shared workspace are loaded by their ids.
+	ids := make([]uuid.UUID, 0, len(rows))
+	for i := range rows {
+		ids = append(ids, rows[i].UserID)
+		if rows[i].AssigneeID != nil {
+			ids = append(ids, *rows[i].AssigneeID)
+		}
+	}
+	users := make(map[uuid.UUID]*models.User, len(ids))
+	if len(ids) > 0 {
+		var found []*models.User
+		if err := ur.db.Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for _, user := range found {
+			users[user.ID] = user
+		}
+	}
+
+	results := make([]*models.TodoSearchResult, len(rows))
+	for i := range rows {
+		todo := rows[i].Todo
+		todo.User = users[todo.UserID]
+		if todo.AssigneeID != nil {
+			todo.Assignee = users[*todo.AssigneeID]
+		}
+		results[i] = &models.TodoSearchResult{
+			Todo:    &todo,
+			Rank:    rows[i].Rank,
+			Snippet: rows[i].Snippet,
+		}
+	}
+
+	return results, nil
+}
+
+// UpdateSearchLanguage follows a change of the language of the tenant on all
+// its todos: the personal todos of the user, or every todo of the workspace.
+func (ur *todoRepository) UpdateSearchLanguage(language string, userId string) error {
+
+	if err := ur.db.Unscoped().Model((*models.Todo)(nil)).Scopes(ur.scoped(userId)).
+		Update("search_language", language).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Insert stores a prepared todo in the tenant of the repository.
+func (ur *todoRepository) Insert(todo *models.Todo) error {
+
+	todo.WorkspaceID = ur.workspaceId
+	if todo.SearchLanguage == "" {
+		language, err := ur.searchLanguage(todo.UserID.String())
+		if err != nil {
+			return err
+		}
+		todo.SearchLanguage = language
+	}
+	if err := ur.db.Omit("User", "Assignee", "Status", "Children").Create(todo).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ur *todoRepository) Update(id string, userId string, fields map[string]interface{}) error {
+
+	if err := ur.db.Model((*models.Todo)(nil)).Where("id = ?", id).Scopes(ur.scoped(userId)).
+		Updates(fields).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ur *todoRepository) UpdateMany(ids []string, userId string, fields map[string]interface{}) error {
+
+	if err := ur.db.Model((*models.Todo)(nil)).Where("id IN ?", ids).Scopes(ur.scoped(userId)).
+		Updates(fields).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSeries updates the open occurrences of a recurring series that are
+// scheduled at or after from.
+func (ur *todoRepository) UpdateSeries(seriesId string, from time.Time, userId string, fields map[string]interface{}) error {
+
+	if err := ur.db.Model((*models.Todo)(nil)).
+		Where("series_id = ? AND done = false AND occurrence_at >= ?", seriesId, from).Scopes(ur.scoped(userId)).
+		Updates(fields).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Adjacent returns the todo right after, or right before, the given one in
+// the manual order, skipping excludeId. It returns nil at either end.
+func (ur *todoRepository) Adjacent(todo *models.Todo, next bool, excludeId string, userId string) (*models.Todo, error) {
+	cmp, dir := "<", "DESC"
+	if next {
+		cmp, dir = ">", "ASC"
+	}
+
+	var adjacent models.Todo
+	err := ur.db.Where("id <> ? AND (position, created, id) "+cmp+" (?, ?, ?)",
+		excludeId, todo.Position, todo.Created, todo.ID).Scopes(ur.scoped(userId)).
+		Order("position " + dir + ", created " + dir + ", id " + dir).Take(&adjacent).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &adjacent, nil
+}
+
+// Rebalance spreads the manual order of the tenant out again, keeping the
+// current order but leaving an even gap between every two todos.
+func (ur *todoRepository) Rebalance(userId string) error {
+
+	tenant, args := ur.tenant("", userId)
+	if err := ur.db.Exec(`UPDATE todos SET position = r.n * ?
+		FROM (
+			SELECT id, row_number() OVER (ORDER BY position, created, id) AS n
+			FROM todos WHERE `+tenant+` AND deleted IS NULL
+		) r
+		WHERE todos.id = r.id`, append([]interface{}{models.TodoPositionStep}, args...)...).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ur *todoRepository) WithTx(tx *gorm.DB) usecaseRepository.TodoRepository {
+	return &todoRepository{tx, ur.workspaceId}
+}
+
+// InWorkspace binds the repository to the todos of a workspace, or to the
+// personal todos of the users when workspaceId is nil.
+func (ur *todoRepository) InWorkspace(workspaceId *uuid.UUID) usecaseRepository.TodoRepository {
+	return &todoRepository{ur.db, workspaceId}
+}
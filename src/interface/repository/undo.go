@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"gorm.io/gorm"
+)
+
+type todoUndoRepository struct {
+	db *gorm.DB
+}
+
+type TodoUndoRepository interface {
+	Create(undo *models.TodoUndo) error
+	Get(id string, userId string) (*models.TodoUndo, error)
+	Delete(id string) error
+	DeleteExpired(now time.Time) (int64, error)
+	WithTx(tx *gorm.DB) usecaseRepository.TodoUndoRepository
+}
+
+func NewTodoUndoRepository(db *gorm.DB) TodoUndoRepository {
+	return &todoUndoRepository{db}
+}
+
+func (ur *todoUndoRepository) Create(undo *models.TodoUndo) error {
+
+	if err := ur.db.Omit("Todo").Create(undo).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get returns an undo of the user that hasn't expired yet.
+func (ur *todoUndoRepository) Get(id string, userId string) (*models.TodoUndo, error) {
+
+	var undo models.TodoUndo
+	if err := ur.db.Where("id = ? AND user_id = ? AND expires > ?", id, userId, time.Now()).
+		Take(&undo).Error; err != nil {
+		return nil, err
+	}
+
+	return &undo, nil
+}
+
+func (ur *todoUndoRepository) Delete(id string) error {
+
+	if err := ur.db.Where("id = ?", id).Delete(&models.TodoUndo{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ur *todoUndoRepository) DeleteExpired(now time.Time) (int64, error) {
+
+	res := ur.db.Where("expires <= ?", now).Delete(&models.TodoUndo{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (ur *todoUndoRepository) WithTx(tx *gorm.DB) usecaseRepository.TodoUndoRepository {
+	return &todoUndoRepository{tx}
+}
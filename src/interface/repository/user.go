@@ -3,6 +3,7 @@ package repository
 import (
 	"strings"
 	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +16,10 @@ type UserRepository interface {
 	Create(user models.User) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByID(id string) (*models.User, error)
+	UpdateTimezone(id string, timezone string) error
+	UpdateSearchLanguage(id string, language string) error
+	SearchLanguageExists(language string) (bool, error)
+	WithTx(tx *gorm.DB) usecaseRepository.UserRepository
 }
 
 func NewUserRepository(db *gorm.DB) UserRepository {
@@ -49,3 +54,37 @@ func (ur *userRepository) GetByID(id string) (*models.User, error) {
 
 	return &user, nil
 }
+
+func (ur *userRepository) UpdateTimezone(id string, timezone string) error {
+
+	if err := ur.db.Model((*models.User)(nil)).Where("id = ?", id).Update("timezone", timezone).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ur *userRepository) UpdateSearchLanguage(id string, language string) error {
+
+	if err := ur.db.Model((*models.User)(nil)).Where("id = ?", id).Update("search_language", language).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SearchLanguageExists reports whether Postgres knows a text search
+// configuration with the given name.
+func (ur *userRepository) SearchLanguageExists(language string) (bool, error) {
+
+	var count int64
+	if err := ur.db.Raw("SELECT count(*) FROM pg_ts_config WHERE cfgname = ?", language).Scan(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (ur *userRepository) WithTx(tx *gorm.DB) usecaseRepository.UserRepository {
+	return &userRepository{tx}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type workflowRepository struct {
+	db *gorm.DB
+}
+
+type WorkflowRepository interface {
+	List(workspaceId *uuid.UUID, userId string) (models.Workflow, error)
+	Replace(workspaceId *uuid.UUID, userId string, workflow models.Workflow) error
+	WithTx(tx *gorm.DB) usecaseRepository.WorkflowRepository
+}
+
+func NewWorkflowRepository(db *gorm.DB) WorkflowRepository {
+	return &workflowRepository{db}
+}
+
+// workflowOf limits statuses to the workflow of a workspace, or to the
+// workflow of the personal todos of the user when workspaceId is nil.
+func workflowOf(workspaceId *uuid.UUID, userId string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if workspaceId != nil {
+			return db.Where("workflow_statuses.workspace_id = ?", *workspaceId)
+		}
+
+		return db.Where("workflow_statuses.user_id = ? AND workflow_statuses.workspace_id IS NULL", userId)
+	}
+}
+
+func (wr *workflowRepository) List(workspaceId *uuid.UUID, userId string) (models.Workflow, error) {
+
+	var workflow models.Workflow
+	if err := wr.db.Scopes(workflowOf(workspaceId, userId)).Preload("Transitions.To").Order("position").
+		Find(&workflow).Error; err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// Replace stores workflow as the whole workflow of the tenant. Statuses that
+// aren't part of it anymore are removed, which moves their todos back to the
+// first status of their category.
+func (wr *workflowRepository) Replace(workspaceId *uuid.UUID, userId string, workflow models.Workflow) error {
+
+	ids := make([]uuid.UUID, 0, len(workflow))
+	transitions := make([]*models.WorkflowTransition, 0)
+	for _, status := range workflow {
+		ids = append(ids, status.ID)
+		transitions = append(transitions, status.Transitions...)
+	}
+
+	stale := wr.db.Scopes(workflowOf(workspaceId, userId))
+	if len(ids) > 0 {
+		stale = stale.Where("id NOT IN ?", ids)
+	}
+	if err := stale.Delete(&models.WorkflowStatus{}).Error; err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := wr.db.Where("from_id IN ?", ids).Delete(&models.WorkflowTransition{}).Error; err != nil {
+		return err
+	}
+
+	for _, status := range workflow {
+		if err := wr.db.Omit(clause.Associations).Save(status).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(transitions) > 0 {
+		if err := wr.db.Omit(clause.Associations).Create(&transitions).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (wr *workflowRepository) WithTx(tx *gorm.DB) usecaseRepository.WorkflowRepository {
+	return &workflowRepository{tx}
+}
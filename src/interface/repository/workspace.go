@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"strings"
+	"todo-service/src/models"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+type WorkspaceRepository interface {
+	Create(workspace *models.Workspace, owner *models.WorkspaceMember) error
+	GetByID(id string, userId string) (*models.Workspace, error)
+	ListByUser(userId string) ([]*models.Workspace, error)
+	GetMember(workspaceId string, userId string) (*models.WorkspaceMember, error)
+	ListMembers(workspaceId string) ([]*models.WorkspaceMember, error)
+	AddMember(member *models.WorkspaceMember) error
+	UpdateMemberRole(workspaceId string, userId string, role models.WorkspaceRole) error
+	RemoveMember(workspaceId string, userId string) (bool, error)
+	UpdateSearchLanguage(id string, language string) error
+	UpsertInvitation(invitation *models.WorkspaceInvitation) error
+	GetInvitation(id string) (*models.WorkspaceInvitation, error)
+	GetInvitationByEmail(workspaceId string, email string) (*models.WorkspaceInvitation, error)
+	ListInvitationsByEmail(email string) ([]*models.WorkspaceInvitation, error)
+	DeleteInvitation(id string) error
+	WithTx(tx *gorm.DB) usecaseRepository.WorkspaceRepository
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db}
+}
+
+// Create stores a new workspace together with its owner.
+func (wr *workspaceRepository) Create(workspace *models.Workspace, owner *models.WorkspaceMember) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit("Workspace", "User").Create(owner).Error
+	})
+}
+
+// withMyRole selects the role of the user along with each workspace and only
+// keeps the workspaces the user is a member of.
+func withMyRole(userId string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select("workspaces.*, m.role AS my_role").
+			Joins("INNER JOIN workspace_members m ON m.workspace_id = workspaces.id AND m.user_id = ?", userId)
+	}
+}
+
+func (wr *workspaceRepository) GetByID(id string, userId string) (*models.Workspace, error) {
+
+	var workspace models.Workspace
+	if err := wr.db.Model(workspace).Scopes(withMyRole(userId)).Where("workspaces.id = ?", id).
+		Take(&workspace).Error; err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+func (wr *workspaceRepository) ListByUser(userId string) ([]*models.Workspace, error) {
+
+	var workspaces []*models.Workspace
+	if err := wr.db.Model(workspaces).Scopes(withMyRole(userId)).Order("workspaces.name, workspaces.id").
+		Find(&workspaces).Error; err != nil {
+		return nil, err
+	}
+
+	return workspaces, nil
+}
+
+func (wr *workspaceRepository) GetMember(workspaceId string, userId string) (*models.WorkspaceMember, error) {
+
+	var member models.WorkspaceMember
+	if err := wr.db.Where("workspace_id = ? AND user_id = ?", workspaceId, userId).Preload("User").
+		Take(&member).Error; err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (wr *workspaceRepository) ListMembers(workspaceId string) ([]*models.WorkspaceMember, error) {
+
+	var members []*models.WorkspaceMember
+	if err := wr.db.Where("workspace_id = ?", workspaceId).Preload("User").Order("created").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (wr *workspaceRepository) AddMember(member *models.WorkspaceMember) error {
+
+	if err := wr.db.Omit("Workspace", "User").Create(member).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepository) UpdateMemberRole(workspaceId string, userId string, role models.WorkspaceRole) error {
+
+	if err := wr.db.Model((*models.WorkspaceMember)(nil)).Where("workspace_id = ? AND user_id = ?", workspaceId, userId).
+		Update("role", role).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepository) UpdateSearchLanguage(id string, language string) error {
+
+	if err := wr.db.Model((*models.Workspace)(nil)).Where("id = ?", id).Update("search_language", language).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepository) RemoveMember(workspaceId string, userId string) (bool, error) {
+
+	res := wr.db.Where("workspace_id = ? AND user_id = ?", workspaceId, userId).Delete(&models.WorkspaceMember{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// UpsertInvitation invites the email to the workspace, replacing the role of
+// an open invitation for the same email.
+func (wr *workspaceRepository) UpsertInvitation(invitation *models.WorkspaceInvitation) error {
+
+	invitation.Email = strings.ToLower(invitation.Email)
+	if err := wr.db.Omit("Workspace").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "invited_by_id"}),
+	}).Create(invitation).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepository) GetInvitation(id string) (*models.WorkspaceInvitation, error) {
+
+	var invitation models.WorkspaceInvitation
+	if err := wr.db.Where("id = ?", id).Preload("Workspace").Take(&invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (wr *workspaceRepository) GetInvitationByEmail(workspaceId string, email string) (*models.WorkspaceInvitation, error) {
+
+	var invitation models.WorkspaceInvitation
+	if err := wr.db.Where("workspace_id = ? AND email = ?", workspaceId, strings.ToLower(email)).Preload("Workspace").
+		Take(&invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (wr *workspaceRepository) ListInvitationsByEmail(email string) ([]*models.WorkspaceInvitation, error) {
+
+	var invitations []*models.WorkspaceInvitation
+	if err := wr.db.Where("email = ?", strings.ToLower(email)).Preload("Workspace").Order("created").
+		Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (wr *workspaceRepository) DeleteInvitation(id string) error {
+
+	if err := wr.db.Where("id = ?", id).Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (wr *workspaceRepository) WithTx(tx *gorm.DB) usecaseRepository.WorkspaceRepository {
+	return &workspaceRepository{tx}
+}
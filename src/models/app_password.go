@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	ErrAppPasswordNotFound = &gqlerror.Error{Message: "app password not found"}
+	ErrAppPasswordName     = &gqlerror.Error{Message: "app password name has to be 1 to 64 characters long"}
+	ErrAppPasswordInvalid  = &gqlerror.Error{Message: "invalid email or app password"}
+)
+
+// AppPassword lets a client that can't sign in with a bearer token, like a
+// CalDAV client, act as its user. Only the hash of the password is stored,
+// so Password is only known right after it was created.
+type AppPassword struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User   *User     `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+
+	Name         string     `json:"name" gorm:"type:varchar(64);not null"`
+	PasswordHash string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	Password     string     `json:"password,omitempty" gorm:"-"`
+	LastUsed     *time.Time `json:"last_used"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+}
+
+// AppPasswordTouchInterval is how often the last use of an app password is
+// recorded at most.
+const AppPasswordTouchInterval = time.Minute
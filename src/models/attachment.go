@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrAttachmentNotFound = &gqlerror.Error{Message: "attachment not found"}
+	ErrAttachmentTooLarge = &gqlerror.Error{Message: "attachment is too large"}
+	ErrAttachmentType     = &gqlerror.Error{Message: "attachment type is not allowed"}
+	// ErrAttachmentCleanup warns that blobs of deleted attachments are left
+	// for the trash purge job to remove.
+	ErrAttachmentCleanup = &gqlerror.Error{Message: "attachments could not be removed yet"}
+)
+
+// AttachmentSettings holds the limits of uploaded files and how long their
+// download links stay valid.
+type AttachmentSettings struct {
+	MaxSize      int64
+	AllowedTypes []string
+	URLLifetime  time.Duration
+}
+
+type Attachment struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	TodoID uuid.UUID `json:"todo_id" gorm:"type:uuid;index"`
+	// UserID is the owner of the todo, UploaderID the user who attached the
+	// file, who may be a workspace member or share recipient.
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	UploaderID  uuid.UUID `json:"uploader_id" gorm:"type:uuid;index"`
+	Filename    string    `json:"filename" gorm:"type:varchar(255);not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(255);not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	// Key locates the content of the attachment in the blob store.
+	Key string `json:"-" gorm:"type:varchar(255);not null;uniqueIndex"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+}
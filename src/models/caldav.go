@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	ErrCalendarObjectNotFound = &gqlerror.Error{Message: "calendar object not found"}
+	ErrCalendarObjectUID      = &gqlerror.Error{Message: "calendar object has to hold one todo with the UID it is named after"}
+	ErrCalendarSyncToken      = &gqlerror.Error{Message: "sync token is invalid or too old"}
+)
+
+// CalendarSyncTokenPrefix starts every sync token of a CalDAV collection,
+// which have to be URIs.
+const CalendarSyncTokenPrefix = "urn:todo-service:sync:"
+
+// CalendarObject is a todo as a CalDAV resource: a calendar of the one VTODO
+// with its UID. Deleted objects only come with their UID.
+type CalendarObject struct {
+	UID     string
+	ETag    string
+	Data    string
+	Todo    *Todo
+	Deleted bool
+}
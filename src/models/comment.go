@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrCommentNotFound  = &gqlerror.Error{Message: "comment not found"}
+	ErrCommentNotAuthor = &gqlerror.Error{Message: "only the author can change a comment"}
+)
+
+type Comment struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	TodoID uuid.UUID `json:"todo_id" gorm:"type:uuid;index:idx_comments_todo_created,priority:1"`
+	Todo   *Todo     `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid"`
+	User   *User     `json:"user" gorm:"foreignKey:UserID"`
+	// Body is markdown and is rendered by the clients.
+	Body string `json:"body" gorm:"type:text;not null"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime;index:idx_comments_todo_created,priority:2"`
+	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+}
+
+type CommentEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Comment `json:"node"`
+}
+
+type CommentConnection struct {
+	Edges      []*CommentEdge `json:"edges"`
+	PageInfo   *PageInfo      `json:"page_info"`
+	TotalCount int64          `json:"total_count"`
+}
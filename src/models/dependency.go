@@ -0,0 +1,35 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrTodoBlocked          = &gqlerror.Error{Message: "todo is blocked by open todos"}
+	ErrTodoDependencyCycle  = &gqlerror.Error{Message: "dependency would create a cycle"}
+	ErrTodoDependencyTenant = &gqlerror.Error{Message: "dependent todos must belong to the same workspace"}
+)
+
+// BlockedRule defines what happens when a todo is completed while todos that
+// block it are still open.
+type BlockedRule string
+
+const (
+	// BlockedRefuse refuses to complete the todo.
+	BlockedRefuse BlockedRule = "refuse"
+	// BlockedWarn completes the todo and warns about its open blockers.
+	BlockedWarn BlockedRule = "warn"
+)
+
+// TodoDependency is an edge of the dependency graph of todos: the blocker
+// has to be completed before the blocked todo.
+type TodoDependency struct {
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"type:uuid;primarykey"`
+	Blocker   *Todo     `json:"-" gorm:"foreignKey:BlockerID;constraint:OnDelete:CASCADE"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"type:uuid;primarykey;index"`
+	Blocked   *Todo     `json:"-" gorm:"foreignKey:BlockedID;constraint:OnDelete:CASCADE"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+}
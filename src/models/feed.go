@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	ErrCalendarFeedNotFound = &gqlerror.Error{Message: "calendar feed not found"}
+)
+
+// CalendarFeedPath is the path feed links start with, followed by the token
+// and CalendarFeedExtension, so that calendar apps recognize the link as an
+// iCalendar file.
+const (
+	CalendarFeedPath      = "/api/v1/feeds/"
+	CalendarFeedExtension = ".ics"
+)
+
+// CalendarFeed is the secret link a user subscribes to the open todos with in
+// a calendar app. Only the hash of its token is stored, so URL is only known
+// right after the token was generated.
+type CalendarFeed struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	User   *User     `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+
+	TokenHash string `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	URL       string `json:"url,omitempty" gorm:"-"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+}
+
+// FeedSettings configures the links of calendar feeds. BaseURL is the scheme
+// and host the links start with.
+type FeedSettings struct {
+	BaseURL string
+}
+
+// TodoVersion sums up the state of a list of todos: it changes whenever a
+// todo of the list is added, changed or deleted. Emptied is when the trash of
+// the list was last emptied, which leaves no trace of the todos it held.
+type TodoVersion struct {
+	Count   int64
+	Updated time.Time
+	Emptied time.Time
+}
+
+// TrashEmptied records when a tenant last emptied its trash. TenantID is the
+// workspace, or the user for their personal todos.
+type TrashEmptied struct {
+	TenantID uuid.UUID `json:"tenant_id" gorm:"type:uuid;primarykey"`
+	Emptied  time.Time `json:"emptied" gorm:"not null"`
+}
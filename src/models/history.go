@@ -0,0 +1,131 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TodoEventAction is the kind of change a TodoEvent records.
+type TodoEventAction string
+
+const (
+	TodoEventCreated   TodoEventAction = "CREATED"
+	TodoEventUpdated   TodoEventAction = "UPDATED"
+	TodoEventCompleted TodoEventAction = "COMPLETED"
+	TodoEventDeleted   TodoEventAction = "DELETED"
+	TodoEventRestored  TodoEventAction = "RESTORED"
+	TodoEventMoved     TodoEventAction = "MOVED"
+	TodoEventAssigned  TodoEventAction = "ASSIGNED"
+)
+
+// TodoEvent is one entry in the history of a todo. Events are only ever
+// added: a change to a field is recorded with its old and new value, while
+// creating, deleting and restoring a todo are recorded without a field.
+type TodoEvent struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	TodoID  uuid.UUID       `json:"todo_id" gorm:"type:uuid;not null;index:idx_todo_events_todo_created,priority:1"`
+	Todo    *Todo           `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	ActorID uuid.UUID       `json:"actor_id" gorm:"type:uuid;not null"`
+	Actor   *User           `json:"actor" gorm:"foreignKey:ActorID"`
+	Action  TodoEventAction `json:"action" gorm:"type:varchar(16);not null"`
+
+	Field    string  `json:"field" gorm:"type:varchar(32)"`
+	OldValue *string `json:"old_value" gorm:"type:text"`
+	NewValue *string `json:"new_value" gorm:"type:text"`
+
+	Created time.Time `json:"created" gorm:"not null;index:idx_todo_events_todo_created,priority:2"`
+}
+
+// NewTodoEvent returns an event for a change that concerns the todo as a
+// whole.
+func NewTodoEvent(todoId uuid.UUID, actorId uuid.UUID, action TodoEventAction) *TodoEvent {
+	return &TodoEvent{
+		ID:      uuid.New(),
+		TodoID:  todoId,
+		ActorID: actorId,
+		Action:  action,
+		Created: time.Now(),
+	}
+}
+
+// TodoChanges compares a todo before and after a change and returns an event
+// for every field the user can change that now has a different value.
+func TodoChanges(action TodoEventAction, actorId uuid.UUID, before *Todo, after *Todo) []*TodoEvent {
+	fields := []struct {
+		name     string
+		old, new *string
+	}{
+		{"text", &before.Text, &after.Text},
+		{"done", boolValue(before.Done), boolValue(after.Done)},
+		{"dueAt", timeValue(before.DueAt), timeValue(after.DueAt)},
+		{"rrule", stringValue(before.RRule), stringValue(after.RRule)},
+		{"parentId", uuidValue(before.ParentID), uuidValue(after.ParentID)},
+		{"assigneeId", uuidValue(before.AssigneeID), uuidValue(after.AssigneeID)},
+		{"statusId", uuidValue(before.StatusID), uuidValue(after.StatusID)},
+		{"priority", stringValue(string(before.Priority)), stringValue(string(after.Priority))},
+		{"tags", stringValue(strings.Join(before.Tags, ",")), stringValue(strings.Join(after.Tags, ","))},
+		{"position", floatValue(before.Position), floatValue(after.Position)},
+	}
+
+	events := make([]*TodoEvent, 0)
+	for _, field := range fields {
+		if equalValues(field.old, field.new) {
+			continue
+		}
+
+		event := NewTodoEvent(after.ID, actorId, action)
+		event.Field = field.name
+		event.OldValue = field.old
+		event.NewValue = field.new
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func equalValues(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func boolValue(v bool) *string {
+	s := strconv.FormatBool(v)
+	return &s
+}
+
+func floatValue(v float64) *string {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	return &s
+}
+
+func stringValue(v string) *string {
+	if v == "" {
+		return nil
+	}
+
+	return &v
+}
+
+func timeValue(v *time.Time) *string {
+	if v == nil {
+		return nil
+	}
+
+	s := v.UTC().Format(time.RFC3339)
+	return &s
+}
+
+func uuidValue(v *uuid.UUID) *string {
+	if v == nil {
+		return nil
+	}
+
+	s := v.String()
+	return &s
+}
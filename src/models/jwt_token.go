@@ -20,5 +20,8 @@ type SessionDetails struct {
 
 type JwtCustomClaim struct {
 	ID uuid.UUID `json:"id"`
+	// WorkspaceID selects the active workspace when the request doesn't
+	// select one with the X-Workspace-ID header.
+	WorkspaceID string `json:"workspace_id,omitempty"`
 	jwt.StandardClaims
 }
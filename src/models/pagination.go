@@ -0,0 +1,36 @@
+package models
+
+import (
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	ErrPageArgsInvalid = &gqlerror.Error{Message: "invalid pagination arguments"}
+	ErrCursorInvalid   = &gqlerror.Error{Message: "invalid cursor"}
+)
+
+// PageArgs are the Relay connection arguments of a paginated query.
+type PageArgs struct {
+	First  *int
+	After  *string
+	Last   *int
+	Before *string
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"has_next_page"`
+	HasPreviousPage bool    `json:"has_previous_page"`
+	StartCursor     *string `json:"start_cursor"`
+	EndCursor       *string `json:"end_cursor"`
+}
+
+type TodoEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Todo  `json:"node"`
+}
+
+type TodoConnection struct {
+	Edges      []*TodoEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"page_info"`
+	TotalCount int64       `json:"total_count"`
+}
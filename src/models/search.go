@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	ErrSearchLanguageNotFound = &gqlerror.Error{Message: "search language not found"}
+)
+
+type TodoSearchResult struct {
+	Todo    *Todo   `json:"todo"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrTodoForbidden     = &gqlerror.Error{Message: "you don't have permission to do this with the todo"}
+	ErrShareRoleInvalid  = &gqlerror.Error{Message: "todos can only be shared as VIEWER or EDITOR"}
+	ErrShareUserNotFound = &gqlerror.Error{Message: "user to share with not found"}
+	ErrShareWithSelf     = &gqlerror.Error{Message: "todos can't be shared with their owner"}
+)
+
+// TodoRole is what a user may do with a todo. Roles are ordered, every role
+// allows everything the roles below it allow.
+type TodoRole string
+
+const (
+	// TodoRoleViewer can see the todo, its subtasks and its comments.
+	TodoRoleViewer TodoRole = "VIEWER"
+	// TodoRoleEditor can also change, complete, move and comment on the todo
+	// and add subtasks to it.
+	TodoRoleEditor TodoRole = "EDITOR"
+	// TodoRoleOwner can also delete, restore and share the todo.
+	TodoRoleOwner TodoRole = "OWNER"
+)
+
+var todoRoleRank = map[TodoRole]int{
+	TodoRoleViewer: 1,
+	TodoRoleEditor: 2,
+	TodoRoleOwner:  3,
+}
+
+// Allows reports whether the role includes the needed one.
+func (r TodoRole) Allows(need TodoRole) bool {
+	return todoRoleRank[r] > 0 && todoRoleRank[r] >= todoRoleRank[need]
+}
+
+// Share gives a user access to a todo of someone else. The access extends to
+// every subtask of the todo.
+type Share struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	TodoID uuid.UUID `json:"todo_id" gorm:"type:uuid;uniqueIndex:idx_shares_todo_user,priority:1"`
+	Todo   *Todo     `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;uniqueIndex:idx_shares_todo_user,priority:2;index"`
+	User   *User     `json:"user" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Role   TodoRole  `json:"role" gorm:"not null"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+}
+
+// TodoAccess is what the role of a user on a todo is decided from: the owner
+// of the todo, its workspace along with the role of the user there, and the
+// best role shared with the user on the todo or one of its parents.
+type TodoAccess struct {
+	OwnerID     uuid.UUID
+	WorkspaceID *uuid.UUID
+	MemberRole  WorkspaceRole
+	SharedRole  TodoRole
+
+	// Role is the resulting role of the user, empty without access.
+	Role TodoRole
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrTemplateNotFound    = &gqlerror.Error{Message: "template not found"}
+	ErrTemplateName        = &gqlerror.Error{Message: "template name has to be 1 to 128 characters long"}
+	ErrTemplateVariable    = &gqlerror.Error{Message: "missing value for a template variable"}
+	ErrTemplateTextTooLong = &gqlerror.Error{Message: "todo text is too long once the template is filled in"}
+	ErrTemplateTooLarge    = &gqlerror.Error{Message: "template has too many items"}
+)
+
+// TemplateMaxItems caps the todos a template can create at once.
+const TemplateMaxItems = 500
+
+// TodoTemplate is a tree of todos that can be created again and again. It
+// belongs to a workspace, or to its user when WorkspaceID is nil.
+type TodoTemplate struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User        *User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	WorkspaceID *uuid.UUID `json:"workspace_id" gorm:"type:uuid;index"`
+	Workspace   *Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE"`
+
+	Name  string          `json:"name" gorm:"type:varchar(128);not null"`
+	Items []*TemplateItem `json:"items" gorm:"type:jsonb;serializer:json;not null"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+}
+
+// TemplateItem is a todo of a template. Its text may contain placeholders
+// like {{date}} that are filled in when the template is used, and DueOffset
+// is the time in seconds from the start of the template to its due date.
+// Items saved before templates kept the priority have none.
+type TemplateItem struct {
+	Text      string          `json:"text"`
+	DueOffset *int64          `json:"due_offset,omitempty"`
+	RRule     string          `json:"rrule,omitempty"`
+	Priority  TodoPriority    `json:"priority,omitempty"`
+	Tags      TodoTags        `json:"tags,omitempty"`
+	Children  []*TemplateItem `json:"children,omitempty"`
+}
+
+// CountTemplateItems returns the number of todos in a list of items,
+// including every descendant.
+func CountTemplateItems(items []*TemplateItem) int {
+	count := len(items)
+	for _, item := range items {
+		count += CountTemplateItems(item.Children)
+	}
+
+	return count
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// FillTemplate replaces the placeholders in text with their values. It fails
+// with ErrTemplateVariable if text uses a variable without a value.
+func FillTemplate(text string, variables map[string]string) (string, error) {
+	var missing bool
+	filled := placeholder.ReplaceAllStringFunc(text, func(match string) string {
+		value, ok := variables[placeholder.FindStringSubmatch(match)[1]]
+		if !ok {
+			missing = true
+		}
+		return value
+	})
+
+	if missing {
+		return "", ErrTemplateVariable
+	}
+
+	return filled, nil
+}
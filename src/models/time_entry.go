@@ -0,0 +1,64 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrTimerNotRunning    = &gqlerror.Error{Message: "no timer is running"}
+	ErrTimeEntryNotFound  = &gqlerror.Error{Message: "time entry not found"}
+	ErrTimeEntryInvalid   = &gqlerror.Error{Message: "time entry has to end after it starts"}
+	ErrTimeReportInvalid  = &gqlerror.Error{Message: "time report has to end after it starts"}
+	ErrTimeReportGrouping = &gqlerror.Error{Message: "invalid time report grouping"}
+)
+
+// TimeEntry is time a user spent on a todo, either tracked with a timer or
+// entered by hand. A user has at most one running timer, which is the entry
+// without Stopped.
+type TimeEntry struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	TodoID uuid.UUID `json:"todo_id" gorm:"type:uuid;not null;index"`
+	Todo   *Todo     `json:"todo" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_time_entries_user_started,priority:1;uniqueIndex:idx_time_entries_running,where:stopped IS NULL"`
+	User   *User     `json:"user" gorm:"foreignKey:UserID"`
+
+	Started time.Time  `json:"started" gorm:"not null;index:idx_time_entries_user_started,priority:2"`
+	Stopped *time.Time `json:"stopped"`
+	Note    string     `json:"note" gorm:"type:varchar(255);not null;default:''"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+}
+
+// Duration is the time spent so far, counting a running timer up to now.
+func (e *TimeEntry) Duration(now time.Time) time.Duration {
+	stopped := now
+	if e.Stopped != nil {
+		stopped = *e.Stopped
+	}
+
+	if stopped.Before(e.Started) {
+		return 0
+	}
+
+	return stopped.Sub(e.Started)
+}
+
+// TimeReportGroup is what the rows of a time report add up time by.
+type TimeReportGroup string
+
+const (
+	TimeReportGroupDay  TimeReportGroup = "DAY"
+	TimeReportGroupTodo TimeReportGroup = "TODO"
+)
+
+// TimeReportRow is the time spent on a day or on a todo. Day is midnight in
+// the time zone of the user.
+type TimeReportRow struct {
+	Day     *time.Time `json:"day"`
+	Todo    *Todo      `json:"todo"`
+	Seconds int64      `json:"seconds"`
+}
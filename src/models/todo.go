@@ -1,18 +1,233 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gorm.io/gorm"
+	"strings"
 	"time"
 )
 
+var (
+	ErrTodoParentNotFound = &gqlerror.Error{Message: "parent todo not found"}
+	ErrTodoHasChildren    = &gqlerror.Error{Message: "todo has subtasks"}
+	ErrTodoInvalidRRule   = &gqlerror.Error{Message: "invalid recurrence rule"}
+	ErrTodoRRuleNeedsDue  = &gqlerror.Error{Message: "recurring todo requires a due date"}
+	ErrTodoRRuleScope     = &gqlerror.Error{Message: "recurrence rule can only be changed for future occurrences"}
+	ErrTodoFilterInvalid  = &gqlerror.Error{Message: "invalid todo filter"}
+	ErrTodoNotInTrash     = &gqlerror.Error{Message: "todo not found in trash"}
+	ErrTodoNotFound       = &gqlerror.Error{Message: "todo not found"}
+	ErrTodoBulkTooLarge   = &gqlerror.Error{Message: "too many todos in one request"}
+	ErrTodoMoveTarget     = &gqlerror.Error{Message: "beforeId or afterId is required"}
+	ErrTodoMoveInvalid    = &gqlerror.Error{Message: "afterId must come before beforeId"}
+	ErrTodoAssigneeAccess = &gqlerror.Error{Message: "assignee has no access to the todo"}
+)
+
+// CascadeRule defines what happens to the subtasks of a todo when the todo
+// itself is completed or deleted.
+type CascadeRule string
+
+const (
+	// CascadeNone leaves subtasks untouched.
+	CascadeNone CascadeRule = "none"
+	// CascadeChildren applies the change to every descendant as well.
+	CascadeChildren CascadeRule = "children"
+	// CascadeReparent moves direct subtasks up to the parent of a deleted todo.
+	CascadeReparent CascadeRule = "reparent"
+	// CascadeRestrict refuses to delete a todo that still has subtasks.
+	CascadeRestrict CascadeRule = "restrict"
+)
+
+// TodoPriority is how urgent a todo is.
+type TodoPriority string
+
+const (
+	TodoPriorityNone   TodoPriority = "NONE"
+	TodoPriorityLow    TodoPriority = "LOW"
+	TodoPriorityMedium TodoPriority = "MEDIUM"
+	TodoPriorityHigh   TodoPriority = "HIGH"
+)
+
+// TodoTags are the labels of a todo, stored as a JSON array.
+type TodoTags []string
+
+// NormalizeTags lowercases tags, drops a leading # and removes empty and
+// duplicate tags while keeping their order.
+func NormalizeTags(tags []string) TodoTags {
+	normalized := make(TodoTags, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+func (t TodoTags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (t *TodoTags) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = TodoTags{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(t))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(t))
+	default:
+		return errors.New("unsupported type for todo tags")
+	}
+}
+
+// TodoPositionStep is the gap left between todos in the manual order when
+// they are appended or rebalanced.
+const TodoPositionStep = 1024.0
+
+// TodoSettings holds the configurable behaviour of todos.
+type TodoSettings struct {
+	CompleteCascade CascadeRule
+	DeleteCascade   CascadeRule
+	PageSize        int
+	MaxPageSize     int
+	TrashRetention  time.Duration
+	UndoWindow      time.Duration
+	BlockedComplete BlockedRule
+}
+
 type Todo struct {
 	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
 
-	Text   string    `json:"text" gorm:"type:varchar(255);not null"`
-	Done   bool      `json:"done" gorm:"type:bool;default:false"`
-	UserID uuid.UUID `json:"user_id" gorm:"type:uuid"`
-	User   *User     `json:"user" gorm:"foreignKey:UserID""`
+	Text     string     `json:"text" gorm:"type:varchar(255);not null"`
+	Done     bool       `json:"done" gorm:"type:bool;default:false"`
+	UserID   uuid.UUID  `json:"user_id" gorm:"type:uuid"`
+	User     *User      `json:"user" gorm:"foreignKey:UserID"`
+	ParentID *uuid.UUID `json:"parent_id" gorm:"type:uuid;index"`
+	Children []*Todo    `json:"children" gorm:"foreignKey:ParentID"`
+
+	// WorkspaceID is the tenant of the todo. Todos without a workspace are
+	// the personal todos of their user.
+	WorkspaceID *uuid.UUID `json:"workspace_id" gorm:"type:uuid;index"`
+	Workspace   *Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE"`
+
+	// AssigneeID is the user who is expected to work on the todo, which can
+	// be someone other than the user who created it.
+	AssigneeID *uuid.UUID `json:"assignee_id" gorm:"type:uuid;index"`
+	Assignee   *User      `json:"assignee" gorm:"foreignKey:AssigneeID;constraint:OnDelete:SET NULL"`
+
+	// StatusID is the workflow status the todo was last moved to, see
+	// Workflow.Current for the status it is in.
+	StatusID *uuid.UUID      `json:"status_id" gorm:"type:uuid;index"`
+	Status   *WorkflowStatus `json:"-" gorm:"foreignKey:StatusID;constraint:OnDelete:SET NULL"`
+
+	Priority TodoPriority `json:"priority" gorm:"type:varchar(8);not null;default:NONE"`
+	Tags     TodoTags     `json:"tags" gorm:"type:jsonb;not null;default:'[]';index:idx_todos_tags,type:gin"`
+
+	// DueAt is when the todo is due. For recurring todos OccurrenceAt keeps
+	// the slot of the series this todo stands for, even if DueAt is moved.
+	DueAt        *time.Time `json:"due_at"`
+	RRule        string     `json:"rrule" gorm:"type:varchar(255)"`
+	SeriesID     *uuid.UUID `json:"series_id" gorm:"type:uuid;index"`
+	SeriesStart  *time.Time `json:"series_start"`
+	OccurrenceAt *time.Time `json:"occurrence_at"`
+
+	// CompletedAt is when the todo was last completed, nil while it is open.
+	CompletedAt *time.Time `json:"completed_at"`
+
+	// UID identifies a todo imported from a calendar by the UID it has there.
+	// Other todos are identified by their id, see CalendarUID.
+	UID string `json:"uid" gorm:"type:varchar(255);not null;default:'';index"`
+	// ParentUID is the CalendarUID of the parent, only filled by Each.
+	ParentUID string `json:"-" gorm:"->;-:migration"`
+
+	// Position orders the todos of a user manually. Moving a todo places it
+	// halfway between its new neighbours.
+	Position float64 `json:"position" gorm:"not null;default:0;index"`
+
+	// CommentCount is only filled by queries that list todos.
+	CommentCount int64 `json:"comment_count" gorm:"->;-:migration"`
+
+	// UndoToken is only set on a todo returned by a change that can be undone.
+	UndoToken string `json:"-" gorm:"-"`
+	// Warnings are about a change that went through despite them.
+	Warnings []string `json:"-" gorm:"-"`
+
+	// SearchLanguage mirrors the text search configuration of the owner and
+	// feeds the generated Search column.
+	SearchLanguage string `json:"search_language" gorm:"type:regconfig;not null;default:simple"`
+	Search         string `json:"-" gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (to_tsvector(search_language, text)) STORED;index:idx_todos_search,type:gin"`
+
+	Created time.Time      `json:"created" gorm:"autoCreateTime"`
+	Updated time.Time      `json:"updated" gorm:"autoUpdateTime"`
+	Deleted gorm.DeletedAt `json:"deleted" gorm:"index"`
+}
+
+// CalendarUID is the UID of the todo in calendars.
+func (t *Todo) CalendarUID() string {
+	if t.UID != "" {
+		return t.UID
+	}
+
+	return t.ID.String()
+}
+
+// Progress rolls up completion of the todo over its loaded subtasks: a done
+// todo counts as 1, a leaf as 0, and otherwise the mean of its children.
+func (t *Todo) Progress() float64 {
+	if t.Done {
+		return 1
+	}
+
+	if len(t.Children) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, child := range t.Children {
+		sum += child.Progress()
+	}
+
+	return sum / float64(len(t.Children))
+}
+
+// BuildTodoTree links a flat list of todos into trees and returns the roots.
+// A todo whose parent is not part of the list is treated as a root.
+func BuildTodoTree(todos []*Todo) []*Todo {
+	byID := make(map[uuid.UUID]*Todo, len(todos))
+	for _, todo := range todos {
+		todo.Children = make([]*Todo, 0)
+		byID[todo.ID] = todo
+	}
+
+	roots := make([]*Todo, 0)
+	for _, todo := range todos {
+		if todo.ParentID != nil {
+			if parent, ok := byID[*todo.ParentID]; ok {
+				parent.Children = append(parent.Children, todo)
+				continue
+			}
+		}
+		roots = append(roots, todo)
+	}
 
-	Created time.Time `json:"created" gorm:"autoCreateTime"`
-	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+	return roots
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	ErrTransferFormat   = &gqlerror.Error{Message: "unsupported file format"}
+	ErrImportFile       = &gqlerror.Error{Message: "file can't be read in the given format"}
+	ErrImportNoText     = &gqlerror.Error{Message: "no column is mapped to the text of the todos"}
+	ErrImportTooLarge   = &gqlerror.Error{Message: "too many rows in the file"}
+	ErrImportParentRow  = &gqlerror.Error{Message: "parent todo not found in the file or the list"}
+	ErrImportDuplicated = &gqlerror.Error{Message: "id is used by more than one row"}
+)
+
+// TodoImportMaxRows is the largest number of rows one import can hold.
+const TodoImportMaxRows = 10000
+
+// TodoFileFormat is the file format of an import or export of todos.
+type TodoFileFormat string
+
+const (
+	TodoFileFormatJSON TodoFileFormat = "JSON"
+	TodoFileFormatCSV  TodoFileFormat = "CSV"
+	// TodoFileFormatICS is an iCalendar file of VTODO components.
+	TodoFileFormatICS TodoFileFormat = "ICS"
+	// TodoFileFormatTodoTxt is a todo.txt file with one todo per line.
+	TodoFileFormatTodoTxt TodoFileFormat = "TODO_TXT"
+)
+
+// TodoField is a field of a todo that a column of an imported file can be
+// mapped to.
+type TodoField string
+
+const (
+	// TodoFieldID only links rows to their parent row within the file,
+	// imported todos get new ids.
+	TodoFieldID       TodoField = "ID"
+	TodoFieldParentID TodoField = "PARENT_ID"
+	TodoFieldText     TodoField = "TEXT"
+	TodoFieldDone     TodoField = "DONE"
+	TodoFieldDueAt    TodoField = "DUE_AT"
+	TodoFieldPriority TodoField = "PRIORITY"
+	TodoFieldTags     TodoField = "TAGS"
+	TodoFieldRRule    TodoField = "RRULE"
+)
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrUndoNotFound = &gqlerror.Error{Message: "nothing to undo or undo expired"}
+	ErrUndoConflict = &gqlerror.Error{Message: "todo was changed since, the change can't be undone"}
+)
+
+// TodoUndo is what it takes to revert a single change to a todo. Its ID is
+// the token handed out with the change, which only the user who made the
+// change can redeem, once and before Expires.
+type TodoUndo struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey"`
+
+	TodoID uuid.UUID       `json:"todo_id" gorm:"type:uuid;not null;index"`
+	Todo   *Todo           `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	UserID uuid.UUID       `json:"user_id" gorm:"type:uuid;not null"`
+	Action TodoEventAction `json:"action" gorm:"type:varchar(16);not null"`
+
+	// OwnerID and WorkspaceID locate the todo, which may be in the trash by
+	// the time the change is undone.
+	OwnerID     uuid.UUID  `json:"owner_id" gorm:"type:uuid;not null"`
+	WorkspaceID *uuid.UUID `json:"workspace_id" gorm:"type:uuid"`
+
+	// Updated is when the todo was last updated as of the change. A todo
+	// updated after that has been changed since and is left alone.
+	Updated time.Time     `json:"updated" gorm:"not null"`
+	State   TodoUndoState `json:"state" gorm:"type:jsonb;serializer:json"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+	Expires time.Time `json:"expires" gorm:"not null;index"`
+}
+
+// TodoUndoState holds what a change overwrote.
+type TodoUndoState struct {
+	// Fields are the previous values of the columns an edit changed.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// Completed are the todos that were still open before a todo was
+	// completed, including the subtasks completed along with it.
+	Completed []uuid.UUID `json:"completed,omitempty"`
+	// Next is the occurrence of a recurring todo created by completing it.
+	Next *uuid.UUID `json:"next,omitempty"`
+}
@@ -10,16 +10,33 @@ var (
 	ErrUserEmailNotFound      = &gqlerror.Error{Message: "email not found"}
 	ErrUserEmailAlreadyExists = &gqlerror.Error{Message: "email already exist"}
 	ErrUserPasswordIsInvalid  = &gqlerror.Error{Message: "invalid password"}
+	ErrUserTimezoneIsInvalid  = &gqlerror.Error{Message: "invalid timezone"}
 )
 
 type User struct {
 	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
 
-	Name     string  `json:"name" gorm:"type:varchar(128);not null"`
-	Email    string  `json:"email" gorm:"type:varchar(255);not null"`
-	Password string  `json:"password" gorm:"type:varchar(64);not null"`
-	Todos    []*Todo `json:"todos" gorm:"foreignKey:UserID"`
+	Name           string  `json:"name" gorm:"type:varchar(128);not null"`
+	Email          string  `json:"email" gorm:"type:varchar(255);not null"`
+	Password       string  `json:"password" gorm:"type:varchar(64);not null"`
+	Timezone       string  `json:"timezone" gorm:"type:varchar(64);not null;default:UTC"`
+	SearchLanguage string  `json:"search_language" gorm:"type:regconfig;not null;default:simple"`
+	Todos          []*Todo `json:"todos" gorm:"foreignKey:UserID"`
 
 	Created time.Time `json:"created" gorm:"autoCreateTime"`
 	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
 }
+
+// Location returns the time zone of the user, falling back to UTC.
+func (u *User) Location() *time.Location {
+	if u == nil || u.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
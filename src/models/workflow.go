@@ -0,0 +1,113 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrWorkflowNotDefined     = &gqlerror.Error{Message: "no workflow is defined for the todo"}
+	ErrWorkflowStatusNotFound = &gqlerror.Error{Message: "status not found in the workflow"}
+	ErrWorkflowTransition     = &gqlerror.Error{Message: "transition to this status isn't allowed"}
+	ErrWorkflowInvalid        = &gqlerror.Error{Message: "workflow needs at least one open and one done status"}
+	ErrWorkflowDuplicate      = &gqlerror.Error{Message: "status names have to be unique in a workflow"}
+	ErrWorkflowUnknownTarget  = &gqlerror.Error{Message: "transition to a status that isn't part of the workflow"}
+)
+
+// StatusCategory groups the statuses of a workflow. A todo is done exactly
+// when its status is in the DONE category.
+type StatusCategory string
+
+const (
+	StatusCategoryTodo       StatusCategory = "TODO"
+	StatusCategoryInProgress StatusCategory = "IN_PROGRESS"
+	StatusCategoryDone       StatusCategory = "DONE"
+)
+
+// WorkflowStatus is a state of the workflow of a workspace, or of the
+// personal todos of a user when WorkspaceID is nil.
+type WorkflowStatus struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	UserID      *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	User        *User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	WorkspaceID *uuid.UUID `json:"workspace_id" gorm:"type:uuid;index"`
+	Workspace   *Workspace `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE"`
+
+	Name     string         `json:"name" gorm:"type:varchar(64);not null"`
+	Category StatusCategory `json:"category" gorm:"type:varchar(16);not null"`
+	Position int            `json:"position" gorm:"not null;default:0"`
+
+	// Transitions are the statuses a todo may move to from this one.
+	Transitions []*WorkflowTransition `json:"transitions" gorm:"foreignKey:FromID"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+}
+
+type WorkflowTransition struct {
+	FromID uuid.UUID       `json:"from_id" gorm:"type:uuid;primarykey"`
+	From   *WorkflowStatus `json:"-" gorm:"foreignKey:FromID;constraint:OnDelete:CASCADE"`
+	ToID   uuid.UUID       `json:"to_id" gorm:"type:uuid;primarykey;index"`
+	To     *WorkflowStatus `json:"to" gorm:"foreignKey:ToID;constraint:OnDelete:CASCADE"`
+}
+
+// Workflow is the ordered list of statuses todos of a tenant move through.
+type Workflow []*WorkflowStatus
+
+// Named returns the status with the given name, or nil.
+func (w Workflow) Named(name string) *WorkflowStatus {
+	for _, status := range w {
+		if status.Name == name {
+			return status
+		}
+	}
+
+	return nil
+}
+
+// Current returns the status of a todo. The stored status only counts as
+// long as it agrees with Done, which can still be changed without going
+// through the workflow. Otherwise the todo is in the first status of the
+// matching category, preferring TODO over IN_PROGRESS for open todos.
+func (w Workflow) Current(todo *Todo) *WorkflowStatus {
+	if todo.StatusID != nil {
+		for _, status := range w {
+			if status.ID == *todo.StatusID && status.Done() == todo.Done {
+				return status
+			}
+		}
+	}
+
+	var open *WorkflowStatus
+	for _, status := range w {
+		if todo.Done && status.Done() {
+			return status
+		}
+		if !todo.Done && status.Category == StatusCategoryTodo {
+			return status
+		}
+		if !todo.Done && !status.Done() && open == nil {
+			open = status
+		}
+	}
+
+	return open
+}
+
+// Done reports whether todos in the status count as done.
+func (s *WorkflowStatus) Done() bool {
+	return s.Category == StatusCategoryDone
+}
+
+// Allows reports whether a todo may move from the status to another one.
+func (s *WorkflowStatus) Allows(to *WorkflowStatus) bool {
+	for _, transition := range s.Transitions {
+		if transition.ToID == to.ID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"time"
+)
+
+var (
+	ErrWorkspaceNotFound           = &gqlerror.Error{Message: "workspace not found"}
+	ErrWorkspaceForbidden          = &gqlerror.Error{Message: "you don't have permission to do this in the workspace"}
+	ErrWorkspaceRoleInvalid        = &gqlerror.Error{Message: "members can only be given the ADMIN or MEMBER role"}
+	ErrWorkspaceAlreadyMember      = &gqlerror.Error{Message: "user is already a member of the workspace"}
+	ErrWorkspaceMemberNotFound     = &gqlerror.Error{Message: "workspace member not found"}
+	ErrWorkspaceOwnerRemove        = &gqlerror.Error{Message: "the owner can't be removed from the workspace"}
+	ErrWorkspaceInvitationNotFound = &gqlerror.Error{Message: "invitation not found"}
+)
+
+// WorkspaceRole is what a member may do in a workspace. Roles are ordered,
+// every role allows everything the roles below it allow.
+type WorkspaceRole string
+
+const (
+	// WorkspaceRoleMember can see and work on every todo of the workspace.
+	WorkspaceRoleMember WorkspaceRole = "MEMBER"
+	// WorkspaceRoleAdmin can also invite and remove members and manage every
+	// todo of the workspace like its owner.
+	WorkspaceRoleAdmin WorkspaceRole = "ADMIN"
+	// WorkspaceRoleOwner can also change the role of members.
+	WorkspaceRoleOwner WorkspaceRole = "OWNER"
+)
+
+var workspaceRoleRank = map[WorkspaceRole]int{
+	WorkspaceRoleMember: 1,
+	WorkspaceRoleAdmin:  2,
+	WorkspaceRoleOwner:  3,
+}
+
+// Allows reports whether the role includes the needed one.
+func (r WorkspaceRole) Allows(need WorkspaceRole) bool {
+	return workspaceRoleRank[r] > 0 && workspaceRoleRank[r] >= workspaceRoleRank[need]
+}
+
+// Workspace is the tenant of a team. The todos of a workspace are only
+// visible to its members.
+type Workspace struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	Name string `json:"name" gorm:"type:varchar(128);not null"`
+	// SearchLanguage is the text search configuration of the todos of the
+	// workspace, whoever created them.
+	SearchLanguage string `json:"search_language" gorm:"type:regconfig;not null;default:simple"`
+	// MyRole is only filled by queries that list the workspaces of a user.
+	MyRole WorkspaceRole `json:"my_role" gorm:"->;-:migration"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+	Updated time.Time `json:"updated" gorm:"autoUpdateTime"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID     `json:"workspace_id" gorm:"type:uuid;primarykey"`
+	Workspace   *Workspace    `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE"`
+	UserID      uuid.UUID     `json:"user_id" gorm:"type:uuid;primarykey;index"`
+	User        *User         `json:"user" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Role        WorkspaceRole `json:"role" gorm:"not null"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+}
+
+// WorkspaceInvitation lets the user with the given email join a workspace.
+// There is at most one open invitation per workspace and email.
+type WorkspaceInvitation struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:uuid_generate_v4()"`
+
+	WorkspaceID uuid.UUID     `json:"workspace_id" gorm:"type:uuid;uniqueIndex:idx_invitations_workspace_email,priority:1"`
+	Workspace   *Workspace    `json:"workspace" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE"`
+	Email       string        `json:"email" gorm:"type:varchar(255);not null;uniqueIndex:idx_invitations_workspace_email,priority:2;index"`
+	Role        WorkspaceRole `json:"role" gorm:"not null"`
+	InvitedByID uuid.UUID     `json:"invited_by_id" gorm:"type:uuid"`
+
+	Created time.Time `json:"created" gorm:"autoCreateTime"`
+}
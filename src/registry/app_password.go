@@ -0,0 +1,21 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewAppPasswordInteractor() usecaseInteractor.AppPasswordInteractor {
+	return usecaseInteractor.NewAppPasswordInteractor(r.NewAppPasswordRepository(), r.NewAppPasswordPresenter())
+}
+
+func (r *registry) NewAppPasswordRepository() usecaseRepository.AppPasswordRepository {
+	return interfaceRepository.NewAppPasswordRepository(r.db)
+}
+
+func (r *registry) NewAppPasswordPresenter() usecasePresenter.AppPasswordPresenter {
+	return interfacePresenter.NewAppPasswordPresenter()
+}
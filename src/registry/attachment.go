@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+	"todo-service/src/infrastructure/storage"
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	"todo-service/src/models"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/spf13/viper"
+)
+
+func (r *registry) NewAttachmentInteractor() usecaseInteractor.AttachmentInteractor {
+	return usecaseInteractor.NewAttachmentInteractor(r.NewAttachmentRepository(), r.NewAttachmentPresenter(),
+		r.NewTodoRepository(), r.NewShareRepository(), r.NewBlobStore(), r.NewAttachmentSettings())
+}
+
+func (r *registry) NewAttachmentSettings() models.AttachmentSettings {
+	settings := models.AttachmentSettings{
+		MaxSize:      viper.GetInt64("attachments.max_size"),
+		AllowedTypes: viper.GetStringSlice("attachments.allowed_types"),
+		URLLifetime:  viper.GetDuration("attachments.url_lifetime"),
+	}
+
+	if settings.MaxSize <= 0 {
+		settings.MaxSize = 10 << 20
+	}
+
+	if settings.URLLifetime <= 0 {
+		settings.URLLifetime = 15 * time.Minute
+	}
+
+	return settings
+}
+
+// NewBlobStore creates the blob store selected by attachments.store, which
+// is either "local" or "s3".
+func (r *registry) NewBlobStore() usecaseRepository.BlobStore {
+	if viper.GetString("attachments.store") == "s3" {
+		store, err := storage.NewS3BlobStore(
+			viper.GetString("attachments.s3.endpoint"),
+			viper.GetString("attachments.s3.access_key"),
+			viper.GetString("attachments.s3.secret_key"),
+			viper.GetString("attachments.s3.region"),
+			viper.GetString("attachments.s3.bucket"),
+			viper.GetBool("attachments.s3.use_ssl"),
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		return store
+	}
+
+	dir := viper.GetString("attachments.local.dir")
+	if dir == "" {
+		dir = "data/attachments"
+	}
+
+	// Without a configured secret download links are signed with a random
+	// one and stop working when the service restarts.
+	secret := viper.GetString("attachments.local.secret")
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			panic(err)
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	return storage.NewLocalBlobStore(dir, viper.GetString("attachments.local.base_url"), secret)
+}
+
+func (r *registry) NewAttachmentRepository() usecaseRepository.AttachmentRepository {
+	return interfaceRepository.NewAttachmentRepository(r.db)
+}
+
+func (r *registry) NewAttachmentPresenter() usecasePresenter.AttachmentPresenter {
+	return interfacePresenter.NewAttachmentPresenter()
+}
@@ -0,0 +1,22 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewCommentInteractor() usecaseInteractor.CommentInteractor {
+	return usecaseInteractor.NewCommentInteractor(r.NewCommentRepository(), r.NewCommentPresenter(),
+		r.NewTodoRepository(), r.NewShareRepository(), r.NewTodoSettings())
+}
+
+func (r *registry) NewCommentRepository() usecaseRepository.CommentRepository {
+	return interfaceRepository.NewCommentRepository(r.db)
+}
+
+func (r *registry) NewCommentPresenter() usecasePresenter.CommentPresenter {
+	return interfacePresenter.NewCommentPresenter()
+}
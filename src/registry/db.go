@@ -0,0 +1,10 @@
+package registry
+
+import (
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewDBRepository() usecaseRepository.DBRepository {
+	return interfaceRepository.NewDBRepository(r.db)
+}
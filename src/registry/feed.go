@@ -0,0 +1,42 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	"todo-service/src/models"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/spf13/viper"
+)
+
+func (r *registry) NewCalendarFeedInteractor() usecaseInteractor.CalendarFeedInteractor {
+	return usecaseInteractor.NewCalendarFeedInteractor(r.NewCalendarFeedRepository(), r.NewCalendarFeedPresenter(),
+		r.NewTodoRepository(), r.NewWorkspaceRepository(), r.NewFeedSettings())
+}
+
+// NewFeedSettings links feeds to feeds.base_url, or to the current domain of
+// the service when it isn't set.
+func (r *registry) NewFeedSettings() models.FeedSettings {
+	settings := models.FeedSettings{
+		BaseURL: viper.GetString("feeds.base_url"),
+	}
+
+	if settings.BaseURL == "" {
+		settings.BaseURL = "https://" + viper.GetString("current_domain")
+		if viper.GetString("env") == "local" {
+			settings.BaseURL = "http://" + viper.GetString("current_domain")
+		}
+	}
+
+	return settings
+}
+
+func (r *registry) NewCalendarFeedRepository() usecaseRepository.CalendarFeedRepository {
+	return interfaceRepository.NewCalendarFeedRepository(r.db)
+}
+
+func (r *registry) NewCalendarFeedPresenter() usecasePresenter.CalendarFeedPresenter {
+	return interfacePresenter.NewCalendarFeedPresenter()
+}
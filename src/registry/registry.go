@@ -12,6 +12,23 @@ type UseCase struct {
 	User           interface{ interactor.UserInteractor }
 	Todo           interface{ interactor.TodoInteractor }
 	Auth           interface{ interactor.AuthInteractor }
+	Attachment     interface {
+		interactor.AttachmentInteractor
+	}
+	Comment   interface{ interactor.CommentInteractor }
+	Share     interface{ interactor.ShareInteractor }
+	Workspace interface{ interactor.WorkspaceInteractor }
+	TimeEntry interface{ interactor.TimeEntryInteractor }
+	Workflow  interface{ interactor.WorkflowInteractor }
+	Template  interface {
+		interactor.TodoTemplateInteractor
+	}
+	CalendarFeed interface {
+		interactor.CalendarFeedInteractor
+	}
+	AppPassword interface {
+		interactor.AppPasswordInteractor
+	}
 }
 
 type registry struct {
@@ -36,5 +53,14 @@ func (r *registry) NewUseCase() UseCase {
 		User:           r.NewUserInteractor(),
 		Todo:           r.NewTodoInteractor(),
 		Auth:           r.NewAuthInteractor(),
+		Attachment:     r.NewAttachmentInteractor(),
+		Comment:        r.NewCommentInteractor(),
+		Share:          r.NewShareInteractor(),
+		Workspace:      r.NewWorkspaceInteractor(),
+		TimeEntry:      r.NewTimeEntryInteractor(),
+		Workflow:       r.NewWorkflowInteractor(),
+		Template:       r.NewTodoTemplateInteractor(),
+		CalendarFeed:   r.NewCalendarFeedInteractor(),
+		AppPassword:    r.NewAppPasswordInteractor(),
 	}
 }
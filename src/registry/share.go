@@ -0,0 +1,21 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewShareInteractor() usecaseInteractor.ShareInteractor {
+	return usecaseInteractor.NewShareInteractor(r.NewShareRepository(), r.NewSharePresenter(), r.NewUserRepository())
+}
+
+func (r *registry) NewShareRepository() usecaseRepository.ShareRepository {
+	return interfaceRepository.NewShareRepository(r.db)
+}
+
+func (r *registry) NewSharePresenter() usecasePresenter.SharePresenter {
+	return interfacePresenter.NewSharePresenter()
+}
@@ -0,0 +1,23 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewTodoTemplateInteractor() usecaseInteractor.TodoTemplateInteractor {
+	return usecaseInteractor.NewTodoTemplateInteractor(r.NewTodoTemplateRepository(), r.NewTodoTemplatePresenter(),
+		r.NewTodoRepository(), r.NewTodoEventRepository(), r.NewShareRepository(), r.NewWorkspaceRepository(),
+		r.NewUserRepository(), r.NewDBRepository())
+}
+
+func (r *registry) NewTodoTemplateRepository() usecaseRepository.TodoTemplateRepository {
+	return interfaceRepository.NewTodoTemplateRepository(r.db)
+}
+
+func (r *registry) NewTodoTemplatePresenter() usecasePresenter.TodoTemplatePresenter {
+	return interfacePresenter.NewTodoTemplatePresenter()
+}
@@ -0,0 +1,22 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewTimeEntryInteractor() usecaseInteractor.TimeEntryInteractor {
+	return usecaseInteractor.NewTimeEntryInteractor(r.NewTimeEntryRepository(), r.NewTimeEntryPresenter(),
+		r.NewTodoRepository(), r.NewShareRepository(), r.NewUserRepository(), r.NewDBRepository())
+}
+
+func (r *registry) NewTimeEntryRepository() usecaseRepository.TimeEntryRepository {
+	return interfaceRepository.NewTimeEntryRepository(r.db)
+}
+
+func (r *registry) NewTimeEntryPresenter() usecasePresenter.TimeEntryPresenter {
+	return interfacePresenter.NewTimeEntryPresenter()
+}
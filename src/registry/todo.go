@@ -1,21 +1,84 @@
 package registry
 
 import (
+	"time"
 	interfacePresenter "todo-service/src/interface/presenter"
 	interfaceRepository "todo-service/src/interface/repository"
+	"todo-service/src/models"
 	usecaseInteractor "todo-service/src/usecase/interactor"
 	usecasePresenter "todo-service/src/usecase/presenter"
 	usecaseRepository "todo-service/src/usecase/repository"
+
+	"github.com/spf13/viper"
 )
 
 func (r *registry) NewTodoInteractor() usecaseInteractor.TodoInteractor {
-	return usecaseInteractor.NewTodoInteractor(r.NewTodoRepository(), r.NewTodoPresenter())
+	return usecaseInteractor.NewTodoInteractor(r.NewTodoRepository(), r.NewTodoPresenter(), r.NewDBRepository(),
+		r.NewShareRepository(), r.NewWorkspaceRepository(), r.NewTodoEventRepository(),
+		r.NewTodoUndoRepository(), r.NewTodoDependencyRepository(), r.NewWorkflowRepository(),
+		r.NewUserRepository(), r.NewTodoSettings())
+}
+
+func (r *registry) NewTodoSettings() models.TodoSettings {
+	settings := models.TodoSettings{
+		CompleteCascade: models.CascadeRule(viper.GetString("todo.complete_cascade")),
+		DeleteCascade:   models.CascadeRule(viper.GetString("todo.delete_cascade")),
+		PageSize:        viper.GetInt("todo.page_size"),
+		MaxPageSize:     viper.GetInt("todo.max_page_size"),
+		TrashRetention:  viper.GetDuration("todo.trash_retention"),
+		UndoWindow:      viper.GetDuration("todo.undo_window"),
+		BlockedComplete: models.BlockedRule(viper.GetString("todo.blocked_complete")),
+	}
+
+	if settings.CompleteCascade != models.CascadeNone {
+		settings.CompleteCascade = models.CascadeChildren
+	}
+
+	if settings.BlockedComplete != models.BlockedWarn {
+		settings.BlockedComplete = models.BlockedRefuse
+	}
+
+	switch settings.DeleteCascade {
+	case models.CascadeReparent, models.CascadeRestrict:
+	default:
+		settings.DeleteCascade = models.CascadeChildren
+	}
+
+	if settings.MaxPageSize <= 0 {
+		settings.MaxPageSize = 100
+	}
+
+	if settings.PageSize <= 0 || settings.PageSize > settings.MaxPageSize {
+		settings.PageSize = settings.MaxPageSize
+	}
+
+	if settings.TrashRetention <= 0 {
+		settings.TrashRetention = 30 * 24 * time.Hour
+	}
+
+	if settings.UndoWindow <= 0 {
+		settings.UndoWindow = 30 * time.Second
+	}
+
+	return settings
 }
 
 func (r *registry) NewTodoRepository() usecaseRepository.TodoRepository {
 	return interfaceRepository.NewTodoRepository(r.db)
 }
 
+func (r *registry) NewTodoEventRepository() usecaseRepository.TodoEventRepository {
+	return interfaceRepository.NewTodoEventRepository(r.db)
+}
+
+func (r *registry) NewTodoUndoRepository() usecaseRepository.TodoUndoRepository {
+	return interfaceRepository.NewTodoUndoRepository(r.db)
+}
+
+func (r *registry) NewTodoDependencyRepository() usecaseRepository.TodoDependencyRepository {
+	return interfaceRepository.NewTodoDependencyRepository(r.db)
+}
+
 func (r *registry) NewTodoPresenter() usecasePresenter.TodoPresenter {
 	return interfacePresenter.NewTodoPresenter()
 }
@@ -9,7 +9,7 @@ import (
 )
 
 func (r *registry) NewUserInteractor() usecaseInteractor.UserInteractor {
-	return usecaseInteractor.NewUserInteractor(r.NewUserRepository(), r.NewUserPresenter())
+	return usecaseInteractor.NewUserInteractor(r.NewUserRepository(), r.NewUserPresenter(), r.NewTodoRepository(), r.NewDBRepository())
 }
 
 func (r *registry) NewUserRepository() usecaseRepository.UserRepository {
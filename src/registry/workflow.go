@@ -0,0 +1,22 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewWorkflowInteractor() usecaseInteractor.WorkflowInteractor {
+	return usecaseInteractor.NewWorkflowInteractor(r.NewWorkflowRepository(), r.NewWorkflowPresenter(),
+		r.NewWorkspaceRepository(), r.NewDBRepository())
+}
+
+func (r *registry) NewWorkflowRepository() usecaseRepository.WorkflowRepository {
+	return interfaceRepository.NewWorkflowRepository(r.db)
+}
+
+func (r *registry) NewWorkflowPresenter() usecasePresenter.WorkflowPresenter {
+	return interfacePresenter.NewWorkflowPresenter()
+}
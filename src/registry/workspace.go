@@ -0,0 +1,22 @@
+package registry
+
+import (
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	usecaseInteractor "todo-service/src/usecase/interactor"
+	usecasePresenter "todo-service/src/usecase/presenter"
+	usecaseRepository "todo-service/src/usecase/repository"
+)
+
+func (r *registry) NewWorkspaceInteractor() usecaseInteractor.WorkspaceInteractor {
+	return usecaseInteractor.NewWorkspaceInteractor(r.NewWorkspaceRepository(), r.NewWorkspacePresenter(),
+		r.NewUserRepository(), r.NewDBRepository(), r.NewTodoRepository())
+}
+
+func (r *registry) NewWorkspaceRepository() usecaseRepository.WorkspaceRepository {
+	return interfaceRepository.NewWorkspaceRepository(r.db)
+}
+
+func (r *registry) NewWorkspacePresenter() usecasePresenter.WorkspacePresenter {
+	return interfacePresenter.NewWorkspacePresenter()
+}
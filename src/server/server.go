@@ -7,7 +7,9 @@ import (
 	"os/signal"
 	"time"
 	"todo-service/src/infrastructure/authentication"
+	"todo-service/src/infrastructure/delivery/caldav"
 	"todo-service/src/infrastructure/delivery/graphql"
+	"todo-service/src/infrastructure/jobs"
 	"todo-service/src/infrastructure/monitoring/logs"
 	"todo-service/src/infrastructure/storage"
 	"todo-service/src/registry"
@@ -19,6 +21,7 @@ import (
 type App struct {
 	httpServer *http.Server
 	e          *echo.Echo
+	jobs       []func(ctx context.Context)
 }
 
 func NewApp() *App {
@@ -37,7 +40,17 @@ func NewApp() *App {
 	// Initialize Echo instance
 	e := echo.New()
 
-	graphql.NewGraphqlRouter(e, useCase)
+	graphql.NewGraphqlRouter(e, useCase, logger)
+	caldav.NewCalDAVRouter(e, useCase)
+
+	// Background jobs
+	purgeInterval := viper.GetDuration("todo.trash_purge_interval")
+	if purgeInterval <= 0 {
+		purgeInterval = time.Hour
+	}
+	purgeTrash := func(ctx context.Context) {
+		jobs.PurgeTrash(ctx, logger, useCase.Todo, useCase.Attachment, purgeInterval)
+	}
 
 	// Start server
 	s := &http.Server{
@@ -50,10 +63,18 @@ func NewApp() *App {
 	return &App{
 		httpServer: s,
 		e:          e,
+		jobs:       []func(ctx context.Context){purgeTrash},
 	}
 }
 
 func (a *App) Run() error {
+	// Start background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	for _, job := range a.jobs {
+		go job(jobsCtx)
+	}
+
 	// Start server
 	go func() {
 		if err := a.e.StartServer(a.httpServer); err != nil {
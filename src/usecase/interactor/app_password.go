@@ -0,0 +1,97 @@
+package interactor
+
+import (
+	"strings"
+	"time"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type appPasswordInteractor struct {
+	AppPasswordRepository repository.AppPasswordRepository
+	AppPasswordPresenter  presenter.AppPasswordPresenter
+}
+
+type AppPasswordInteractor interface {
+	Create(name string, userId string) (*models.AppPassword, error)
+	List(userId string) ([]*models.AppPassword, error)
+	Delete(id string, userId string) (bool, error)
+	Authenticate(email string, password string) (*models.User, error)
+}
+
+func NewAppPasswordInteractor(r repository.AppPasswordRepository, p presenter.AppPasswordPresenter) AppPasswordInteractor {
+	return &appPasswordInteractor{r, p}
+}
+
+// Create gives the user a new app password. The password is only returned
+// this once.
+func (pi *appPasswordInteractor) Create(name string, userId string) (*models.AppPassword, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 64 {
+		return nil, models.ErrAppPasswordName
+	}
+
+	secret, err := models.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	password := &models.AppPassword{
+		ID:           uuid.New(),
+		UserID:       uuid.MustParse(userId),
+		Name:         name,
+		PasswordHash: models.HashSecret(secret),
+	}
+	if err := pi.AppPasswordRepository.Create(password); err != nil {
+		return nil, err
+	}
+
+	password.Password = secret
+	return password, nil
+}
+
+func (pi *appPasswordInteractor) List(userId string) ([]*models.AppPassword, error) {
+	return pi.AppPasswordRepository.List(userId)
+}
+
+func (pi *appPasswordInteractor) Delete(id string, userId string) (bool, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return false, nil
+	}
+
+	return pi.AppPasswordRepository.Delete(id, userId)
+}
+
+// Authenticate returns the user an app password belongs to, as long as the
+// email is the email of that user, and records that the password was used.
+func (pi *appPasswordInteractor) Authenticate(email string, password string) (*models.User, error) {
+	if password == "" {
+		return nil, models.ErrAppPasswordInvalid
+	}
+
+	found, err := pi.AppPasswordRepository.GetByHash(models.HashSecret(password))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrAppPasswordInvalid
+		}
+		return nil, err
+	}
+
+	if found.User == nil || !strings.EqualFold(found.User.Email, strings.TrimSpace(email)) {
+		return nil, models.ErrAppPasswordInvalid
+	}
+
+	now := time.Now()
+	if found.LastUsed == nil || now.Sub(*found.LastUsed) >= models.AppPasswordTouchInterval {
+		if err := pi.AppPasswordRepository.Touch(found.ID.String(), now); err != nil {
+			return nil, err
+		}
+	}
+
+	return found.User, nil
+}
@@ -0,0 +1,253 @@
+package interactor
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type attachmentInteractor struct {
+	AttachmentRepository repository.AttachmentRepository
+	AttachmentPresenter  presenter.AttachmentPresenter
+	TodoRepository       repository.TodoRepository
+	ShareRepository      repository.ShareRepository
+	BlobStore            repository.BlobStore
+	settings             models.AttachmentSettings
+}
+
+type AttachmentInteractor interface {
+	Upload(todoId string, file graphql.Upload, userId string) (*models.Attachment, error)
+	List(todoId string, userId string) ([]*models.Attachment, error)
+	Delete(id string, userId string) (bool, error)
+	URL(attachment *models.Attachment) (string, error)
+	Open(key string, expires string, signature string) (*models.Attachment, io.ReadCloser, error)
+	Cleanup() (int, error)
+}
+
+func NewAttachmentInteractor(
+	r repository.AttachmentRepository, p presenter.AttachmentPresenter, tr repository.TodoRepository,
+	sr repository.ShareRepository, bs repository.BlobStore, s models.AttachmentSettings) AttachmentInteractor {
+	return &attachmentInteractor{r, p, tr, sr, bs, s}
+}
+
+// Upload checks the file against the configured limits, stores its content
+// in the blob store and records it on the todo.
+func (ai *attachmentInteractor) Upload(todoId string, file graphql.Upload, userId string) (*models.Attachment, error) {
+	todo, _, err := ai.todo(todoId, userId, models.TodoRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Size > ai.settings.MaxSize {
+		return nil, models.ErrAttachmentTooLarge
+	}
+
+	contentType, err := sniffContentType(file.File)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ai.allowed(contentType) {
+		return nil, models.ErrAttachmentType
+	}
+
+	id := uuid.New()
+	attachment := &models.Attachment{
+		ID:          id,
+		TodoID:      todo.ID,
+		UserID:      todo.UserID,
+		UploaderID:  uuid.MustParse(userId),
+		Filename:    file.Filename,
+		ContentType: contentType,
+		Size:        file.Size,
+		Key:         userId + "/" + todo.ID.String() + "/" + id.String(),
+	}
+
+	if err := ai.BlobStore.Put(attachment.Key, file.File, file.Size, contentType); err != nil {
+		return nil, err
+	}
+
+	if err := ai.AttachmentRepository.Create(attachment); err != nil {
+		_ = ai.BlobStore.Delete(attachment.Key)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// List returns the files attached to a todo by anyone who can work on it.
+func (ai *attachmentInteractor) List(todoId string, userId string) ([]*models.Attachment, error) {
+	todo, _, err := ai.todo(todoId, userId, models.TodoRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return ai.AttachmentRepository.ListByTodo(todo.ID.String())
+}
+
+// Delete removes an attachment for an editor of its todo, or for the user
+// who uploaded it as long as they can still see the todo.
+func (ai *attachmentInteractor) Delete(id string, userId string) (bool, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return false, nil
+	}
+
+	attachment, err := ai.AttachmentRepository.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, access, err := ai.todo(attachment.TodoID.String(), userId, models.TodoRoleViewer)
+	if err != nil {
+		if err == models.ErrTodoNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !access.Role.Allows(models.TodoRoleEditor) && attachment.UploaderID.String() != userId {
+		return false, models.ErrTodoForbidden
+	}
+
+	if err := ai.BlobStore.Delete(attachment.Key); err != nil {
+		return false, err
+	}
+
+	if err := ai.AttachmentRepository.Delete(id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (ai *attachmentInteractor) URL(attachment *models.Attachment) (string, error) {
+	return ai.BlobStore.SignedURL(attachment.Key, attachment.Filename, ai.settings.URLLifetime)
+}
+
+// Open checks a signed link issued by URL and opens the attachment behind it.
+func (ai *attachmentInteractor) Open(key string, expires string, signature string) (*models.Attachment, io.ReadCloser, error) {
+	if !ai.BlobStore.Verify(key, expires, signature) {
+		return nil, nil, models.ErrAttachmentNotFound
+	}
+
+	attachment, err := ai.AttachmentRepository.GetByKey(key)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, models.ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+
+	content, err := ai.BlobStore.Get(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// Cleanup removes the attachments of todos that have been permanently
+// deleted, together with their blobs.
+func (ai *attachmentInteractor) Cleanup() (int, error) {
+	var removed int
+	for {
+		orphans, err := ai.AttachmentRepository.ListOrphaned(100)
+		if err != nil {
+			return removed, err
+		}
+
+		if len(orphans) == 0 {
+			return removed, nil
+		}
+
+		for _, attachment := range orphans {
+			if err := ai.BlobStore.Delete(attachment.Key); err != nil {
+				return removed, err
+			}
+
+			if err := ai.AttachmentRepository.Delete(attachment.ID.String()); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+}
+
+// todo loads the todo of an attachment, as long as the user has the needed
+// role on it, together with the access the role was decided from.
+func (ai *attachmentInteractor) todo(todoId string, userId string, need models.TodoRole) (*models.Todo, *models.TodoAccess, error) {
+	if _, err := uuid.Parse(todoId); err != nil {
+		return nil, nil, models.ErrTodoNotFound
+	}
+
+	access, err := authorizeTodo(ai.ShareRepository, todoId, userId, need)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, models.ErrTodoNotFound
+		}
+		return nil, nil, err
+	}
+
+	todo, err := ai.TodoRepository.InWorkspace(access.WorkspaceID).GetByID(todoId, access.OwnerID.String())
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, models.ErrTodoNotFound
+		}
+		return nil, nil, err
+	}
+
+	return todo, access, nil
+}
+
+// allowed reports whether the content type matches the configured list,
+// where an entry like "image/*" matches a whole family. An empty list
+// allows every type.
+func (ai *attachmentInteractor) allowed(contentType string) bool {
+	if len(ai.settings.AllowedTypes) == 0 {
+		return true
+	}
+
+	for _, allowed := range ai.settings.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sniffContentType detects the type of the file from its content instead of
+// trusting the type sent by the client, and rewinds the file afterwards.
+func sniffContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", err
+	}
+
+	return contentType, nil
+}
@@ -48,6 +48,11 @@ func (ai *authInteractor) SignUp(ctx context.Context, input model.NewUser) (*mod
 		Name:     input.Name,
 		Email:    strings.ToLower(input.Email),
 		Password: input.Password,
+		Timezone: "UTC",
+	}
+
+	if input.Timezone != nil {
+		user.Timezone = *input.Timezone
 	}
 
 	_, err = ai.UserRepository.Create(user)
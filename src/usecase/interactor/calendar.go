@@ -0,0 +1,324 @@
+package interactor
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/repository"
+	"todo-service/utils"
+	"todo-service/utils/ical"
+	"todo-service/utils/recurrence"
+
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gorm.io/gorm"
+)
+
+// calendarPriorities are the PRIORITY values todos are exported with. On
+// import 1 to 4 are high, 5 is medium and 6 to 9 are low, as in RFC 5545.
+var calendarPriorities = map[models.TodoPriority]int{
+	models.TodoPriorityHigh:   1,
+	models.TodoPriorityMedium: 5,
+	models.TodoPriorityLow:    9,
+}
+
+// exportCalendar writes the todos of the list as VTODO components.
+func (ti *todoInteractor) exportCalendar(w io.Writer, userId string) error {
+	return writeCalendar(w, ti.TodoRepository, nil, userId)
+}
+
+// writeCalendar writes the todos of the repository that match the filter as
+// VTODO components.
+func writeCalendar(w io.Writer, todos repository.TodoRepository, filter *model.TodoFilter, userId string) error {
+	encoder := ical.NewEncoder(w, "Todos")
+	err := todos.Each(filter, userId, func(todo *models.Todo) error {
+		return encoder.Encode(calendarTodo(todo))
+	})
+	if err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// calendarTodo turns a todo into a VTODO.
+func calendarTodo(todo *models.Todo) *ical.Todo {
+	item := &ical.Todo{
+		UID:          todo.CalendarUID(),
+		Summary:      todo.Text,
+		Status:       ical.StatusNeedsAction,
+		Due:          todo.DueAt,
+		RRule:        todo.RRule,
+		Priority:     calendarPriorities[todo.Priority],
+		Categories:   todo.Tags,
+		RelatedTo:    todo.ParentUID,
+		Created:      &todo.Created,
+		LastModified: &todo.Updated,
+		Stamp:        todo.Updated,
+	}
+
+	if todo.Done {
+		item.Status = ical.StatusCompleted
+		item.Completed = todo.CompletedAt
+	}
+
+	return item
+}
+
+// decodeCalendarRow reads a VTODO into the input of a new todo and notes
+// every value that doesn't fit, like decodeImportRow.
+func decodeCalendarRow(n int, item *ical.Todo) *importRow {
+	row := &importRow{
+		row:      n,
+		id:       item.UID,
+		parentId: item.RelatedTo,
+		uid:      item.UID,
+		errors:   make([]string, 0),
+	}
+
+	for _, property := range item.Invalid {
+		row.fail("Parameters incorrectly formatted or out of range (" + property + ")")
+	}
+
+	checked := importedTodo{
+		Text: strings.TrimSpace(item.Summary),
+		Tags: models.NormalizeTags(item.Categories),
+	}
+	if err := utils.Validate(checked); err != nil {
+		row.fail(err.Error())
+	}
+	row.input.Text = checked.Text
+	row.input.Tags = checked.Tags
+	row.input.DueAt = item.Due
+
+	if item.Priority > 0 {
+		priority := models.TodoPriorityLow
+		switch {
+		case item.Priority < 5:
+			priority = models.TodoPriorityHigh
+		case item.Priority == 5:
+			priority = models.TodoPriorityMedium
+		}
+		row.input.Priority = &priority
+	}
+
+	row.done = item.Status == ical.StatusCompleted || item.Completed != nil
+	if row.done {
+		row.completedAt = item.Completed
+	}
+
+	if item.RRule != "" {
+		normalized, err := recurrence.Normalize(item.RRule)
+		if err != nil {
+			row.fail(models.ErrTodoInvalidRRule.Message)
+		} else if row.input.DueAt == nil {
+			row.fail(models.ErrTodoRRuleNeedsDue.Message)
+		} else {
+			row.input.Rrule = &normalized
+		}
+	}
+
+	return row
+}
+
+// calendarObject turns a todo into a calendar of its own VTODO. The ETag
+// changes with every change to the todo.
+func calendarObject(todo *models.Todo) (*models.CalendarObject, error) {
+	var b strings.Builder
+	encoder := ical.NewEncoder(&b, "")
+	if err := encoder.Encode(calendarTodo(todo)); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return &models.CalendarObject{
+		UID:  todo.CalendarUID(),
+		ETag: `"` + strconv.FormatInt(todo.Updated.UnixMicro(), 16) + `"`,
+		Data: b.String(),
+		Todo: todo,
+	}, nil
+}
+
+// CalendarObject returns the todo of the list with a calendar UID as a
+// calendar object.
+func (ti *todoInteractor) CalendarObject(uid string, userId string) (*models.CalendarObject, error) {
+	todo, err := ti.TodoRepository.GetByUID(uid, userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrCalendarObjectNotFound
+		}
+		return nil, err
+	}
+
+	return calendarObject(todo)
+}
+
+// CalendarObjects calls fn with every todo of the list as a calendar object.
+func (ti *todoInteractor) CalendarObjects(userId string, fn func(object *models.CalendarObject) error) error {
+	return ti.TodoRepository.Each(nil, userId, func(todo *models.Todo) error {
+		object, err := calendarObject(todo)
+		if err != nil {
+			return err
+		}
+		return fn(object)
+	})
+}
+
+// CalendarSyncToken returns the sync token of the current state of the list.
+func (ti *todoInteractor) CalendarSyncToken(userId string) (string, error) {
+	version, err := ti.TodoRepository.Version(nil, userId)
+	if err != nil {
+		return "", err
+	}
+
+	return calendarSyncToken(version), nil
+}
+
+// calendarSyncToken is the time of the last change to a list, which is never
+// before the trash was last emptied.
+func calendarSyncToken(version *models.TodoVersion) string {
+	at := version.Updated
+	if version.Emptied.After(at) {
+		at = version.Emptied
+	}
+
+	return models.CalendarSyncTokenPrefix + strconv.FormatInt(at.UnixMicro(), 10)
+}
+
+// CalendarChanges calls fn with every calendar object of the list that was
+// changed or deleted since the state of a sync token, or with every object
+// for an empty token, and returns the token of the current state. A token
+// older than the trash retention or the last time the trash was emptied is
+// refused, as todos deleted for good since then can't be reported anymore.
+func (ti *todoInteractor) CalendarChanges(syncToken string, userId string, fn func(object *models.CalendarObject) error) (string, error) {
+	version, err := ti.TodoRepository.Version(nil, userId)
+	if err != nil {
+		return "", err
+	}
+	next := calendarSyncToken(version)
+
+	if syncToken == "" {
+		return next, ti.CalendarObjects(userId, fn)
+	}
+
+	micros, err := strconv.ParseInt(strings.TrimPrefix(syncToken, models.CalendarSyncTokenPrefix), 10, 64)
+	if err != nil || !strings.HasPrefix(syncToken, models.CalendarSyncTokenPrefix) {
+		return "", models.ErrCalendarSyncToken
+	}
+
+	since := time.UnixMicro(micros)
+	if since.After(time.Now()) || (micros != (time.Time{}).UnixMicro() && since.Before(time.Now().Add(-ti.settings.TrashRetention))) {
+		return "", models.ErrCalendarSyncToken
+	}
+	if since.Before(version.Emptied) {
+		return "", models.ErrCalendarSyncToken
+	}
+
+	err = ti.TodoRepository.EachChanged(since, userId, func(todo *models.Todo) error {
+		if todo.Deleted.Valid {
+			return fn(&models.CalendarObject{UID: todo.CalendarUID(), Todo: todo, Deleted: true})
+		}
+
+		object, err := calendarObject(todo)
+		if err != nil {
+			return err
+		}
+		return fn(object)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return next, nil
+}
+
+// PutCalendarObject saves a calendar object to the list under its UID: it
+// updates the todo with the UID, or creates one that goes below the todo of
+// its RELATED-TO, as far as that one is already part of the list. Further
+// VTODOs of the same UID override single occurrences of a recurring todo and
+// are left out. It tells whether the todo was created.
+func (ti *todoInteractor) PutCalendarObject(uid string, data io.Reader, userId string) (*models.CalendarObject, bool, error) {
+	user, err := ti.UserRepository.GetByID(userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	decoder := ical.NewDecoder(data, user.Location())
+	item, err := decoder.Next()
+	if err == io.EOF {
+		return nil, false, models.ErrCalendarObjectUID
+	}
+	if err != nil {
+		return nil, false, models.ErrImportFile
+	}
+	for {
+		override, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, models.ErrImportFile
+		}
+		if override.UID != item.UID {
+			return nil, false, models.ErrCalendarObjectUID
+		}
+	}
+
+	if item.UID == "" {
+		item.UID = uid
+	}
+	if item.UID != uid {
+		return nil, false, models.ErrCalendarObjectUID
+	}
+
+	row := decodeCalendarRow(1, item)
+	if len(row.errors) > 0 {
+		return nil, false, &gqlerror.Error{Message: strings.Join(row.errors, ", ")}
+	}
+
+	actor := uuid.MustParse(userId)
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		if row.parentId != "" {
+			parent, err := ti.TodoRepository.WithTx(tx).GetByUID(row.parentId, userId)
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return err
+			}
+			if err == nil && parent.CalendarUID() != uid {
+				parentId := parent.ID.String()
+				row.input.ParentID = &parentId
+			}
+		}
+
+		_, err := ti.saveImported(tx, row, actor, userId)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	object, err := ti.CalendarObject(uid, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return object, !row.updated, nil
+}
+
+// DeleteCalendarObject deletes the todo with a calendar UID like Delete.
+func (ti *todoInteractor) DeleteCalendarObject(uid string, userId string) (bool, error) {
+	todo, err := ti.TodoRepository.GetByUID(uid, userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	deleted, _, err := ti.Delete(todo.ID.String(), userId)
+	return deleted, err
+}
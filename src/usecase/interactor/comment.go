@@ -0,0 +1,145 @@
+package interactor
+
+import (
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type commentInteractor struct {
+	CommentRepository repository.CommentRepository
+	CommentPresenter  presenter.CommentPresenter
+	TodoRepository    repository.TodoRepository
+	ShareRepository   repository.ShareRepository
+	settings          models.TodoSettings
+}
+
+type CommentInteractor interface {
+	Create(todoId string, input model.NewComment, userId string) (*models.Comment, error)
+	Update(id string, input model.UpdateComment, userId string) (*models.Comment, error)
+	Delete(id string, userId string) (bool, error)
+	Paginate(todoId string, page models.PageArgs, userId string) (*models.CommentConnection, error)
+}
+
+func NewCommentInteractor(
+	r repository.CommentRepository, p presenter.CommentPresenter, tr repository.TodoRepository,
+	sr repository.ShareRepository, s models.TodoSettings) CommentInteractor {
+	return &commentInteractor{r, p, tr, sr, s}
+}
+
+func (ci *commentInteractor) Create(todoId string, input model.NewComment, userId string) (*models.Comment, error) {
+	todo, err := ci.todo(todoId, userId, models.TodoRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		ID:     uuid.New(),
+		TodoID: todo.ID,
+		UserID: uuid.MustParse(userId),
+		Body:   input.Body,
+	}
+
+	if err := ci.CommentRepository.Create(comment); err != nil {
+		return nil, err
+	}
+
+	return ci.CommentRepository.GetByID(comment.ID.String())
+}
+
+func (ci *commentInteractor) Update(id string, input model.UpdateComment, userId string) (*models.Comment, error) {
+	comment, err := ci.authored(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ci.CommentRepository.Update(comment.ID.String(), input.Body); err != nil {
+		return nil, err
+	}
+
+	return ci.CommentRepository.GetByID(comment.ID.String())
+}
+
+func (ci *commentInteractor) Delete(id string, userId string) (bool, error) {
+	comment, err := ci.authored(id, userId)
+	if err != nil {
+		if err == models.ErrCommentNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return ci.CommentRepository.Delete(comment.ID.String())
+}
+
+func (ci *commentInteractor) Paginate(todoId string, page models.PageArgs, userId string) (*models.CommentConnection, error) {
+	if _, err := ci.todo(todoId, userId, models.TodoRoleViewer); err != nil {
+		return nil, err
+	}
+
+	if err := normalizePage(&page, ci.settings); err != nil {
+		return nil, err
+	}
+
+	return ci.CommentRepository.Paginate(todoId, page)
+}
+
+// todo loads the todo a comment belongs to, as long as the user has the
+// needed role on it. Viewers can read the comments, editors can add to them.
+func (ci *commentInteractor) todo(todoId string, userId string, need models.TodoRole) (*models.Todo, error) {
+	if _, err := uuid.Parse(todoId); err != nil {
+		return nil, models.ErrTodoNotFound
+	}
+
+	access, err := authorizeTodo(ci.ShareRepository, todoId, userId, need)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+
+	todo, err := ci.TodoRepository.InWorkspace(access.WorkspaceID).GetByID(todoId, access.OwnerID.String())
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// authored loads a comment the user is allowed to change. Comments on todos
+// the user can't see are reported as missing, and only the author may change
+// the ones they can.
+func (ci *commentInteractor) authored(id string, userId string) (*models.Comment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrCommentNotFound
+	}
+
+	comment, err := ci.CommentRepository.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := ci.todo(comment.TodoID.String(), userId, models.TodoRoleViewer); err != nil {
+		if err == models.ErrTodoNotFound {
+			return nil, models.ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	if comment.UserID.String() != userId {
+		return nil, models.ErrCommentNotAuthor
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,129 @@
+package interactor
+
+import (
+	"io"
+	"strings"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type calendarFeedInteractor struct {
+	FeedRepository      repository.CalendarFeedRepository
+	FeedPresenter       presenter.CalendarFeedPresenter
+	TodoRepository      repository.TodoRepository
+	WorkspaceRepository repository.WorkspaceRepository
+	settings            models.FeedSettings
+}
+
+type CalendarFeedInteractor interface {
+	Get(userId string) (*models.CalendarFeed, error)
+	Regenerate(userId string) (*models.CalendarFeed, error)
+	Revoke(userId string) (bool, error)
+	Open(token string, workspaceId string, tag string) (*models.TodoVersion, func(w io.Writer) error, error)
+}
+
+func NewCalendarFeedInteractor(
+	r repository.CalendarFeedRepository, p presenter.CalendarFeedPresenter, tr repository.TodoRepository,
+	wr repository.WorkspaceRepository, s models.FeedSettings) CalendarFeedInteractor {
+	return &calendarFeedInteractor{r, p, tr, wr, s}
+}
+
+// Get returns the feed of the user, without its link, or nil when the user
+// has none.
+func (fi *calendarFeedInteractor) Get(userId string) (*models.CalendarFeed, error) {
+	feed, err := fi.FeedRepository.GetByUser(userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// Regenerate gives the user a feed with a new link. The link is only
+// returned this once, and the previous link of the user stops working.
+func (fi *calendarFeedInteractor) Regenerate(userId string) (*models.CalendarFeed, error) {
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, models.ErrCalendarFeedNotFound
+	}
+
+	token, err := models.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &models.CalendarFeed{
+		UserID:    id,
+		TokenHash: models.HashSecret(token),
+	}
+	if err := fi.FeedRepository.Upsert(feed); err != nil {
+		return nil, err
+	}
+
+	feed.URL = strings.TrimSuffix(fi.settings.BaseURL, "/") + models.CalendarFeedPath + token + models.CalendarFeedExtension
+	return feed, nil
+}
+
+// Revoke deletes the feed of the user so that its link stops working.
+func (fi *calendarFeedInteractor) Revoke(userId string) (bool, error) {
+	return fi.FeedRepository.Delete(userId)
+}
+
+// Open checks the token of a feed link and returns the version of the open
+// todos it shows, together with a function that writes them as a calendar.
+// The feed shows the personal todos of its user, or the todos of a workspace
+// the user is still a member of, optionally narrowed down to one tag.
+func (fi *calendarFeedInteractor) Open(token string, workspaceId string, tag string) (*models.TodoVersion, func(w io.Writer) error, error) {
+	if token == "" {
+		return nil, nil, models.ErrCalendarFeedNotFound
+	}
+
+	feed, err := fi.FeedRepository.GetByTokenHash(models.HashSecret(token))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, models.ErrCalendarFeedNotFound
+		}
+		return nil, nil, err
+	}
+	userId := feed.UserID.String()
+
+	todos := fi.TodoRepository
+	if workspaceId != "" {
+		id, err := uuid.Parse(workspaceId)
+		if err != nil {
+			return nil, nil, models.ErrWorkspaceNotFound
+		}
+
+		if _, err := fi.WorkspaceRepository.GetMember(id.String(), userId); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, nil, models.ErrWorkspaceNotFound
+			}
+			return nil, nil, err
+		}
+
+		todos = todos.InWorkspace(&id)
+	}
+
+	done := false
+	filter := &model.TodoFilter{Done: &done}
+	if tag != "" {
+		filter.Tag = &tag
+	}
+
+	version, err := todos.Version(filter, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return version, func(w io.Writer) error {
+		return writeCalendar(w, todos, filter, userId)
+	}, nil
+}
@@ -23,11 +23,21 @@ type Middleware interface {
 	Auth(w http.ResponseWriter, req *http.Request) (*http.Request, error)
 }
 
+// WorkspaceHeader selects the active workspace of a request.
+const WorkspaceHeader = "X-Workspace-ID"
+
 func CtxValue(ctx context.Context) *models.JwtCustomClaim {
 	raw, _ := ctx.Value(authString("auth")).(*models.JwtCustomClaim)
 	return raw
 }
 
+// WorkspaceCtxValue returns the workspace selected for the request, or an
+// empty string for the personal todos of the user.
+func WorkspaceCtxValue(ctx context.Context) string {
+	raw, _ := ctx.Value(authString("workspace")).(string)
+	return raw
+}
+
 func (am *authMiddleware) Auth(w http.ResponseWriter, req *http.Request) (*http.Request, error) {
 
 	//Parse and check token
@@ -53,7 +63,13 @@ func (am *authMiddleware) Auth(w http.ResponseWriter, req *http.Request) (*http.
 
 	customClaim, _ := validate.Claims.(*models.JwtCustomClaim)
 
+	workspace := req.Header.Get(WorkspaceHeader)
+	if workspace == "" {
+		workspace = customClaim.WorkspaceID
+	}
+
 	ctx := context.WithValue(req.Context(), authString("auth"), customClaim)
+	ctx = context.WithValue(ctx, authString("workspace"), workspace)
 	req = req.WithContext(ctx)
 
 	return req, nil
@@ -0,0 +1,179 @@
+package interactor
+
+import (
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type shareInteractor struct {
+	ShareRepository repository.ShareRepository
+	SharePresenter  presenter.SharePresenter
+	UserRepository  repository.UserRepository
+}
+
+type ShareInteractor interface {
+	Share(todoIds []string, email string, role models.TodoRole, userId string) ([]*models.Share, error)
+	Unshare(todoId string, recipientId string, userId string) (bool, error)
+	List(todoId string, userId string) ([]*models.Share, error)
+}
+
+func NewShareInteractor(
+	r repository.ShareRepository, p presenter.SharePresenter, ur repository.UserRepository) ShareInteractor {
+	return &shareInteractor{r, p, ur}
+}
+
+// Share gives the user with the given email access to the todos, and to all
+// of their subtasks, at once. Only the owner of a todo can share it.
+func (si *shareInteractor) Share(todoIds []string, email string, role models.TodoRole, userId string) ([]*models.Share, error) {
+	if role != models.TodoRoleViewer && role != models.TodoRoleEditor {
+		return nil, models.ErrShareRoleInvalid
+	}
+
+	recipient, err := si.UserRepository.GetByEmail(email)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrShareUserNotFound
+		}
+		return nil, err
+	}
+
+	if recipient.ID.String() == userId {
+		return nil, models.ErrShareWithSelf
+	}
+
+	shares := make([]*models.Share, 0, len(todoIds))
+	ids := make([]string, 0, len(todoIds))
+	for _, id := range todoIds {
+		if _, err := authorizeTodo(si.ShareRepository, id, userId, models.TodoRoleOwner); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, models.ErrTodoNotFound
+			}
+			return nil, err
+		}
+
+		shares = append(shares, &models.Share{
+			ID:     uuid.New(),
+			TodoID: uuid.MustParse(id),
+			UserID: recipient.ID,
+			Role:   role,
+		})
+		ids = append(ids, id)
+	}
+
+	if len(shares) == 0 {
+		return []*models.Share{}, nil
+	}
+
+	if err := si.ShareRepository.Upsert(shares); err != nil {
+		return nil, err
+	}
+
+	return si.ShareRepository.ListByRecipient(ids, recipient.ID.String())
+}
+
+// Unshare takes the access to a todo away from a user. The owner can remove
+// anyone, everyone else can only remove themselves.
+func (si *shareInteractor) Unshare(todoId string, recipientId string, userId string) (bool, error) {
+	access, err := authorizeTodo(si.ShareRepository, todoId, userId, models.TodoRoleViewer)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if access.Role != models.TodoRoleOwner && recipientId != userId {
+		return false, models.ErrTodoForbidden
+	}
+
+	if _, err := uuid.Parse(recipientId); err != nil {
+		return false, nil
+	}
+
+	return si.ShareRepository.Delete(todoId, recipientId)
+}
+
+func (si *shareInteractor) List(todoId string, userId string) ([]*models.Share, error) {
+	if _, err := authorizeTodo(si.ShareRepository, todoId, userId, models.TodoRoleViewer); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+
+	return si.ShareRepository.ListByTodo(todoId)
+}
+
+// authorizeTodo is the one place that decides what a user may do with a todo.
+// Repositories stay scoped to a tenant, so the caller acts on the todo in the
+// workspace and with the id of the owner it returns once this check passes.
+//
+// A todo the user can't see at all is reported as gorm.ErrRecordNotFound,
+// like a todo that doesn't exist.
+func authorizeTodo(shares repository.ShareRepository, id string, userId string, need models.TodoRole) (*models.TodoAccess, error) {
+	access, err := shares.Access(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return authorized(access, userId, need)
+}
+
+// authorizeTrashed is authorizeTodo for a todo in the trash.
+func authorizeTrashed(shares repository.ShareRepository, id string, userId string, need models.TodoRole) (*models.TodoAccess, error) {
+	access, err := shares.TrashedAccess(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return authorized(access, userId, need)
+}
+
+// authorized decides the role of the user from the access to a todo, nil
+// when there is no todo, and checks it against the needed one.
+func authorized(access *models.TodoAccess, userId string, need models.TodoRole) (*models.TodoAccess, error) {
+	if access == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	access.Role = todoRole(access, userId)
+	if access.Role == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if !access.Role.Allows(need) {
+		return nil, models.ErrTodoForbidden
+	}
+
+	return access, nil
+}
+
+// todoRole decides the role of a user on a todo. Every member of a workspace
+// can work on its todos, while admins and the creator of a todo manage it as
+// its owner. Personal todos belong to their creator. Shares add access on top.
+func todoRole(access *models.TodoAccess, userId string) models.TodoRole {
+	owner := access.OwnerID.String() == userId
+
+	if access.WorkspaceID != nil {
+		switch {
+		case access.MemberRole.Allows(models.WorkspaceRoleAdmin):
+			return models.TodoRoleOwner
+		case access.MemberRole != "" && owner:
+			return models.TodoRoleOwner
+		case access.MemberRole != "":
+			return models.TodoRoleEditor
+		}
+
+		return access.SharedRole
+	}
+
+	if owner {
+		return models.TodoRoleOwner
+	}
+
+	return access.SharedRole
+}
@@ -0,0 +1,375 @@
+package interactor
+
+import (
+	"sort"
+	"time"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+	"todo-service/utils/recurrence"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type todoTemplateInteractor struct {
+	TemplateRepository  repository.TodoTemplateRepository
+	TemplatePresenter   presenter.TodoTemplatePresenter
+	TodoRepository      repository.TodoRepository
+	TodoEventRepository repository.TodoEventRepository
+	ShareRepository     repository.ShareRepository
+	WorkspaceRepository repository.WorkspaceRepository
+	UserRepository      repository.UserRepository
+	DBRepository        repository.DBRepository
+}
+
+type TodoTemplateInteractor interface {
+	Create(workspaceId string, input model.NewTodoTemplate, userId string) (*models.TodoTemplate, error)
+	SaveTodo(todoId string, name string, userId string) (*models.TodoTemplate, error)
+	List(workspaceId string, userId string) ([]*models.TodoTemplate, error)
+	Delete(id string, userId string) (bool, error)
+	Instantiate(id string, variables []*model.TemplateVariable, start *time.Time, userId string) ([]*models.Todo, error)
+}
+
+func NewTodoTemplateInteractor(
+	r repository.TodoTemplateRepository, p presenter.TodoTemplatePresenter, tr repository.TodoRepository,
+	er repository.TodoEventRepository, sr repository.ShareRepository, wr repository.WorkspaceRepository,
+	ur repository.UserRepository, db repository.DBRepository) TodoTemplateInteractor {
+	return &todoTemplateInteractor{r, p, tr, er, sr, wr, ur, db}
+}
+
+// Create adds a template to a workspace, or to the personal templates of the
+// user when workspaceId is empty.
+func (ti *todoTemplateInteractor) Create(workspaceId string, input model.NewTodoTemplate, userId string) (*models.TodoTemplate, error) {
+	tenant, err := ti.tenant(workspaceId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := templateItems(input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return ti.create(tenant, input.Name, items, userId)
+}
+
+// SaveTodo turns a todo the user can see and its subtasks into a template of
+// the tenant of the todo.
+func (ti *todoTemplateInteractor) SaveTodo(todoId string, name string, userId string) (*models.TodoTemplate, error) {
+	if name == "" || utf8.RuneCountInString(name) > 128 {
+		return nil, models.ErrTemplateName
+	}
+
+	if _, err := uuid.Parse(todoId); err != nil {
+		return nil, models.ErrTodoNotFound
+	}
+
+	access, err := authorizeTodo(ti.ShareRepository, todoId, userId, models.TodoRoleViewer)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+
+	todos, err := ti.TodoRepository.InWorkspace(access.WorkspaceID).ListTree(&todoId, access.OwnerID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	roots := models.BuildTodoTree(todos)
+	if len(roots) == 0 {
+		return nil, models.ErrTodoNotFound
+	}
+
+	anchor := roots[0].Created
+	if roots[0].DueAt != nil {
+		anchor = *roots[0].DueAt
+	}
+
+	// A personal todo shared with the user becomes a personal template of
+	// the user, not of its owner.
+	return ti.create(access.WorkspaceID, name, todoItems(roots, anchor), userId)
+}
+
+func (ti *todoTemplateInteractor) create(workspaceId *uuid.UUID, name string, items []*models.TemplateItem, userId string) (*models.TodoTemplate, error) {
+	if models.CountTemplateItems(items) > models.TemplateMaxItems {
+		return nil, models.ErrTemplateTooLarge
+	}
+
+	template := &models.TodoTemplate{
+		ID:          uuid.New(),
+		UserID:      uuid.MustParse(userId),
+		WorkspaceID: workspaceId,
+		Name:        name,
+		Items:       items,
+	}
+
+	if err := ti.TemplateRepository.Create(template); err != nil {
+		return nil, err
+	}
+
+	return ti.TemplateRepository.GetByID(template.ID.String())
+}
+
+func (ti *todoTemplateInteractor) List(workspaceId string, userId string) ([]*models.TodoTemplate, error) {
+	tenant, err := ti.tenant(workspaceId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ti.TemplateRepository.List(tenant, userId)
+}
+
+// Delete removes a template. Templates of a workspace can be removed by
+// whoever created them and by the admins of the workspace.
+func (ti *todoTemplateInteractor) Delete(id string, userId string) (bool, error) {
+	template, err := ti.template(id, userId)
+	if err != nil {
+		if err == models.ErrTemplateNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if template.UserID.String() != userId {
+		member, err := ti.WorkspaceRepository.GetMember(template.WorkspaceID.String(), userId)
+		if err != nil {
+			return false, err
+		}
+
+		if !member.Role.Allows(models.WorkspaceRoleAdmin) {
+			return false, models.ErrWorkspaceForbidden
+		}
+	}
+
+	return ti.TemplateRepository.Delete(id)
+}
+
+// Instantiate creates the todos of a template for the user, in the tenant of
+// the template, within one transaction. It returns the top-level todos.
+func (ti *todoTemplateInteractor) Instantiate(id string, variables []*model.TemplateVariable, start *time.Time, userId string) ([]*models.Todo, error) {
+	template, err := ti.template(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := ti.UserRepository.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Now()
+	if start != nil {
+		from = *start
+	}
+
+	local := from.In(user.Location())
+	values := map[string]string{
+		"date": local.Format("2006-01-02"),
+		"time": local.Format("15:04"),
+	}
+	for _, variable := range variables {
+		values[variable.Name] = variable.Value
+	}
+
+	actor := uuid.MustParse(userId)
+	todos := ti.TodoRepository.InWorkspace(template.WorkspaceID)
+
+	var created []*models.Todo
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		ids, err := ti.instantiate(tx, todos.WithTx(tx), template.Items, nil, from, values, actor)
+		if err != nil {
+			return err
+		}
+
+		created, err = todos.WithTx(tx).ListByIDs(ids, userId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(created, func(i, j int) bool {
+		return created[i].Position < created[j].Position
+	})
+
+	return created, nil
+}
+
+// instantiate creates the todos of items below parentId, depth first so that
+// they keep the order of the template, and returns the ids of those created
+// for items.
+func (ti *todoTemplateInteractor) instantiate(tx *gorm.DB, todos repository.TodoRepository, items []*models.TemplateItem,
+	parentId *string, start time.Time, values map[string]string, actor uuid.UUID) ([]string, error) {
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		text, err := models.FillTemplate(item.Text, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if utf8.RuneCountInString(text) > 255 {
+			return nil, models.ErrTemplateTextTooLong
+		}
+
+		input := model.NewTodo{Text: text, ParentID: parentId}
+		if item.DueOffset != nil {
+			due := start.Add(time.Duration(*item.DueOffset) * time.Second)
+			input.DueAt = &due
+		}
+		if item.RRule != "" && input.DueAt != nil {
+			input.Rrule = &item.RRule
+		}
+		if item.Priority != "" {
+			input.Priority = &item.Priority
+		}
+		input.Tags = item.Tags
+
+		todo, err := todos.Create(input, actor.String())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ti.TodoEventRepository.WithTx(tx).Create([]*models.TodoEvent{
+			models.NewTodoEvent(todo.ID, actor, models.TodoEventCreated),
+		}); err != nil {
+			return nil, err
+		}
+
+		id := todo.ID.String()
+		if _, err := ti.instantiate(tx, todos, item.Children, &id, start, values, actor); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// templateItems checks the items of a new template.
+func templateItems(input []*model.TodoTemplateItemInput) ([]*models.TemplateItem, error) {
+	items := make([]*models.TemplateItem, 0, len(input))
+	for _, in := range input {
+		item := &models.TemplateItem{Text: in.Text, Priority: models.TodoPriorityNone}
+		if in.Priority != nil {
+			item.Priority = *in.Priority
+		}
+		if len(in.Tags) > 0 {
+			item.Tags = models.NormalizeTags(in.Tags)
+		}
+
+		if in.DueOffset != nil {
+			offset := int64(*in.DueOffset)
+			item.DueOffset = &offset
+		}
+
+		if in.Rrule != nil {
+			rule, err := recurrence.Normalize(*in.Rrule)
+			if err != nil {
+				return nil, models.ErrTodoInvalidRRule
+			}
+
+			if rule != "" && item.DueOffset == nil {
+				return nil, models.ErrTodoRRuleNeedsDue
+			}
+			item.RRule = rule
+		}
+
+		children, err := templateItems(in.Children)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) > 0 {
+			item.Children = children
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// todoItems turns a tree of todos into template items with due dates
+// relative to anchor.
+func todoItems(todos []*models.Todo, anchor time.Time) []*models.TemplateItem {
+	items := make([]*models.TemplateItem, 0, len(todos))
+	for _, todo := range todos {
+		item := &models.TemplateItem{Text: todo.Text, RRule: todo.RRule, Priority: todo.Priority}
+		if len(todo.Tags) > 0 {
+			item.Tags = todo.Tags
+		}
+
+		if todo.DueAt != nil {
+			offset := int64(todo.DueAt.Sub(anchor) / time.Second)
+			item.DueOffset = &offset
+		}
+
+		if len(todo.Children) > 0 {
+			item.Children = todoItems(todo.Children, anchor)
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// tenant resolves the workspace templates are listed in or added to, as long
+// as the user is a member of it, or nil for the personal templates.
+func (ti *todoTemplateInteractor) tenant(workspaceId string, userId string) (*uuid.UUID, error) {
+	if workspaceId == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(workspaceId)
+	if err != nil {
+		return nil, models.ErrWorkspaceNotFound
+	}
+
+	if _, err := ti.WorkspaceRepository.GetMember(id.String(), userId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// template loads a template the user can use: a personal template of the
+// user or a template of a workspace the user is a member of.
+func (ti *todoTemplateInteractor) template(id string, userId string) (*models.TodoTemplate, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrTemplateNotFound
+	}
+
+	template, err := ti.TemplateRepository.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+
+	if template.WorkspaceID == nil {
+		if template.UserID.String() != userId {
+			return nil, models.ErrTemplateNotFound
+		}
+		return template, nil
+	}
+
+	if _, err := ti.WorkspaceRepository.GetMember(template.WorkspaceID.String(), userId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
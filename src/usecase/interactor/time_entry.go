@@ -0,0 +1,347 @@
+package interactor
+
+import (
+	"sort"
+	"time"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type timeEntryInteractor struct {
+	TimeEntryRepository repository.TimeEntryRepository
+	TimeEntryPresenter  presenter.TimeEntryPresenter
+	TodoRepository      repository.TodoRepository
+	ShareRepository     repository.ShareRepository
+	UserRepository      repository.UserRepository
+	DBRepository        repository.DBRepository
+}
+
+type TimeEntryInteractor interface {
+	Start(todoId string, userId string) (*models.TimeEntry, error)
+	Stop(userId string) (*models.TimeEntry, error)
+	Running(userId string) (*models.TimeEntry, error)
+	Create(todoId string, input model.NewTimeEntry, userId string) (*models.TimeEntry, error)
+	Update(id string, input model.UpdateTimeEntry, userId string) (*models.TimeEntry, error)
+	Delete(id string, userId string) (bool, error)
+	ListByTodo(todoId string) ([]*models.TimeEntry, error)
+	TimeSpent(todoIds []string) (map[string]int64, error)
+	Report(from time.Time, to time.Time, groupBy models.TimeReportGroup, userId string) ([]*models.TimeReportRow, error)
+}
+
+func NewTimeEntryInteractor(
+	r repository.TimeEntryRepository, p presenter.TimeEntryPresenter, tr repository.TodoRepository,
+	sr repository.ShareRepository, ur repository.UserRepository, db repository.DBRepository) TimeEntryInteractor {
+	return &timeEntryInteractor{r, p, tr, sr, ur, db}
+}
+
+// Start starts a timer on a todo the user can edit, stopping the timer that
+// runs already.
+func (ei *timeEntryInteractor) Start(todoId string, userId string) (*models.TimeEntry, error) {
+	todo, err := ei.todo(todoId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.TimeEntry{
+		ID:      uuid.New(),
+		TodoID:  todo.ID,
+		UserID:  uuid.MustParse(userId),
+		Started: time.Now(),
+	}
+
+	err = ei.DBRepository.Transaction(func(tx *gorm.DB) error {
+		entries := ei.TimeEntryRepository.WithTx(tx)
+
+		running, err := entries.GetRunning(userId)
+		if err != nil {
+			return err
+		}
+
+		if running != nil {
+			if err := entries.Update(running.ID.String(), userId, map[string]interface{}{"stopped": entry.Started}); err != nil {
+				return err
+			}
+		}
+
+		return entries.Create(entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ei.TimeEntryRepository.GetByID(entry.ID.String(), userId)
+}
+
+func (ei *timeEntryInteractor) Stop(userId string) (*models.TimeEntry, error) {
+	running, err := ei.TimeEntryRepository.GetRunning(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if running == nil {
+		return nil, models.ErrTimerNotRunning
+	}
+
+	if err := ei.TimeEntryRepository.Update(running.ID.String(), userId, map[string]interface{}{"stopped": time.Now()}); err != nil {
+		return nil, err
+	}
+
+	return ei.TimeEntryRepository.GetByID(running.ID.String(), userId)
+}
+
+func (ei *timeEntryInteractor) Running(userId string) (*models.TimeEntry, error) {
+	return ei.TimeEntryRepository.GetRunning(userId)
+}
+
+// Create adds time the user spent on a todo without a timer.
+func (ei *timeEntryInteractor) Create(todoId string, input model.NewTimeEntry, userId string) (*models.TimeEntry, error) {
+	if !input.Stopped.After(input.Started) {
+		return nil, models.ErrTimeEntryInvalid
+	}
+
+	todo, err := ei.todo(todoId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.TimeEntry{
+		ID:      uuid.New(),
+		TodoID:  todo.ID,
+		UserID:  uuid.MustParse(userId),
+		Started: input.Started,
+		Stopped: &input.Stopped,
+	}
+	if input.Note != nil {
+		entry.Note = *input.Note
+	}
+
+	if err := ei.TimeEntryRepository.Create(entry); err != nil {
+		return nil, err
+	}
+
+	return ei.TimeEntryRepository.GetByID(entry.ID.String(), userId)
+}
+
+// Update changes a time entry of the user. Setting the end of a running timer
+// stops it.
+func (ei *timeEntryInteractor) Update(id string, input model.UpdateTimeEntry, userId string) (*models.TimeEntry, error) {
+	entry, err := ei.own(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if input.Started != nil {
+		entry.Started = *input.Started
+		fields["started"] = *input.Started
+	}
+	if input.Stopped != nil {
+		entry.Stopped = input.Stopped
+		fields["stopped"] = *input.Stopped
+	}
+	if input.Note != nil {
+		fields["note"] = *input.Note
+	}
+
+	if len(fields) == 0 {
+		return entry, nil
+	}
+
+	if entry.Stopped != nil && !entry.Stopped.After(entry.Started) {
+		return nil, models.ErrTimeEntryInvalid
+	}
+	if entry.Stopped == nil && entry.Started.After(time.Now()) {
+		return nil, models.ErrTimeEntryInvalid
+	}
+
+	if err := ei.TimeEntryRepository.Update(id, userId, fields); err != nil {
+		return nil, err
+	}
+
+	return ei.TimeEntryRepository.GetByID(id, userId)
+}
+
+func (ei *timeEntryInteractor) Delete(id string, userId string) (bool, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return false, nil
+	}
+
+	return ei.TimeEntryRepository.Delete(id, userId)
+}
+
+// ListByTodo returns the time entries of every user on a todo the caller can
+// see already.
+func (ei *timeEntryInteractor) ListByTodo(todoId string) ([]*models.TimeEntry, error) {
+	return ei.TimeEntryRepository.ListByTodo(todoId)
+}
+
+// TimeSpent returns the seconds spent on each of the todos the caller can see
+// already, by id.
+func (ei *timeEntryInteractor) TimeSpent(todoIds []string) (map[string]int64, error) {
+	valid := make([]string, 0, len(todoIds))
+	for _, id := range todoIds {
+		if parsed, err := uuid.Parse(id); err == nil {
+			valid = append(valid, parsed.String())
+		}
+	}
+
+	return ei.TimeEntryRepository.SumByTodos(valid, time.Now())
+}
+
+// Report adds up the time the user spent between from and to, by day in the
+// time zone of the user or by todo. Entries that overlap the range only count
+// for the part inside of it.
+func (ei *timeEntryInteractor) Report(from time.Time, to time.Time, groupBy models.TimeReportGroup, userId string) ([]*models.TimeReportRow, error) {
+	if !to.After(from) {
+		return nil, models.ErrTimeReportInvalid
+	}
+
+	user, err := ei.UserRepository.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ei.TimeEntryRepository.ListByUser(userId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch groupBy {
+	case models.TimeReportGroupDay:
+		return reportByDay(entries, from, to, now, user.Location()), nil
+	case models.TimeReportGroupTodo:
+		return reportByTodo(entries, from, to, now), nil
+	default:
+		return nil, models.ErrTimeReportGrouping
+	}
+}
+
+// clip returns the part of an entry inside of from to, which is empty if
+// started isn't before stopped.
+func clip(entry *models.TimeEntry, from time.Time, to time.Time, now time.Time) (time.Time, time.Time) {
+	started, stopped := entry.Started, now
+	if entry.Stopped != nil {
+		stopped = *entry.Stopped
+	}
+
+	if started.Before(from) {
+		started = from
+	}
+	if stopped.After(to) {
+		stopped = to
+	}
+
+	return started, stopped
+}
+
+func reportByDay(entries []*models.TimeEntry, from time.Time, to time.Time, now time.Time, loc *time.Location) []*models.TimeReportRow {
+	days := map[time.Time]*models.TimeReportRow{}
+	for _, entry := range entries {
+		started, stopped := clip(entry, from, to, now)
+
+		for started.Before(stopped) {
+			local := started.In(loc)
+			day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+			end := day.AddDate(0, 0, 1)
+			if end.After(stopped) {
+				end = stopped
+			}
+
+			row, ok := days[day]
+			if !ok {
+				row = &models.TimeReportRow{Day: &day}
+				days[day] = row
+			}
+			row.Seconds += int64(end.Sub(started) / time.Second)
+
+			started = end
+		}
+	}
+
+	rows := make([]*models.TimeReportRow, 0, len(days))
+	for _, row := range days {
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Day.Before(*rows[j].Day)
+	})
+
+	return rows
+}
+
+func reportByTodo(entries []*models.TimeEntry, from time.Time, to time.Time, now time.Time) []*models.TimeReportRow {
+	todos := map[uuid.UUID]*models.TimeReportRow{}
+	var rows []*models.TimeReportRow
+	for _, entry := range entries {
+		started, stopped := clip(entry, from, to, now)
+		if !started.Before(stopped) || entry.Todo == nil {
+			continue
+		}
+
+		row, ok := todos[entry.TodoID]
+		if !ok {
+			row = &models.TimeReportRow{Todo: entry.Todo}
+			todos[entry.TodoID] = row
+			rows = append(rows, row)
+		}
+		row.Seconds += int64(stopped.Sub(started) / time.Second)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Seconds > rows[j].Seconds
+	})
+
+	return rows
+}
+
+// todo loads a todo the user can edit, since tracking time on a todo is
+// working on it.
+func (ei *timeEntryInteractor) todo(todoId string, userId string) (*models.Todo, error) {
+	if _, err := uuid.Parse(todoId); err != nil {
+		return nil, models.ErrTodoNotFound
+	}
+
+	access, err := authorizeTodo(ei.ShareRepository, todoId, userId, models.TodoRoleEditor)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+
+	todo, err := ei.TodoRepository.InWorkspace(access.WorkspaceID).GetByID(todoId, access.OwnerID.String())
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// own loads a time entry of the user, entries of other users are reported as
+// missing.
+func (ei *timeEntryInteractor) own(id string, userId string) (*models.TimeEntry, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrTimeEntryNotFound
+	}
+
+	entry, err := ei.TimeEntryRepository.GetByID(id, userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTimeEntryNotFound
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -1,41 +1,355 @@
 package interactor
 
 import (
+	"errors"
+	"io"
+	"time"
 	"todo-service/graph/model"
 	"todo-service/src/models"
 	"todo-service/src/usecase/presenter"
 	"todo-service/src/usecase/repository"
+	"todo-service/utils/quickadd"
+	"todo-service/utils/recurrence"
+
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gorm.io/gorm"
 )
 
 type todoInteractor struct {
-	TodoRepository repository.TodoRepository
-	TodoPresenter  presenter.TodoPresenter
+	TodoRepository       repository.TodoRepository
+	TodoPresenter        presenter.TodoPresenter
+	DBRepository         repository.DBRepository
+	ShareRepository      repository.ShareRepository
+	WorkspaceRepository  repository.WorkspaceRepository
+	TodoEventRepository  repository.TodoEventRepository
+	TodoUndoRepository   repository.TodoUndoRepository
+	DependencyRepository repository.TodoDependencyRepository
+	WorkflowRepository   repository.WorkflowRepository
+	UserRepository       repository.UserRepository
+	settings             models.TodoSettings
+
+	// member is the membership of the user in the workspace the interactor
+	// is bound to by InWorkspace, nil for personal todos.
+	member *models.WorkspaceMember
 }
 
 type TodoInteractor interface {
 	Create(input model.NewTodo, userId string) (*models.Todo, error)
+	Parse(text string, userId string) (*model.ParsedTodo, error)
+	Update(id string, input model.UpdateTodo, scope model.EditScope, userId string) (*models.Todo, error)
 	MarkComplete(id string, userId string) (*models.Todo, error)
-	Delete(id string, userId string) (bool, error)
+	Transition(id string, name string, userId string) (*models.Todo, error)
+	Delete(id string, userId string) (bool, string, error)
+	Undo(token string, userId string) (*models.Todo, error)
+	Assign(id string, assigneeId string, userId string) (*models.Todo, error)
+	Unassign(id string, userId string) (*models.Todo, error)
+	AssignedTo(userId string) ([]*models.Todo, error)
+	AddDependency(blockerId string, blockedId string, userId string) (*models.Todo, error)
+	RemoveDependency(blockerId string, blockedId string, userId string) (bool, error)
+	BlockedBy(id string, userId string) ([]*models.Todo, error)
+	Blocks(id string, userId string) ([]*models.Todo, error)
+	BulkUpdate(ids []string, patch model.TodoPatch, userId string) ([]*model.BulkTodoResult, error)
+	BulkDelete(ids []string, userId string) ([]*model.BulkTodoResult, error)
+	Move(id string, beforeId *string, afterId *string, userId string) (*models.Todo, error)
+	Trashed(userId string) ([]*models.Todo, error)
+	Restore(id string, userId string) (*models.Todo, error)
+	EmptyTrash(userId string) (int, error)
+	PurgeTrash() (int64, error)
 	GetByID(id string, userId string) (*models.Todo, error)
 	List(userId string) ([]*models.Todo, error)
+	Paginate(page models.PageArgs, filter *model.TodoFilter, order []*model.TodoOrder, userId string) (*models.TodoConnection, error)
+	ListChildren(parentId string, userId string) ([]*models.Todo, error)
+	Search(query string, limit *int, userId string) ([]*models.TodoSearchResult, error)
+	Tree(rootId *string, userId string) ([]*models.Todo, error)
+	Progress(todoIds []string, userId string) (map[string]float64, error)
+	Role(todo *models.Todo, userId string) (models.TodoRole, error)
+	Roles(todoIds []string, userId string) (map[string]models.TodoRole, error)
+	History(id string, userId string) ([]*models.TodoEvent, error)
+	Export(format models.TodoFileFormat, w io.Writer, userId string) error
+	Import(file io.Reader, format models.TodoFileFormat, mapping []*model.TodoColumnMapping, dryRun bool, userId string) (*model.TodoImportResult, error)
+	CalendarObject(uid string, userId string) (*models.CalendarObject, error)
+	CalendarObjects(userId string, fn func(object *models.CalendarObject) error) error
+	CalendarSyncToken(userId string) (string, error)
+	CalendarChanges(syncToken string, userId string, fn func(object *models.CalendarObject) error) (string, error)
+	PutCalendarObject(uid string, data io.Reader, userId string) (*models.CalendarObject, bool, error)
+	DeleteCalendarObject(uid string, userId string) (bool, error)
+	InWorkspace(workspaceId string, userId string) (TodoInteractor, error)
 }
 
 func NewTodoInteractor(
-	r repository.TodoRepository, p presenter.TodoPresenter) TodoInteractor {
-	return &todoInteractor{r, p}
+	r repository.TodoRepository, p presenter.TodoPresenter, db repository.DBRepository,
+	sr repository.ShareRepository, wr repository.WorkspaceRepository, er repository.TodoEventRepository,
+	ur repository.TodoUndoRepository, dr repository.TodoDependencyRepository, fr repository.WorkflowRepository,
+	usr repository.UserRepository, s models.TodoSettings) TodoInteractor {
+	return &todoInteractor{r, p, db, sr, wr, er, ur, dr, fr, usr, s, nil}
+}
+
+// InWorkspace returns the todos of the workspace selected for a request, or
+// the personal todos of the user when no workspace is selected. Lists and new
+// todos stay within that tenant, while a single todo is always worked on in
+// the tenant it belongs to, as far as the user has access to it.
+func (ti *todoInteractor) InWorkspace(workspaceId string, userId string) (TodoInteractor, error) {
+	if workspaceId == "" {
+		return ti, nil
+	}
+
+	id, err := uuid.Parse(workspaceId)
+	if err != nil {
+		return nil, models.ErrWorkspaceNotFound
+	}
+
+	member, err := ti.WorkspaceRepository.GetMember(id.String(), userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	scoped := ti.in(&id)
+	scoped.member = member
+	return scoped, nil
+}
+
+// in returns a copy of the interactor bound to the todos of a workspace, or
+// to personal todos when workspaceId is nil.
+func (ti *todoInteractor) in(workspaceId *uuid.UUID) *todoInteractor {
+	scoped := *ti
+	scoped.TodoRepository = ti.TodoRepository.InWorkspace(workspaceId)
+	return &scoped
 }
 
+// Create adds a todo for the user. Subtasks go to the tenant of their parent,
+// and subtasks of a shared personal todo belong to the owner of the parent so
+// that the whole tree stays with one owner.
 func (ti *todoInteractor) Create(input model.NewTodo, userId string) (*models.Todo, error) {
-	todo, err := ti.TodoRepository.Create(input, userId)
+	if input.Parse != nil && *input.Parse {
+		parsed, err := ti.Parse(input.Text, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		input.Text = parsed.Text
+		if input.DueAt == nil {
+			input.DueAt = parsed.DueAt
+		}
+		if input.Rrule == nil {
+			input.Rrule = parsed.Rrule
+		}
+		if input.Priority == nil && parsed.Priority != models.TodoPriorityNone {
+			input.Priority = &parsed.Priority
+		}
+		input.Tags = append(input.Tags, parsed.Tags...)
+	}
+
+	ownerId := userId
+	if input.ParentID != nil {
+		access, err := authorizeTodo(ti.ShareRepository, *input.ParentID, userId, models.TodoRoleEditor)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, models.ErrTodoParentNotFound
+			}
+			return nil, err
+		}
+
+		ti = ti.in(access.WorkspaceID)
+		if access.WorkspaceID == nil {
+			ownerId = access.OwnerID.String()
+		}
+	}
+
+	if input.Rrule != nil {
+		rule, err := recurrence.Normalize(*input.Rrule)
+		if err != nil {
+			return nil, models.ErrTodoInvalidRRule
+		}
+
+		if rule != "" && input.DueAt == nil {
+			return nil, models.ErrTodoRRuleNeedsDue
+		}
+		input.Rrule = &rule
+	}
+
+	actor := uuid.MustParse(userId)
+
+	var todo *models.Todo
+	err := ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.TodoRepository.WithTx(tx)
+
+		created, err := todos.Create(input, ownerId)
+		if err != nil {
+			return err
+		}
+
+		if err := ti.record(tx, models.NewTodoEvent(created.ID, actor, models.TodoEventCreated)); err != nil {
+			return err
+		}
+
+		if todo, err = todos.GetByID(created.ID.String(), ownerId); err != nil {
+			return err
+		}
+
+		todo.UndoToken, err = ti.undoable(tx, todo, models.TodoEventCreated, actor, models.TodoUndoState{})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return ti.TodoRepository.GetByID(todo.ID.String(), userId)
+	return todo, nil
 }
 
-func (ti *todoInteractor) MarkComplete(id string, userId string) (*models.Todo, error) {
-	err := ti.TodoRepository.MarkComplete(id, userId)
+// Parse reads the details of a todo from free text, with dates in the time
+// zone of the user.
+func (ti *todoInteractor) Parse(text string, userId string) (*model.ParsedTodo, error) {
+	user, err := ti.UserRepository.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := quickadd.Parse(text, time.Now(), user.Location())
+
+	parsed := &model.ParsedTodo{
+		Text:     result.Text,
+		DueAt:    result.DueAt,
+		Priority: models.TodoPriorityNone,
+		Tags:     models.NormalizeTags(result.Tags),
+	}
+	if result.Priority != "" {
+		parsed.Priority = models.TodoPriority(result.Priority)
+	}
+	if result.RRule != "" {
+		parsed.Rrule = &result.RRule
+	}
+
+	return parsed, nil
+}
+
+func (ti *todoInteractor) Update(id string, input model.UpdateTodo, scope model.EditScope, userId string) (*models.Todo, error) {
+	access, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	actor := uuid.MustParse(userId)
+	ti, userId = ti.in(access.WorkspaceID), access.OwnerID.String()
+
+	todo, err := ti.TodoRepository.GetByID(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if input.Text != nil {
+		fields["text"] = *input.Text
+	}
+	if input.DueAt != nil {
+		fields["due_at"] = *input.DueAt
+	}
+	if input.Priority != nil {
+		fields["priority"] = *input.Priority
+	}
+	if input.Tags != nil {
+		fields["tags"] = models.NormalizeTags(input.Tags)
+	}
+
+	recurring := todo.SeriesID != nil
+	if recurring && scope == model.EditScopeThisOccurrence && input.Rrule != nil {
+		return nil, models.ErrTodoRRuleScope
+	}
+
+	// Only an edit of a single todo can be undone, an edit that splits a
+	// series changes the todos of the series as well.
+	if input.Rrule == nil && (!recurring || scope == model.EditScopeThisOccurrence) {
+		// Tags go into the undo state as the JSON they are stored as.
+		tags, _ := todo.Tags.Value()
+		previous := map[string]interface{}{"text": todo.Text, "due_at": todo.DueAt, "priority": todo.Priority, "tags": tags}
+		for column := range previous {
+			if _, ok := fields[column]; !ok {
+				delete(previous, column)
+			}
+		}
+
+		var updated *models.Todo
+		err := ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+			if err := ti.TodoRepository.WithTx(tx).Update(id, userId, fields); err != nil {
+				return err
+			}
+
+			var err error
+			if updated, err = ti.recordChanges(tx, todo, models.TodoEventUpdated, actor); err != nil {
+				return err
+			}
+
+			updated.UndoToken, err = ti.undoable(tx, updated, models.TodoEventUpdated, actor,
+				models.TodoUndoState{Fields: previous})
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return updated, nil
+	}
+
+	rule := todo.RRule
+	if input.Rrule != nil {
+		rule, err = recurrence.Normalize(*input.Rrule)
+		if err != nil {
+			return nil, models.ErrTodoInvalidRRule
+		}
+	}
+
+	start := todo.DueAt
+	if input.DueAt != nil {
+		start = input.DueAt
+	}
+
+	if rule != "" && start == nil {
+		return nil, models.ErrTodoRRuleNeedsDue
+	}
+
+	// Editing future occurrences splits the series: this and the later open
+	// occurrences move to a new series anchored at this one, while completed
+	// occurrences keep the old rule.
+	series := map[string]interface{}{
+		"rrule":        rule,
+		"series_id":    nil,
+		"series_start": nil,
+	}
+	if rule != "" {
+		series["series_id"] = uuid.New()
+		series["series_start"] = *start
+	} else {
+		series["occurrence_at"] = nil
+	}
+	if input.Text != nil {
+		series["text"] = *input.Text
+	}
+
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.TodoRepository.WithTx(tx)
+
+		if recurring && todo.OccurrenceAt != nil {
+			if err := todos.UpdateSeries(todo.SeriesID.String(), *todo.OccurrenceAt, userId, series); err != nil {
+				return err
+			}
+		}
+
+		for k, v := range series {
+			fields[k] = v
+		}
+		if rule != "" {
+			fields["occurrence_at"] = *start
+		}
+
+		if err := todos.Update(id, userId, fields); err != nil {
+			return err
+		}
+
+		_, err := ti.recordChanges(tx, todo, models.TodoEventUpdated, actor)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -43,14 +357,1425 @@ func (ti *todoInteractor) MarkComplete(id string, userId string) (*models.Todo,
 	return ti.TodoRepository.GetByID(id, userId)
 }
 
-func (ti *todoInteractor) Delete(id string, userId string) (bool, error) {
-	return ti.TodoRepository.Delete(id, userId)
+func (ti *todoInteractor) MarkComplete(id string, userId string) (*models.Todo, error) {
+	access, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	actor := uuid.MustParse(userId)
+	ti, userId = ti.in(access.WorkspaceID), access.OwnerID.String()
+
+	var completed *models.Todo
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todo, err := ti.TodoRepository.WithTx(tx).GetByID(id, userId)
+		if err != nil {
+			return err
+		}
+
+		var state models.TodoUndoState
+		if completed, state, err = ti.complete(tx, todo, userId, actor); err != nil {
+			return err
+		}
+
+		completed.UndoToken, err = ti.undoable(tx, completed, models.TodoEventCompleted, actor, state)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return completed, nil
 }
 
-func (ti *todoInteractor) GetByID(id string, userId string) (*models.Todo, error) {
-	return ti.TodoRepository.GetByID(id, userId)
+// complete marks a todo of the owner userId done within tx, along with its
+// subtasks as configured, and schedules the next occurrence of a recurring
+// todo. It returns the completed todo and what it takes to undo that.
+func (ti *todoInteractor) complete(tx *gorm.DB, todo *models.Todo, userId string, actor uuid.UUID) (*models.Todo, models.TodoUndoState, error) {
+	todos := ti.TodoRepository.WithTx(tx)
+	id := todo.ID.String()
+
+	var warnings []string
+	if !todo.Done {
+		blocked, err := ti.DependencyRepository.WithTx(tx).ListOpenlyBlocked([]string{id})
+		if err != nil {
+			return nil, models.TodoUndoState{}, err
+		}
+
+		if len(blocked) > 0 {
+			if ti.settings.BlockedComplete != models.BlockedWarn {
+				return nil, models.TodoUndoState{}, models.ErrTodoBlocked
+			}
+			warnings = append(warnings, models.ErrTodoBlocked.Message)
+		}
+	}
+
+	var err error
+	var state models.TodoUndoState
+	if state.Completed, err = openTodos(todos, todo, userId, ti.settings.CompleteCascade); err != nil {
+		return nil, models.TodoUndoState{}, err
+	}
+
+	if err := todos.MarkComplete([]string{id}, userId, ti.settings.CompleteCascade); err != nil {
+		return nil, models.TodoUndoState{}, err
+	}
+
+	completed, err := ti.recordChanges(tx, todo, models.TodoEventCompleted, actor)
+	if err != nil {
+		return nil, models.TodoUndoState{}, err
+	}
+
+	if !todo.Done && todo.RRule != "" {
+		next, err := nextOccurrence(todo)
+		if err != nil {
+			return nil, models.TodoUndoState{}, err
+		}
+
+		if next != nil {
+			if err := todos.Insert(next); err != nil {
+				return nil, models.TodoUndoState{}, err
+			}
+
+			if err := ti.record(tx, models.NewTodoEvent(next.ID, actor, models.TodoEventCreated)); err != nil {
+				return nil, models.TodoUndoState{}, err
+			}
+			state.Next = &next.ID
+		}
+	}
+
+	completed.Warnings = warnings
+	return completed, state, nil
 }
 
-func (ti *todoInteractor) List(userId string) ([]*models.Todo, error) {
-	return ti.TodoRepository.List(userId)
+// Transition moves a todo to the status with the given name in the workflow
+// of its tenant. Moving between an open and a done status completes or
+// reopens the todo, completing goes through the same rules as MarkComplete.
+func (ti *todoInteractor) Transition(id string, name string, userId string) (*models.Todo, error) {
+	access, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	actor := uuid.MustParse(userId)
+	ti, userId = ti.in(access.WorkspaceID), access.OwnerID.String()
+
+	workflow, err := ti.WorkflowRepository.List(access.WorkspaceID, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(workflow) == 0 {
+		return nil, models.ErrWorkflowNotDefined
+	}
+
+	target := workflow.Named(name)
+	if target == nil {
+		return nil, models.ErrWorkflowStatusNotFound
+	}
+
+	var moved *models.Todo
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.TodoRepository.WithTx(tx)
+
+		todo, err := todos.GetByID(id, userId)
+		if err != nil {
+			return err
+		}
+
+		current := workflow.Current(todo)
+		if current != nil && current.ID == target.ID {
+			moved = todo
+			return nil
+		}
+
+		if current != nil && !current.Allows(target) {
+			return models.ErrWorkflowTransition
+		}
+
+		fields := map[string]interface{}{"status_id": target.ID}
+		state := models.TodoUndoState{Fields: map[string]interface{}{"status_id": todo.StatusID}}
+		if target.Done() && !todo.Done {
+			done, completed, err := ti.complete(tx, todo, userId, actor)
+			if err != nil {
+				return err
+			}
+			todo, state.Completed, state.Next = done, completed.Completed, completed.Next
+		} else if !target.Done() && todo.Done {
+			fields["done"] = false
+			fields["completed_at"] = nil
+			state.Fields["done"] = true
+			state.Fields["completed_at"] = todo.CompletedAt
+		}
+
+		if err := todos.Update(id, userId, fields); err != nil {
+			return err
+		}
+
+		warnings := todo.Warnings
+		if moved, err = ti.recordChanges(tx, todo, models.TodoEventUpdated, actor); err != nil {
+			return err
+		}
+		moved.Warnings = warnings
+
+		moved.UndoToken, err = ti.undoable(tx, moved, models.TodoEventUpdated, actor, state)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// openTodos returns the todos that completing todo is going to close: the
+// todo itself unless it's done already, and its open subtasks when they are
+// completed along with it.
+func openTodos(todos repository.TodoRepository, todo *models.Todo, userId string, cascade models.CascadeRule) ([]uuid.UUID, error) {
+	tree := []*models.Todo{todo}
+	if cascade == models.CascadeChildren {
+		id := todo.ID.String()
+
+		var err error
+		if tree, err = todos.ListTree(&id, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	open := make([]uuid.UUID, 0, len(tree))
+	for _, t := range tree {
+		if !t.Done {
+			open = append(open, t.ID)
+		}
+	}
+
+	return open, nil
+}
+
+// Delete moves a todo to the trash and returns whether it did, along with
+// the token that undoes it.
+func (ti *todoInteractor) Delete(id string, userId string) (bool, string, error) {
+	access, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleOwner)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+	actor := uuid.MustParse(userId)
+
+	var deleted bool
+	var token string
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.in(access.WorkspaceID).TodoRepository.WithTx(tx)
+
+		todo, err := todos.GetByID(id, userId)
+		if err != nil {
+			return err
+		}
+
+		deleted, err = todos.Delete(id, userId, ti.settings.DeleteCascade)
+		if err != nil || !deleted {
+			return err
+		}
+
+		if err := ti.record(tx, models.NewTodoEvent(todo.ID, actor, models.TodoEventDeleted)); err != nil {
+			return err
+		}
+
+		token, err = ti.undoable(tx, todo, models.TodoEventDeleted, actor, models.TodoUndoState{})
+		return err
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	return deleted, token, nil
+}
+
+// Assign makes a user responsible for a todo. The assignee has to be able to
+// see the todo, whether through the workspace or a share.
+func (ti *todoInteractor) Assign(id string, assigneeId string, userId string) (*models.Todo, error) {
+	assignee, err := uuid.Parse(assigneeId)
+	if err != nil {
+		return nil, models.ErrTodoAssigneeAccess
+	}
+
+	return ti.assign(id, &assignee, userId)
+}
+
+func (ti *todoInteractor) Unassign(id string, userId string) (*models.Todo, error) {
+	return ti.assign(id, nil, userId)
+}
+
+func (ti *todoInteractor) assign(id string, assigneeId *uuid.UUID, userId string) (*models.Todo, error) {
+	access, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	ti, owner := ti.in(access.WorkspaceID), access.OwnerID.String()
+
+	fields := map[string]interface{}{"assignee_id": nil}
+	if assigneeId != nil {
+		if _, err := authorizeTodo(ti.ShareRepository, id, assigneeId.String(), models.TodoRoleViewer); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, models.ErrTodoAssigneeAccess
+			}
+			return nil, err
+		}
+		fields["assignee_id"] = *assigneeId
+	}
+
+	actor := uuid.MustParse(userId)
+
+	var assigned *models.Todo
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.TodoRepository.WithTx(tx)
+
+		todo, err := todos.GetByID(id, owner)
+		if err != nil {
+			return err
+		}
+
+		if err := todos.Update(id, owner, fields); err != nil {
+			return err
+		}
+
+		if assigned, err = ti.recordChanges(tx, todo, models.TodoEventAssigned, actor); err != nil {
+			return err
+		}
+
+		assigned.UndoToken, err = ti.undoable(tx, assigned, models.TodoEventAssigned, actor,
+			models.TodoUndoState{Fields: map[string]interface{}{"assignee_id": todo.AssigneeID}})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assigned, nil
+}
+
+// AssignedTo lists the todos of the tenant that are assigned to the user.
+// Todos the user has lost access to since they were assigned are left out.
+func (ti *todoInteractor) AssignedTo(userId string) ([]*models.Todo, error) {
+	todos, err := ti.TodoRepository.ListAssigned(userId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ti.visible(todos, userId)
+}
+
+// visible leaves out the todos the user has no access to.
+func (ti *todoInteractor) visible(todos []*models.Todo, userId string) ([]*models.Todo, error) {
+	visible := make([]*models.Todo, 0, len(todos))
+	for _, todo := range todos {
+		if _, err := ti.Role(todo, userId); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				continue
+			}
+			return nil, err
+		}
+		visible = append(visible, todo)
+	}
+
+	return visible, nil
+}
+
+// AddDependency marks that blocker has to be completed before blocked. Both
+// todos have to belong to the same tenant, and an edge that would close a
+// cycle in the dependency graph is refused.
+func (ti *todoInteractor) AddDependency(blockerId string, blockedId string, userId string) (*models.Todo, error) {
+	blocked, err := authorizeTodo(ti.ShareRepository, blockedId, userId, models.TodoRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	blocker, err := authorizeTodo(ti.ShareRepository, blockerId, userId, models.TodoRoleViewer)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+
+	if !sameTenant(blocker, blocked) {
+		return nil, models.ErrTodoDependencyTenant
+	}
+
+	dependency := &models.TodoDependency{BlockerID: uuid.MustParse(blockerId), BlockedID: uuid.MustParse(blockedId)}
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		dependencies := ti.DependencyRepository.WithTx(tx)
+		if err := dependencies.Lock(); err != nil {
+			return err
+		}
+
+		cycle := dependency.BlockerID == dependency.BlockedID
+		if !cycle {
+			if cycle, err = blocks(dependencies, dependency.BlockedID, dependency.BlockerID); err != nil {
+				return err
+			}
+		}
+
+		if cycle {
+			return models.ErrTodoDependencyCycle
+		}
+
+		if err := dependencies.Add(dependency); err != nil {
+			return err
+		}
+
+		event := models.NewTodoEvent(dependency.BlockedID, uuid.MustParse(userId), models.TodoEventUpdated)
+		event.Field, event.NewValue = "blockedBy", &blockerId
+		return ti.record(tx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ti.in(blocked.WorkspaceID).TodoRepository.GetByID(blockedId, blocked.OwnerID.String())
+}
+
+func (ti *todoInteractor) RemoveDependency(blockerId string, blockedId string, userId string) (bool, error) {
+	if _, err := authorizeTodo(ti.ShareRepository, blockedId, userId, models.TodoRoleEditor); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, err := uuid.Parse(blockerId); err != nil {
+		return false, nil
+	}
+
+	var removed bool
+	err := ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		var err error
+		removed, err = ti.DependencyRepository.WithTx(tx).Remove(blockerId, blockedId)
+		if err != nil || !removed {
+			return err
+		}
+
+		event := models.NewTodoEvent(uuid.MustParse(blockedId), uuid.MustParse(userId), models.TodoEventUpdated)
+		event.Field, event.OldValue = "blockedBy", &blockerId
+		return ti.record(tx, event)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// BlockedBy lists the todos that have to be completed before the todo.
+func (ti *todoInteractor) BlockedBy(id string, userId string) ([]*models.Todo, error) {
+	todos, err := ti.DependencyRepository.ListBlockers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ti.visible(todos, userId)
+}
+
+// Blocks lists the todos that wait for the todo to be completed.
+func (ti *todoInteractor) Blocks(id string, userId string) ([]*models.Todo, error) {
+	todos, err := ti.DependencyRepository.ListBlocked(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ti.visible(todos, userId)
+}
+
+// blocks reports whether from blocks to, directly or through other todos,
+// by walking the dependency graph breadth first.
+func blocks(dependencies repository.TodoDependencyRepository, from uuid.UUID, to uuid.UUID) (bool, error) {
+	seen := map[uuid.UUID]bool{from: true}
+	frontier := []string{from.String()}
+
+	for len(frontier) > 0 {
+		edges, err := dependencies.ListByBlockers(frontier)
+		if err != nil {
+			return false, err
+		}
+
+		next := make([]string, 0, len(edges))
+		for _, edge := range edges {
+			if edge.BlockedID == to {
+				return true, nil
+			}
+
+			if !seen[edge.BlockedID] {
+				seen[edge.BlockedID] = true
+				next = append(next, edge.BlockedID.String())
+			}
+		}
+		frontier = next
+	}
+
+	return false, nil
+}
+
+// sameTenant reports whether two todos belong to the same workspace, or are
+// personal todos of the same user.
+func sameTenant(a *models.TodoAccess, b *models.TodoAccess) bool {
+	if a.WorkspaceID == nil || b.WorkspaceID == nil {
+		return a.WorkspaceID == nil && b.WorkspaceID == nil && a.OwnerID == b.OwnerID
+	}
+
+	return *a.WorkspaceID == *b.WorkspaceID
+}
+
+// BulkUpdate applies the patch to every todo in ids the user may edit within
+// one transaction. Marking todos done follows the same rules as MarkComplete.
+func (ti *todoInteractor) BulkUpdate(ids []string, patch model.TodoPatch, userId string) ([]*model.BulkTodoResult, error) {
+	if len(ids) > ti.settings.MaxPageSize {
+		return nil, models.ErrTodoBulkTooLarge
+	}
+	actor := uuid.MustParse(userId)
+
+	fields := map[string]interface{}{}
+	if patch.Text != nil {
+		fields["text"] = *patch.Text
+	}
+	if patch.DueAt != nil {
+		fields["due_at"] = *patch.DueAt
+	}
+	if patch.Done != nil && !*patch.Done {
+		fields["done"] = false
+		fields["completed_at"] = nil
+	}
+
+	var results []*model.BulkTodoResult
+	err := ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		var todos []*models.Todo
+		var accesses []*models.TodoAccess
+		var err error
+		results, todos, accesses, err = ti.bulkLookup(tx, ids, models.TodoRoleEditor, userId)
+		if err != nil {
+			return err
+		}
+
+		if patch.Done != nil && *patch.Done && ti.settings.BlockedComplete != models.BlockedWarn {
+			if err := ti.refuseBlocked(tx, results, todos); err != nil {
+				return err
+			}
+		}
+
+		events := make([]*models.TodoEvent, 0)
+		for _, tenant := range bulkTenants(todos, accesses) {
+			repo := ti.in(tenant.access.WorkspaceID).TodoRepository.WithTx(tx)
+			owner := tenant.access.OwnerID.String()
+
+			if len(fields) > 0 {
+				if err := repo.UpdateMany(tenant.ids, owner, fields); err != nil {
+					return err
+				}
+			}
+
+			if patch.Done != nil && *patch.Done {
+				if err := repo.MarkComplete(tenant.ids, owner, ti.settings.CompleteCascade); err != nil {
+					return err
+				}
+			}
+
+			updated, err := repo.ListByIDs(tenant.ids, owner)
+			if err != nil {
+				return err
+			}
+
+			after := make(map[uuid.UUID]*models.Todo, len(updated))
+			for _, todo := range updated {
+				after[todo.ID] = todo
+			}
+
+			for _, todo := range tenant.todos {
+				if after[todo.ID] != nil {
+					events = append(events, models.TodoChanges(models.TodoEventUpdated, actor, todo, after[todo.ID])...)
+				}
+
+				if patch.Done == nil || !*patch.Done || todo.Done || todo.RRule == "" {
+					continue
+				}
+
+				next, err := nextOccurrence(todo)
+				if err != nil {
+					return err
+				}
+
+				if next != nil {
+					if err := repo.Insert(next); err != nil {
+						return err
+					}
+					events = append(events, models.NewTodoEvent(next.ID, actor, models.TodoEventCreated))
+				}
+			}
+		}
+
+		return ti.record(tx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.Success = result.Error == nil
+	}
+
+	return results, nil
+}
+
+// BulkDelete deletes every todo in ids the user owns within one transaction.
+// A todo that can't be deleted is reported in its result without affecting
+// the others.
+func (ti *todoInteractor) BulkDelete(ids []string, userId string) ([]*model.BulkTodoResult, error) {
+	if len(ids) > ti.settings.MaxPageSize {
+		return nil, models.ErrTodoBulkTooLarge
+	}
+	actor := uuid.MustParse(userId)
+
+	var results []*model.BulkTodoResult
+	err := ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		var todos []*models.Todo
+		var accesses []*models.TodoAccess
+		var err error
+		results, todos, accesses, err = ti.bulkLookup(tx, ids, models.TodoRoleOwner, userId)
+		if err != nil {
+			return err
+		}
+
+		for i, todo := range todos {
+			if todo == nil {
+				continue
+			}
+			repo := ti.in(accesses[i].WorkspaceID).TodoRepository.WithTx(tx)
+
+			// A todo already gone with an earlier one in the batch counts as deleted.
+			deleted, err := repo.Delete(todo.ID.String(), accesses[i].OwnerID.String(), ti.settings.DeleteCascade)
+			var gqlErr *gqlerror.Error
+			if errors.As(err, &gqlErr) {
+				bulkFailure(results[i], gqlErr)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if deleted {
+				if err := ti.record(tx, models.NewTodoEvent(todo.ID, actor, models.TodoEventDeleted)); err != nil {
+					return err
+				}
+			}
+
+			results[i].Success = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// bulkLookup decides the role of the user on every todo in ids in one query,
+// then loads the todos with one query per tenant they belong to, and prepares
+// a result per id. Ids the user has no access to or not the needed role for
+// are marked as failed and get a nil todo.
+func (ti *todoInteractor) bulkLookup(tx *gorm.DB, ids []string, need models.TodoRole, userId string) ([]*model.BulkTodoResult, []*models.Todo, []*models.TodoAccess, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if parsed, err := uuid.Parse(id); err == nil {
+			valid = append(valid, parsed.String())
+		}
+	}
+
+	found, err := ti.ShareRepository.WithTx(tx).AccessMany(valid, userId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	results := make([]*model.BulkTodoResult, len(ids))
+	todos := make([]*models.Todo, len(ids))
+	accesses := make([]*models.TodoAccess, len(ids))
+	tenants := make([]*bulkTenant, 0)
+	for i, id := range ids {
+		results[i] = &model.BulkTodoResult{ID: id}
+
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			bulkFailure(results[i], models.ErrTodoNotFound)
+			continue
+		}
+
+		access, err := authorized(found[parsed.String()], userId, need)
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			bulkFailure(results[i], models.ErrTodoNotFound)
+			continue
+		case err == models.ErrTodoForbidden:
+			bulkFailure(results[i], models.ErrTodoForbidden)
+			continue
+		case err != nil:
+			return nil, nil, nil, err
+		}
+		accesses[i] = access
+
+		tenant := findTenant(tenants, access)
+		if tenant == nil {
+			tenant = &bulkTenant{access: access}
+			tenants = append(tenants, tenant)
+		}
+		tenant.ids = append(tenant.ids, parsed.String())
+	}
+
+	loaded := make(map[uuid.UUID]*models.Todo, len(valid))
+	for _, tenant := range tenants {
+		listed, err := ti.in(tenant.access.WorkspaceID).TodoRepository.WithTx(tx).ListByIDs(tenant.ids, tenant.access.OwnerID.String())
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, todo := range listed {
+			loaded[todo.ID] = todo
+		}
+	}
+
+	for i, id := range ids {
+		if accesses[i] == nil {
+			continue
+		}
+
+		todos[i] = loaded[uuid.MustParse(id)]
+		if todos[i] == nil {
+			accesses[i] = nil
+			bulkFailure(results[i], models.ErrTodoNotFound)
+		}
+	}
+
+	return results, todos, accesses, nil
+}
+
+// bulkTenant holds the todos of a bulk change that belong to one tenant.
+type bulkTenant struct {
+	access *models.TodoAccess
+	todos  []*models.Todo
+	ids    []string
+}
+
+// bulkTenants groups the todos of a bulk change by their tenant, each todo
+// once and in the order of ids.
+func bulkTenants(todos []*models.Todo, accesses []*models.TodoAccess) []*bulkTenant {
+	tenants := make([]*bulkTenant, 0)
+	seen := make(map[uuid.UUID]bool, len(todos))
+	for i, todo := range todos {
+		if todo == nil || seen[todo.ID] {
+			continue
+		}
+		seen[todo.ID] = true
+
+		tenant := findTenant(tenants, accesses[i])
+		if tenant == nil {
+			tenant = &bulkTenant{access: accesses[i]}
+			tenants = append(tenants, tenant)
+		}
+
+		tenant.todos = append(tenant.todos, todo)
+		tenant.ids = append(tenant.ids, todo.ID.String())
+	}
+
+	return tenants
+}
+
+func findTenant(tenants []*bulkTenant, access *models.TodoAccess) *bulkTenant {
+	for _, tenant := range tenants {
+		if sameTenant(tenant.access, access) {
+			return tenant
+		}
+	}
+	return nil
+}
+
+// refuseBlocked marks the open todos of a bulk update that are blocked by
+// open todos as failed and drops them from todos.
+func (ti *todoInteractor) refuseBlocked(tx *gorm.DB, results []*model.BulkTodoResult, todos []*models.Todo) error {
+	open := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		if todo != nil && !todo.Done {
+			open = append(open, todo.ID.String())
+		}
+	}
+
+	if len(open) == 0 {
+		return nil
+	}
+
+	blocked, err := ti.DependencyRepository.WithTx(tx).ListOpenlyBlocked(open)
+	if err != nil {
+		return err
+	}
+
+	refused := make(map[uuid.UUID]bool, len(blocked))
+	for _, id := range blocked {
+		refused[id] = true
+	}
+
+	for i, todo := range todos {
+		if todo != nil && !todo.Done && refused[todo.ID] {
+			bulkFailure(results[i], models.ErrTodoBlocked)
+			todos[i] = nil
+		}
+	}
+
+	return nil
+}
+
+func bulkFailure(result *model.BulkTodoResult, err *gqlerror.Error) {
+	message := err.Message
+	result.Success = false
+	result.Error = &message
+}
+
+// minPositionGap is the closest two neighbours may get in the manual order
+// before the positions of the user are rebalanced.
+const minPositionGap = 1e-6
+
+// Move places the todo after afterId and before beforeId in the manual order
+// of its owner, for anyone who may edit it. Only the moved todo is updated,
+// unless its new neighbours are too close together and the order has to be
+// rebalanced first.
+func (ti *todoInteractor) Move(id string, beforeId *string, afterId *string, userId string) (*models.Todo, error) {
+	if beforeId == nil && afterId == nil {
+		return nil, models.ErrTodoMoveTarget
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrTodoNotFound
+	}
+	access, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleEditor)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return nil, err
+	}
+	actor := uuid.MustParse(userId)
+	ti, userId = ti.in(access.WorkspaceID), access.OwnerID.String()
+
+	var moved *models.Todo
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		repo := ti.TodoRepository.WithTx(tx)
+
+		todo, err := repo.GetByID(id, userId)
+		if err != nil {
+			return err
+		}
+
+		lower, upper, err := moveBounds(repo, id, beforeId, afterId, userId)
+		if err != nil {
+			return err
+		}
+
+		if lower > upper {
+			return models.ErrTodoMoveInvalid
+		}
+
+		rebalanced := upper-lower < minPositionGap
+		if rebalanced {
+			if err := repo.Rebalance(userId); err != nil {
+				return err
+			}
+
+			if lower, upper, err = moveBounds(repo, id, beforeId, afterId, userId); err != nil {
+				return err
+			}
+		}
+
+		if lower >= upper {
+			return models.ErrTodoMoveInvalid
+		}
+
+		if err := repo.Update(id, userId, map[string]interface{}{"position": lower + (upper-lower)/2}); err != nil {
+			return err
+		}
+
+		if moved, err = ti.recordChanges(tx, todo, models.TodoEventMoved, actor); err != nil {
+			return err
+		}
+
+		// The old position is meaningless once the order has been rebalanced.
+		if rebalanced {
+			return nil
+		}
+
+		moved.UndoToken, err = ti.undoable(tx, moved, models.TodoEventMoved, actor,
+			models.TodoUndoState{Fields: map[string]interface{}{"position": todo.Position}})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// moveBounds returns the positions a todo moved between the given neighbours
+// has to fit between. A missing neighbour is looked up from the other one.
+func moveBounds(repo repository.TodoRepository, id string, beforeId *string, afterId *string, userId string) (float64, float64, error) {
+	neighbour := func(neighbourId *string) (*models.Todo, error) {
+		if neighbourId == nil {
+			return nil, nil
+		}
+
+		if _, err := uuid.Parse(*neighbourId); err != nil || *neighbourId == id {
+			return nil, models.ErrTodoNotFound
+		}
+
+		todo, err := repo.GetByID(*neighbourId, userId)
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotFound
+		}
+		return todo, err
+	}
+
+	before, err := neighbour(beforeId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	after, err := neighbour(afterId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if after == nil {
+		if after, err = repo.Adjacent(before, false, id, userId); err != nil {
+			return 0, 0, err
+		}
+	} else if before == nil {
+		if before, err = repo.Adjacent(after, true, id, userId); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	switch {
+	case after == nil:
+		return before.Position - models.TodoPositionStep, before.Position, nil
+	case before == nil:
+		return after.Position, after.Position + models.TodoPositionStep, nil
+	default:
+		return after.Position, before.Position, nil
+	}
+}
+
+func (ti *todoInteractor) Trashed(userId string) ([]*models.Todo, error) {
+	return ti.TodoRepository.ListTrashed(userId)
+}
+
+// Restore takes a todo out of the trash for the users who may delete it.
+func (ti *todoInteractor) Restore(id string, userId string) (*models.Todo, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrTodoNotInTrash
+	}
+	access, err := authorizeTrashed(ti.ShareRepository, id, userId, models.TodoRoleOwner)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTodoNotInTrash
+		}
+		return nil, err
+	}
+	actor := uuid.MustParse(userId)
+	ti, userId = ti.in(access.WorkspaceID), access.OwnerID.String()
+
+	var todo *models.Todo
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.TodoRepository.WithTx(tx)
+
+		restored, err := todos.Restore(id, userId)
+		if err != nil || !restored {
+			return err
+		}
+
+		if err := ti.record(tx, models.NewTodoEvent(uuid.MustParse(id), actor, models.TodoEventRestored)); err != nil {
+			return err
+		}
+
+		if todo, err = todos.GetByID(id, userId); err != nil {
+			return err
+		}
+
+		todo.UndoToken, err = ti.undoable(tx, todo, models.TodoEventRestored, actor, models.TodoUndoState{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if todo == nil {
+		return nil, models.ErrTodoNotInTrash
+	}
+
+	return todo, nil
+}
+
+// EmptyTrash permanently deletes the todos in the trash of the tenant. In a
+// workspace only admins empty the whole trash, other members only the todos
+// they created.
+func (ti *todoInteractor) EmptyTrash(userId string) (int, error) {
+	own := ti.member != nil && !ti.member.Role.Allows(models.WorkspaceRoleAdmin)
+	count, err := ti.TodoRepository.EmptyTrash(userId, own)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// PurgeTrash permanently deletes the todos that have been in the trash for
+// longer than the configured retention.
+func (ti *todoInteractor) PurgeTrash() (int64, error) {
+	if _, err := ti.TodoUndoRepository.DeleteExpired(time.Now()); err != nil {
+		return 0, err
+	}
+
+	return ti.TodoRepository.Purge(time.Now().Add(-ti.settings.TrashRetention))
+}
+
+func (ti *todoInteractor) GetByID(id string, userId string) (*models.Todo, error) {
+	access, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return ti.in(access.WorkspaceID).TodoRepository.GetByID(id, access.OwnerID.String())
+}
+
+func (ti *todoInteractor) List(userId string) ([]*models.Todo, error) {
+	return ti.TodoRepository.List(userId)
+}
+
+func (ti *todoInteractor) Paginate(page models.PageArgs, filter *model.TodoFilter, order []*model.TodoOrder, userId string) (*models.TodoConnection, error) {
+	if err := normalizePage(&page, ti.settings); err != nil {
+		return nil, err
+	}
+
+	return ti.TodoRepository.Paginate(page, filter, order, userId)
+}
+
+// normalizePage validates the connection arguments, clamps the page size to
+// the configured maximum and applies the default size when none is given.
+func normalizePage(page *models.PageArgs, settings models.TodoSettings) error {
+	if page.First != nil && page.Last != nil {
+		return models.ErrPageArgsInvalid
+	}
+
+	for _, size := range []*int{page.First, page.Last} {
+		if size == nil {
+			continue
+		}
+
+		if *size < 0 {
+			return models.ErrPageArgsInvalid
+		}
+
+		if *size > settings.MaxPageSize {
+			*size = settings.MaxPageSize
+		}
+	}
+
+	if page.First == nil && page.Last == nil {
+		size := settings.PageSize
+		page.First = &size
+	}
+
+	return nil
+}
+
+func (ti *todoInteractor) Search(query string, limit *int, userId string) ([]*models.TodoSearchResult, error) {
+	size := ti.settings.PageSize
+	if limit != nil {
+		if *limit < 0 {
+			return nil, models.ErrPageArgsInvalid
+		}
+		size = *limit
+	}
+
+	if size > ti.settings.MaxPageSize {
+		size = ti.settings.MaxPageSize
+	}
+
+	return ti.TodoRepository.Search(query, size, userId)
+}
+
+func (ti *todoInteractor) ListChildren(parentId string, userId string) ([]*models.Todo, error) {
+	access, err := authorizeTodo(ti.ShareRepository, parentId, userId, models.TodoRoleViewer)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return []*models.Todo{}, nil
+		}
+		return nil, err
+	}
+
+	return ti.in(access.WorkspaceID).TodoRepository.ListChildren(parentId, access.OwnerID.String())
+}
+
+func (ti *todoInteractor) Tree(rootId *string, userId string) ([]*models.Todo, error) {
+	if rootId != nil {
+		access, err := authorizeTodo(ti.ShareRepository, *rootId, userId, models.TodoRoleViewer)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return []*models.Todo{}, nil
+			}
+			return nil, err
+		}
+		ti, userId = ti.in(access.WorkspaceID), access.OwnerID.String()
+	}
+
+	todos, err := ti.TodoRepository.ListTree(rootId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildTodoTree(todos), nil
+}
+
+// Progress returns the progress of each of the todos the user can see, by
+// id, with one query for the subtrees of all the todos of a tenant.
+func (ti *todoInteractor) Progress(todoIds []string, userId string) (map[string]float64, error) {
+	_, tenants, err := ti.viewable(todoIds, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[string]float64, len(todoIds))
+	for _, tenant := range tenants {
+		todos, err := ti.in(tenant.access.WorkspaceID).TodoRepository.ListSubtrees(tenant.ids, tenant.access.OwnerID.String())
+		if err != nil {
+			return nil, err
+		}
+
+		byID := make(map[string]*models.Todo, len(todos))
+		for _, todo := range todos {
+			byID[todo.ID.String()] = todo
+		}
+		models.BuildTodoTree(todos)
+
+		for _, id := range tenant.ids {
+			if todo := byID[id]; todo != nil {
+				progress[id] = todo.Progress()
+			}
+		}
+	}
+
+	return progress, nil
+}
+
+// Roles returns what the user may do with each of the todos they can see, by
+// id.
+func (ti *todoInteractor) Roles(todoIds []string, userId string) (map[string]models.TodoRole, error) {
+	accesses, _, err := ti.viewable(todoIds, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]models.TodoRole, len(accesses))
+	for id, access := range accesses {
+		roles[id] = access.Role
+	}
+
+	return roles, nil
+}
+
+// viewable returns the access of the user to each of the todos they can see,
+// by id, along with those todos grouped by tenant. The todos the user can't
+// see are left out.
+func (ti *todoInteractor) viewable(todoIds []string, userId string) (map[string]*models.TodoAccess, []*bulkTenant, error) {
+	valid := make([]string, 0, len(todoIds))
+	for _, id := range todoIds {
+		if parsed, err := uuid.Parse(id); err == nil {
+			valid = append(valid, parsed.String())
+		}
+	}
+
+	found, err := ti.ShareRepository.AccessMany(valid, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accesses := make(map[string]*models.TodoAccess, len(found))
+	tenants := make([]*bulkTenant, 0)
+	for _, id := range valid {
+		if accesses[id] != nil {
+			continue
+		}
+
+		access, err := authorized(found[id], userId, models.TodoRoleViewer)
+		if err != nil {
+			continue
+		}
+		accesses[id] = access
+
+		tenant := findTenant(tenants, access)
+		if tenant == nil {
+			tenant = &bulkTenant{access: access}
+			tenants = append(tenants, tenant)
+		}
+		tenant.ids = append(tenant.ids, id)
+	}
+
+	return accesses, tenants, nil
+}
+
+// Role returns what the user may do with a todo they can see.
+func (ti *todoInteractor) Role(todo *models.Todo, userId string) (models.TodoRole, error) {
+	if todo.WorkspaceID == nil && todo.UserID.String() == userId {
+		return models.TodoRoleOwner, nil
+	}
+
+	access, err := authorizeTodo(ti.ShareRepository, todo.ID.String(), userId, models.TodoRoleViewer)
+	if err != nil {
+		return "", err
+	}
+
+	return access.Role, nil
+}
+
+// History returns the changes made to a todo, oldest first.
+func (ti *todoInteractor) History(id string, userId string) ([]*models.TodoEvent, error) {
+	if _, err := authorizeTodo(ti.ShareRepository, id, userId, models.TodoRoleViewer); err != nil {
+		return nil, err
+	}
+
+	return ti.TodoEventRepository.ListByTodo(id)
+}
+
+// record adds events to the history in the transaction of the change they
+// describe, so that the history can't drift from the todos.
+func (ti *todoInteractor) record(tx *gorm.DB, events ...*models.TodoEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return ti.TodoEventRepository.WithTx(tx).Create(events)
+}
+
+// recordChanges records the fields of a todo changed within the transaction
+// by comparing before with the todo as it is now, which it returns.
+func (ti *todoInteractor) recordChanges(tx *gorm.DB, before *models.Todo, action models.TodoEventAction, actor uuid.UUID) (*models.Todo, error) {
+	after, err := ti.TodoRepository.WithTx(tx).GetByID(before.ID.String(), before.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ti.record(tx, models.TodoChanges(action, actor, before, after)...); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// undoable keeps what it takes to revert a change for the undo window and
+// returns the token for it. todo is the todo as the change left it, or as it
+// was before it was deleted.
+func (ti *todoInteractor) undoable(tx *gorm.DB, todo *models.Todo, action models.TodoEventAction, actor uuid.UUID, state models.TodoUndoState) (string, error) {
+	undo := &models.TodoUndo{
+		ID:          uuid.New(),
+		TodoID:      todo.ID,
+		UserID:      actor,
+		Action:      action,
+		OwnerID:     todo.UserID,
+		WorkspaceID: todo.WorkspaceID,
+		Updated:     todo.Updated,
+		State:       state,
+		Expires:     time.Now().Add(ti.settings.UndoWindow),
+	}
+
+	if err := ti.TodoUndoRepository.WithTx(tx).Create(undo); err != nil {
+		return "", err
+	}
+
+	return undo.ID.String(), nil
+}
+
+// Undo reverts the change the token was handed out for and returns the todo
+// as it is afterwards, or nil when the change created or restored it. A todo that has
+// been changed since is left alone, so that undo never loses a later change.
+func (ti *todoInteractor) Undo(token string, userId string) (*models.Todo, error) {
+	if _, err := uuid.Parse(token); err != nil {
+		return nil, models.ErrUndoNotFound
+	}
+
+	undo, err := ti.TodoUndoRepository.Get(token, userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrUndoNotFound
+		}
+		return nil, err
+	}
+
+	ti, owner := ti.in(undo.WorkspaceID), undo.OwnerID.String()
+	actor, id := undo.UserID, undo.TodoID.String()
+
+	var todo *models.Todo
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.TodoRepository.WithTx(tx)
+
+		current, err := undoTarget(todos, undo)
+		if err != nil {
+			return err
+		}
+
+		if current == nil || !current.Updated.Equal(undo.Updated) {
+			return models.ErrUndoConflict
+		}
+
+		switch undo.Action {
+		case models.TodoEventCreated:
+			if _, err := todos.Delete(id, owner, ti.settings.DeleteCascade); err != nil {
+				return err
+			}
+
+			err = ti.record(tx, models.NewTodoEvent(undo.TodoID, actor, models.TodoEventDeleted))
+
+		case models.TodoEventDeleted:
+			if _, err := todos.Restore(id, owner); err != nil {
+				return err
+			}
+
+			err = ti.record(tx, models.NewTodoEvent(undo.TodoID, actor, models.TodoEventRestored))
+
+		case models.TodoEventRestored:
+			if _, err := todos.Delete(id, owner, ti.settings.DeleteCascade); err != nil {
+				return err
+			}
+
+			err = ti.record(tx, models.NewTodoEvent(undo.TodoID, actor, models.TodoEventDeleted))
+
+		default:
+			err = ti.revert(tx, todos, current, undo)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := ti.TodoUndoRepository.WithTx(tx).Delete(token); err != nil {
+			return err
+		}
+
+		if undo.Action == models.TodoEventCreated || undo.Action == models.TodoEventRestored {
+			return nil
+		}
+
+		todo, err = todos.GetByID(id, owner)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// revert undoes an edit, a completion, a move or an assignment of current: it
+// puts back the fields the change overwrote, reopens the todos it completed
+// and removes the occurrence it scheduled.
+func (ti *todoInteractor) revert(tx *gorm.DB, todos repository.TodoRepository, current *models.Todo, undo *models.TodoUndo) error {
+	owner := undo.OwnerID.String()
+
+	if len(undo.State.Fields) > 0 {
+		if err := todos.Update(undo.TodoID.String(), owner, undo.State.Fields); err != nil {
+			return err
+		}
+	}
+
+	reopen := make([]string, len(undo.State.Completed))
+	for i, completed := range undo.State.Completed {
+		reopen[i] = completed.String()
+	}
+
+	if len(reopen) > 0 {
+		if err := todos.UpdateMany(reopen, owner, map[string]interface{}{"done": false, "completed_at": nil}); err != nil {
+			return err
+		}
+	}
+
+	if undo.State.Next != nil {
+		if _, err := todos.Delete(undo.State.Next.String(), owner, models.CascadeChildren); err != nil {
+			return err
+		}
+
+		if err := ti.record(tx, models.NewTodoEvent(*undo.State.Next, undo.UserID, models.TodoEventDeleted)); err != nil {
+			return err
+		}
+	}
+
+	action := undo.Action
+	if action == models.TodoEventCompleted {
+		action = models.TodoEventUpdated
+	}
+
+	_, err := ti.recordChanges(tx, current, action, undo.UserID)
+	return err
+}
+
+// undoTarget loads the todo an undo is for, from the trash when the change
+// deleted it. It returns nil when the todo isn't where the change left it.
+func undoTarget(todos repository.TodoRepository, undo *models.TodoUndo) (*models.Todo, error) {
+	owner := undo.OwnerID.String()
+
+	if undo.Action != models.TodoEventDeleted {
+		todo, err := todos.GetByID(undo.TodoID.String(), owner)
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return todo, err
+	}
+
+	trashed, err := todos.ListTrashed(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, todo := range trashed {
+		if todo.ID == undo.TodoID {
+			return todo, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// nextOccurrence builds the open todo that follows a completed occurrence of
+// a recurring series, or nil once the series is over. The rule is expanded in
+// the time zone of the owner so due times keep their wall-clock time across DST.
+func nextOccurrence(todo *models.Todo) (*models.Todo, error) {
+	if todo.SeriesStart == nil || todo.OccurrenceAt == nil {
+		return nil, nil
+	}
+
+	at, ok, err := recurrence.Next(todo.RRule, *todo.SeriesStart, *todo.OccurrenceAt, todo.User.Location())
+	if err != nil {
+		return nil, models.ErrTodoInvalidRRule
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	return &models.Todo{
+		ID:           uuid.New(),
+		Text:         todo.Text,
+		UserID:       todo.UserID,
+		AssigneeID:   todo.AssigneeID,
+		ParentID:     todo.ParentID,
+		DueAt:        &at,
+		RRule:        todo.RRule,
+		SeriesID:     todo.SeriesID,
+		SeriesStart:  todo.SeriesStart,
+		OccurrenceAt: &at,
+		Position:     todo.Position,
+		Priority:     todo.Priority,
+		Tags:         todo.Tags,
+		Created:      time.Now(),
+
+		SearchLanguage: todo.SearchLanguage,
+	}, nil
 }
@@ -0,0 +1,319 @@
+package interactor
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"todo-service/src/models"
+	"todo-service/utils"
+	"todo-service/utils/recurrence"
+	"todo-service/utils/todotxt"
+
+	"github.com/google/uuid"
+)
+
+// todoTxtPriorities are the priorities todos are exported with. On import B
+// is medium and every letter after it is low. A letter after C is kept in the
+// text of the todo as pri:, so that it is exported as it was imported.
+var todoTxtPriorities = map[models.TodoPriority]string{
+	models.TodoPriorityHigh:   "A",
+	models.TodoPriorityMedium: "B",
+	models.TodoPriorityLow:    "C",
+}
+
+// Extensions of a todo.txt line that are read into fields of a todo and left
+// out of its text. Done todos keep their priority as pri:, since a done line
+// has no priority of its own. id: and p: link a line to its parent line, rec:
+// repeats like 1d, 2w, 1m, 1y or 1b for business days, and rrule: holds the
+// rules rec: can't express.
+const (
+	todoTxtID       = "id"
+	todoTxtParent   = "p"
+	todoTxtPriority = "pri"
+	todoTxtDue      = "due"
+	todoTxtRec      = "rec"
+	todoTxtRRule    = "rrule"
+)
+
+var todoTxtFields = []string{todoTxtID, todoTxtParent, todoTxtPriority, todoTxtDue, todoTxtRec, todoTxtRRule}
+
+// todoTxtDueTime is the format of a due: that isn't at midnight. The time has
+// no colon, since the value of an extension can't hold one.
+const todoTxtDueTime = "2006-01-02T1504"
+
+// todoTxtFrequencies are the units of rec: by the FREQ of a rule.
+var todoTxtFrequencies = map[string]string{"DAILY": "d", "WEEKLY": "w", "MONTHLY": "m", "YEARLY": "y"}
+
+// todoTxtUnits are the other way around.
+var todoTxtUnits = map[string]string{"d": "DAILY", "w": "WEEKLY", "m": "MONTHLY", "y": "YEARLY"}
+
+// todoTxtBusinessDays is the rule of rec:1b.
+const todoTxtBusinessDays = "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR"
+
+var todoTxtRecPattern = regexp.MustCompile(`^\+?([1-9][0-9]{0,2})?([dwmyb])$`)
+
+// exportTodoTxt writes the todos of the list as todo.txt lines, with dates in
+// the time zone of the user. Tags the text doesn't mention yet are added as
+// +project, or as @context when they start with @.
+func (ti *todoInteractor) exportTodoTxt(w io.Writer, userId string) error {
+	user, err := ti.UserRepository.GetByID(userId)
+	if err != nil {
+		return err
+	}
+	loc := user.Location()
+
+	parents := make(map[uuid.UUID]bool)
+	err = ti.TodoRepository.Each(nil, userId, func(todo *models.Todo) error {
+		if todo.ParentID != nil {
+			parents[*todo.ParentID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	out := todotxt.NewWriter(w)
+	err = ti.TodoRepository.Each(nil, userId, func(todo *models.Todo) error {
+		return out.Write(todoTxtTask(todo, parents[todo.ID], loc))
+	})
+	if err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// todoTxtTask turns a todo into a todo.txt line. A done todo always gets a
+// completion date so that its text can't be taken for one. The pri: a low
+// todo kept in its text is its priority again, unless it was changed since.
+func todoTxtTask(todo *models.Todo, parent bool, loc *time.Location) *todotxt.Task {
+	created := todo.Created.In(loc)
+	task := &todotxt.Task{Done: todo.Done, Created: &created}
+
+	text := todo.Text
+	mentioned := &todotxt.Task{Description: text}
+	priority, prioritized := todoTxtPriorities[todo.Priority]
+	if value, ok := mentioned.Extension(todoTxtPriority); ok {
+		text = mentioned.Without(todoTxtPriority)
+		if todo.Priority == models.TodoPriorityLow && todoTxtLetter(value) {
+			priority = value
+		}
+	}
+
+	words := []string{text}
+	projects, contexts := lowered(mentioned.Projects()), lowered(mentioned.Contexts())
+	for _, tag := range todo.Tags {
+		tag = strings.Join(strings.Fields(tag), "_")
+		switch {
+		case strings.HasPrefix(tag, "@"):
+			if !contexts[tag[1:]] {
+				words = append(words, tag)
+			}
+		case !projects[tag]:
+			words = append(words, "+"+tag)
+		}
+	}
+
+	if prioritized {
+		if todo.Done {
+			words = append(words, todoTxtPriority+":"+priority)
+		} else {
+			task.Priority = priority
+		}
+	}
+
+	if todo.Done {
+		completed := todo.Updated
+		if todo.CompletedAt != nil {
+			completed = *todo.CompletedAt
+		}
+		completed = completed.In(loc)
+		task.Completed = &completed
+	}
+
+	if todo.DueAt != nil {
+		due := todo.DueAt.In(loc)
+		format := todotxt.DateFormat
+		if !due.Equal(todoTxtDate(due, loc)) {
+			format = todoTxtDueTime
+		}
+		words = append(words, todoTxtDue+":"+due.Format(format))
+	}
+	if todo.RRule != "" {
+		words = append(words, todoTxtRecurrence(todo.RRule))
+	}
+
+	if parent {
+		words = append(words, todoTxtID+":"+todo.ID.String())
+	}
+	if todo.ParentID != nil {
+		words = append(words, todoTxtParent+":"+todo.ParentID.String())
+	}
+
+	task.Description = strings.Join(words, " ")
+	return task
+}
+
+// todoTxtRecurrence writes a rule as rec: when it only repeats every so many
+// days, weeks, months or years, or on business days, and as rrule: otherwise.
+func todoTxtRecurrence(rule string) string {
+	if rule == todoTxtBusinessDays {
+		return todoTxtRec + ":1b"
+	}
+
+	var unit string
+	interval := "1"
+	for _, part := range strings.Split(rule, ";") {
+		pair := strings.SplitN(part, "=", 2)
+		switch {
+		case len(pair) == 2 && pair[0] == "FREQ" && todoTxtFrequencies[pair[1]] != "":
+			unit = todoTxtFrequencies[pair[1]]
+		case len(pair) == 2 && pair[0] == "INTERVAL":
+			interval = pair[1]
+		default:
+			return todoTxtRRule + ":" + rule
+		}
+	}
+	if unit == "" {
+		return todoTxtRRule + ":" + rule
+	}
+
+	return todoTxtRec + ":" + interval + unit
+}
+
+// decodeTodoTxtRow reads a todo.txt line into the input of a new todo and
+// notes every value that doesn't fit, like decodeImportRow. The text is the
+// description without the extensions that became fields, projects and
+// contexts stay part of it and become tags as well, contexts with their @.
+func decodeTodoTxtRow(n int, task *todotxt.Task, loc *time.Location) *importRow {
+	row := &importRow{row: n, errors: make([]string, 0)}
+	row.id, _ = task.Extension(todoTxtID)
+	row.parentId, _ = task.Extension(todoTxtParent)
+
+	tags := task.Projects()
+	for _, context := range task.Contexts() {
+		tags = append(tags, "@"+context)
+	}
+
+	letter := task.Priority
+	if value, ok := task.Extension(todoTxtPriority); ok && letter == "" {
+		if todoTxtLetter(value) {
+			letter = value
+		} else {
+			row.fail("Parameters incorrectly formatted or out of range (pri)")
+		}
+	}
+
+	text := strings.TrimSpace(task.Without(todoTxtFields...))
+	if text != "" && letter > todoTxtPriorities[models.TodoPriorityLow] {
+		text += " " + todoTxtPriority + ":" + letter
+	}
+
+	checked := importedTodo{
+		Text: text,
+		Tags: models.NormalizeTags(tags),
+	}
+	if err := utils.Validate(checked); err != nil {
+		row.fail(err.Error())
+	}
+	row.input.Text = checked.Text
+	row.input.Tags = checked.Tags
+
+	if letter != "" {
+		priority := models.TodoPriorityLow
+		switch letter {
+		case "A":
+			priority = models.TodoPriorityHigh
+		case "B":
+			priority = models.TodoPriorityMedium
+		}
+		row.input.Priority = &priority
+	}
+
+	row.done = task.Done
+	if task.Done && task.Completed != nil {
+		completed := todoTxtDate(*task.Completed, loc)
+		row.completedAt = &completed
+	}
+	if task.Created != nil {
+		created := todoTxtDate(*task.Created, loc)
+		row.createdAt = &created
+	}
+
+	if due, ok := task.Extension(todoTxtDue); ok {
+		parsed, err := time.ParseInLocation(todotxt.DateFormat, due, loc)
+		if err != nil {
+			parsed, err = time.ParseInLocation(todoTxtDueTime, due, loc)
+		}
+		if err != nil {
+			row.fail("Parameters incorrectly formatted or out of range (due)")
+		} else {
+			row.input.DueAt = &parsed
+		}
+	}
+
+	rule, _ := task.Extension(todoTxtRRule)
+	if rec, ok := task.Extension(todoTxtRec); ok {
+		rule = todoTxtRule(rec)
+		if rule == "" {
+			row.fail(models.ErrTodoInvalidRRule.Message)
+		}
+	}
+	if rule != "" {
+		normalized, err := recurrence.Normalize(rule)
+		if err != nil {
+			row.fail(models.ErrTodoInvalidRRule.Message)
+		} else if row.input.DueAt == nil {
+			row.fail(models.ErrTodoRRuleNeedsDue.Message)
+		} else {
+			row.input.Rrule = &normalized
+		}
+	}
+
+	return row
+}
+
+// todoTxtRule turns a rec: value into a rule, or returns an empty string if
+// it isn't one. The + of a strict rec: is dropped, since todos repeat from
+// their due date anyway.
+func todoTxtRule(rec string) string {
+	match := todoTxtRecPattern.FindStringSubmatch(rec)
+	if match == nil {
+		return ""
+	}
+
+	if match[2] == "b" {
+		if match[1] != "" && match[1] != "1" {
+			return ""
+		}
+		return todoTxtBusinessDays
+	}
+
+	rule := "FREQ=" + todoTxtUnits[match[2]]
+	if match[1] != "" && match[1] != "1" {
+		rule += ";INTERVAL=" + match[1]
+	}
+
+	return rule
+}
+
+// todoTxtLetter reports whether a value is a priority, an uppercase letter.
+func todoTxtLetter(value string) bool {
+	return len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z'
+}
+
+// todoTxtDate is the start of the day of a todo.txt date in a time zone.
+func todoTxtDate(date time.Time, loc *time.Location) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+func lowered(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[strings.ToLower(word)] = true
+	}
+	return set
+}
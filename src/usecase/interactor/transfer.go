@@ -0,0 +1,516 @@
+package interactor
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/repository"
+	"todo-service/utils"
+	"todo-service/utils/ical"
+	"todo-service/utils/recurrence"
+	"todo-service/utils/todotxt"
+	"todo-service/utils/transfer"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// importColumns are the columns an import maps when it is not given a
+// mapping, the columns of an export.
+var importColumns = map[models.TodoField]string{
+	models.TodoFieldID:       transfer.ColumnID,
+	models.TodoFieldParentID: transfer.ColumnParentID,
+	models.TodoFieldText:     transfer.ColumnText,
+	models.TodoFieldDone:     transfer.ColumnDone,
+	models.TodoFieldDueAt:    transfer.ColumnDueAt,
+	models.TodoFieldPriority: transfer.ColumnPriority,
+	models.TodoFieldTags:     transfer.ColumnTags,
+	models.TodoFieldRRule:    transfer.ColumnRRule,
+}
+
+// importDateLayouts are the layouts due dates are read with, besides
+// RFC 3339. Dates without a zone are in the time zone of the user.
+var importDateLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// importedTodo holds the values of a row that are checked like the input of
+// a mutation.
+type importedTodo struct {
+	Text     string   `validate:"required,max=255"`
+	Priority string   `validate:"omitempty,oneof=NONE LOW MEDIUM HIGH"`
+	Tags     []string `validate:"omitempty,max=32,dive,max=64"`
+}
+
+// importRow is a row of an import on its way to becoming a todo.
+type importRow struct {
+	row      int
+	id       string
+	parentId string
+	input    model.NewTodo
+	done     bool
+	errors   []string
+
+	// uid is the calendar UID of a row from a calendar. A row with the UID of
+	// a todo of the list updates that todo instead of creating one.
+	uid         string
+	completedAt *time.Time
+	updated     bool
+
+	// createdAt is when the todo of a row was created, as far as the file
+	// says.
+	createdAt *time.Time
+
+	visiting bool
+	created  string
+}
+
+func (row *importRow) fail(message string) {
+	row.errors = append(row.errors, message)
+}
+
+// Export writes the todos of the list to w one at a time.
+func (ti *todoInteractor) Export(format models.TodoFileFormat, w io.Writer, userId string) error {
+	var out transfer.Writer
+	switch format {
+	case models.TodoFileFormatICS:
+		return ti.exportCalendar(w, userId)
+	case models.TodoFileFormatTodoTxt:
+		return ti.exportTodoTxt(w, userId)
+	case models.TodoFileFormatJSON:
+		out = transfer.NewJSONWriter(w)
+	case models.TodoFileFormatCSV:
+		out = transfer.NewCSVWriter(w)
+	default:
+		return models.ErrTransferFormat
+	}
+
+	err := ti.TodoRepository.Each(nil, userId, func(todo *models.Todo) error {
+		record := &transfer.Record{
+			ID:       todo.ID.String(),
+			Text:     todo.Text,
+			Done:     todo.Done,
+			DueAt:    todo.DueAt,
+			Priority: string(todo.Priority),
+			Tags:     todo.Tags,
+			RRule:    todo.RRule,
+			Created:  todo.Created,
+		}
+		if todo.ParentID != nil {
+			record.ParentID = todo.ParentID.String()
+		}
+
+		return out.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// Import creates a todo for every valid row of file in one transaction and
+// reports the rows that aren't. Rows are linked to their parent by the ids of
+// the file, so that an export can be imported again, or to a todo of the
+// list. Calendars are linked by UID instead and update the todos they were
+// exported from, mapping doesn't apply to them. A dry run checks every row
+// the same way but keeps nothing.
+func (ti *todoInteractor) Import(file io.Reader, format models.TodoFileFormat, mapping []*model.TodoColumnMapping,
+	dryRun bool, userId string) (*model.TodoImportResult, error) {
+
+	user, err := ti.UserRepository.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var next func(n int) (*importRow, error)
+	switch format {
+	case models.TodoFileFormatJSON, models.TodoFileFormatCSV:
+		var reader transfer.Reader
+		if format == models.TodoFileFormatJSON {
+			reader, err = transfer.NewJSONReader(file)
+		} else {
+			reader, err = transfer.NewCSVReader(file)
+		}
+		if err != nil {
+			return nil, models.ErrImportFile
+		}
+
+		columns := importColumns
+		if len(mapping) > 0 {
+			columns = make(map[models.TodoField]string, len(mapping))
+			for _, m := range mapping {
+				columns[m.Field] = m.Column
+			}
+		}
+		if _, ok := columns[models.TodoFieldText]; !ok {
+			return nil, models.ErrImportNoText
+		}
+
+		next = func(n int) (*importRow, error) {
+			values, err := reader.Read()
+			if err != nil {
+				return nil, err
+			}
+			return decodeImportRow(n, values, columns, user.Location()), nil
+		}
+	case models.TodoFileFormatICS:
+		decoder := ical.NewDecoder(file, user.Location())
+		next = func(n int) (*importRow, error) {
+			item, err := decoder.Next()
+			if err != nil {
+				return nil, err
+			}
+			return decodeCalendarRow(n, item), nil
+		}
+	case models.TodoFileFormatTodoTxt:
+		reader := todotxt.NewReader(file)
+		next = func(n int) (*importRow, error) {
+			task, err := reader.Read()
+			if err != nil {
+				return nil, err
+			}
+			return decodeTodoTxtRow(n, task, user.Location()), nil
+		}
+	default:
+		return nil, models.ErrTransferFormat
+	}
+
+	rows := make([]*importRow, 0)
+	byID := make(map[string]*importRow)
+	for n := 1; ; n++ {
+		row, err := next(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, models.ErrImportFile
+		}
+
+		if n > models.TodoImportMaxRows {
+			return nil, models.ErrImportTooLarge
+		}
+
+		if row.id != "" {
+			if byID[row.id] != nil {
+				row.fail(models.ErrImportDuplicated.Message)
+			} else {
+				byID[row.id] = row
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	result := &model.TodoImportResult{
+		DryRun: dryRun,
+		Errors: make([]*model.TodoImportRowError, 0),
+		Todos:  make([]*models.Todo, 0),
+	}
+
+	actor := uuid.MustParse(userId)
+	err = ti.DBRepository.Transaction(func(tx *gorm.DB) error {
+		todos := ti.TodoRepository.WithTx(tx)
+
+		// create makes the todo of a row after the todo of its parent row and
+		// returns its id, or an empty string if either of them failed.
+		var create func(row *importRow) (string, error)
+		create = func(row *importRow) (string, error) {
+			if row.created != "" || len(row.errors) > 0 {
+				return row.created, nil
+			}
+
+			if row.visiting {
+				row.fail(models.ErrImportParentRow.Message)
+				return "", nil
+			}
+			row.visiting = true
+			defer func() { row.visiting = false }()
+
+			if row.parentId != "" {
+				parentId, err := ti.importParent(todos, row, byID, create, userId)
+				if err != nil {
+					return "", err
+				}
+				if parentId == "" {
+					if len(row.errors) == 0 {
+						row.fail(models.ErrImportParentRow.Message)
+					}
+					return "", nil
+				}
+				row.input.ParentID = &parentId
+			}
+
+			id, err := ti.saveImported(tx, row, actor, userId)
+			if err != nil {
+				return "", err
+			}
+
+			row.created = id
+			return row.created, nil
+		}
+
+		roots := make([]string, 0)
+		for _, row := range rows {
+			id, err := create(row)
+			if err != nil {
+				return err
+			}
+
+			if id != "" && byID[row.parentId] == nil {
+				roots = append(roots, id)
+			}
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+
+		if len(roots) == 0 {
+			return nil
+		}
+
+		result.Todos, err = todos.ListByIDs(roots, userId)
+		return err
+	})
+	if err != nil && err != errDryRun {
+		return nil, err
+	}
+
+	sort.Slice(result.Todos, func(i, j int) bool {
+		return result.Todos[i].Position < result.Todos[j].Position
+	})
+
+	for _, row := range rows {
+		if len(row.errors) > 0 {
+			result.Failed++
+			result.Errors = append(result.Errors, &model.TodoImportRowError{Row: row.row, Errors: row.errors})
+		} else {
+			result.Imported++
+		}
+		if row.updated {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// completion is when the todo of a done row was completed: as the row says,
+// as before an update or otherwise now.
+func (row *importRow) completion(before *time.Time) time.Time {
+	switch {
+	case row.completedAt != nil:
+		return *row.completedAt
+	case before != nil:
+		return *before
+	default:
+		return time.Now()
+	}
+}
+
+// saveImported updates the todo with the calendar UID of a row, or creates a
+// todo for the row when there is none, and returns its id.
+func (ti *todoInteractor) saveImported(tx *gorm.DB, row *importRow, actor uuid.UUID, userId string) (string, error) {
+	todos := ti.TodoRepository.WithTx(tx)
+	if row.uid != "" {
+		existing, err := todos.GetByUID(row.uid, userId)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return "", err
+		}
+
+		if err == nil {
+			if err := ti.updateImported(tx, existing, row, actor); err != nil {
+				return "", err
+			}
+
+			row.updated = true
+			return existing.ID.String(), nil
+		}
+	}
+
+	todo, err := todos.Create(row.input, userId)
+	if err != nil {
+		return "", err
+	}
+
+	fields := map[string]interface{}{}
+	if row.uid != "" {
+		fields["uid"] = row.uid
+	}
+	if row.done {
+		fields["done"], fields["completed_at"] = true, row.completion(nil)
+	}
+	if row.createdAt != nil {
+		fields["created"] = *row.createdAt
+	}
+	if len(fields) > 0 {
+		if err := todos.Update(todo.ID.String(), userId, fields); err != nil {
+			return "", err
+		}
+	}
+
+	if err := ti.record(tx, models.NewTodoEvent(todo.ID, actor, models.TodoEventCreated)); err != nil {
+		return "", err
+	}
+
+	return todo.ID.String(), nil
+}
+
+// updateImported changes a todo to the values of a row with its calendar UID.
+// The todo stays where it is in the tree.
+func (ti *todoInteractor) updateImported(tx *gorm.DB, todo *models.Todo, row *importRow, actor uuid.UUID) error {
+	priority := models.TodoPriorityNone
+	if row.input.Priority != nil {
+		priority = *row.input.Priority
+	}
+
+	fields := map[string]interface{}{
+		"text":         row.input.Text,
+		"due_at":       row.input.DueAt,
+		"priority":     priority,
+		"tags":         models.NormalizeTags(row.input.Tags),
+		"done":         row.done,
+		"completed_at": nil,
+	}
+	if row.done {
+		fields["completed_at"] = row.completion(todo.CompletedAt)
+	}
+
+	var rule string
+	if row.input.Rrule != nil {
+		rule = *row.input.Rrule
+	}
+	if rule != todo.RRule {
+		fields["rrule"] = rule
+		fields["series_id"], fields["series_start"], fields["occurrence_at"] = nil, nil, nil
+		if rule != "" {
+			fields["series_id"] = uuid.New()
+			fields["series_start"], fields["occurrence_at"] = row.input.DueAt, row.input.DueAt
+		}
+	}
+
+	if err := ti.TodoRepository.WithTx(tx).Update(todo.ID.String(), todo.UserID.String(), fields); err != nil {
+		return err
+	}
+
+	_, err := ti.recordChanges(tx, todo, models.TodoEventUpdated, actor)
+	return err
+}
+
+// importParent returns the id of the parent of a row: the todo created for
+// another row of the file, or a todo of the list that isn't part of it.
+func (ti *todoInteractor) importParent(todos repository.TodoRepository, row *importRow, byID map[string]*importRow,
+	create func(row *importRow) (string, error), userId string) (string, error) {
+
+	if parent := byID[row.parentId]; parent != nil {
+		return create(parent)
+	}
+
+	if row.uid != "" {
+		parent, err := todos.GetByUID(row.parentId, userId)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return "", nil
+			}
+			return "", err
+		}
+
+		return parent.ID.String(), nil
+	}
+
+	if _, err := uuid.Parse(row.parentId); err != nil {
+		return "", nil
+	}
+
+	parent, err := todos.GetByID(row.parentId, userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return parent.ID.String(), nil
+}
+
+// decodeImportRow reads the mapped columns of a row into the input of a new
+// todo and notes every value that doesn't fit.
+func decodeImportRow(n int, values transfer.Row, columns map[models.TodoField]string, loc *time.Location) *importRow {
+	row := &importRow{row: n, errors: make([]string, 0)}
+	value := func(field models.TodoField) string {
+		column, ok := columns[field]
+		if !ok {
+			return ""
+		}
+		v, _ := values.Get(column)
+		return strings.TrimSpace(v)
+	}
+
+	row.id = value(models.TodoFieldID)
+	row.parentId = value(models.TodoFieldParentID)
+
+	checked := importedTodo{
+		Text:     value(models.TodoFieldText),
+		Priority: strings.ToUpper(value(models.TodoFieldPriority)),
+		Tags:     models.NormalizeTags(transfer.SplitTags(value(models.TodoFieldTags))),
+	}
+	if err := utils.Validate(checked); err != nil {
+		row.fail(err.Error())
+	}
+	row.input.Text = checked.Text
+	row.input.Tags = checked.Tags
+	if checked.Priority != "" {
+		priority := models.TodoPriority(checked.Priority)
+		row.input.Priority = &priority
+	}
+
+	if done := value(models.TodoFieldDone); done != "" {
+		parsed, err := strconv.ParseBool(done)
+		if err != nil {
+			row.fail("Parameters incorrectly formatted or out of range (Done)")
+		}
+		row.done = parsed
+	}
+
+	if due := value(models.TodoFieldDueAt); due != "" {
+		parsed, ok := parseImportDate(due, loc)
+		if !ok {
+			row.fail("Parameters incorrectly formatted or out of range (DueAt)")
+		} else {
+			row.input.DueAt = &parsed
+		}
+	}
+
+	if rule := value(models.TodoFieldRRule); rule != "" {
+		normalized, err := recurrence.Normalize(rule)
+		if err != nil {
+			row.fail(models.ErrTodoInvalidRRule.Message)
+		} else if row.input.DueAt == nil {
+			row.fail(models.ErrTodoRRuleNeedsDue.Message)
+		} else {
+			row.input.Rrule = &normalized
+		}
+	}
+
+	return row
+}
+
+func parseImportDate(value string, loc *time.Location) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -1,25 +1,32 @@
 package interactor
 
 import (
+	"time"
 	"todo-service/src/models"
 	"todo-service/src/usecase/presenter"
 	"todo-service/src/usecase/repository"
+
+	"gorm.io/gorm"
 )
 
 type userInteractor struct {
 	UserRepository repository.UserRepository
 	UserPresenter  presenter.UserPresenter
+	TodoRepository repository.TodoRepository
+	DBRepository   repository.DBRepository
 }
 
 type UserInteractor interface {
 	Create(user models.User) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByID(id string) (*models.User, error)
+	SetTimezone(id string, timezone string) (*models.User, error)
+	SetSearchLanguage(id string, language string) (*models.User, error)
 }
 
 func NewUserInteractor(
-	r repository.UserRepository, p presenter.UserPresenter) UserInteractor {
-	return &userInteractor{r, p}
+	r repository.UserRepository, p presenter.UserPresenter, tr repository.TodoRepository, db repository.DBRepository) UserInteractor {
+	return &userInteractor{r, p, tr, db}
 }
 
 func (ui *userInteractor) Create(user models.User) (*models.User, error) {
@@ -33,3 +40,41 @@ func (ui *userInteractor) GetByEmail(email string) (*models.User, error) {
 func (ui *userInteractor) GetByID(id string) (*models.User, error) {
 	return ui.UserRepository.GetByID(id)
 }
+
+func (ui *userInteractor) SetTimezone(id string, timezone string) (*models.User, error) {
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+		return nil, models.ErrUserTimezoneIsInvalid
+	}
+
+	if err := ui.UserRepository.UpdateTimezone(id, timezone); err != nil {
+		return nil, err
+	}
+
+	return ui.UserRepository.GetByID(id)
+}
+
+// SetSearchLanguage switches the text search configuration of the user and
+// reindexes their personal todos with it. Workspaces keep their own.
+func (ui *userInteractor) SetSearchLanguage(id string, language string) (*models.User, error) {
+	exists, err := ui.UserRepository.SearchLanguageExists(language)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, models.ErrSearchLanguageNotFound
+	}
+
+	err = ui.DBRepository.Transaction(func(tx *gorm.DB) error {
+		if err := ui.UserRepository.WithTx(tx).UpdateSearchLanguage(id, language); err != nil {
+			return err
+		}
+
+		return ui.TodoRepository.WithTx(tx).UpdateSearchLanguage(language, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ui.UserRepository.GetByID(id)
+}
@@ -0,0 +1,173 @@
+package interactor
+
+import (
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type workflowInteractor struct {
+	WorkflowRepository  repository.WorkflowRepository
+	WorkflowPresenter   presenter.WorkflowPresenter
+	WorkspaceRepository repository.WorkspaceRepository
+	DBRepository        repository.DBRepository
+}
+
+type WorkflowInteractor interface {
+	Get(workspaceId string, userId string) (models.Workflow, error)
+	Set(workspaceId string, input []*model.WorkflowStatusInput, userId string) (models.Workflow, error)
+	Status(todo *models.Todo) (*models.WorkflowStatus, error)
+}
+
+func NewWorkflowInteractor(
+	r repository.WorkflowRepository, p presenter.WorkflowPresenter, wr repository.WorkspaceRepository,
+	db repository.DBRepository) WorkflowInteractor {
+	return &workflowInteractor{r, p, wr, db}
+}
+
+// Get returns the workflow of a workspace, or of the personal todos of the
+// user when workspaceId is empty.
+func (fi *workflowInteractor) Get(workspaceId string, userId string) (models.Workflow, error) {
+	tenant, err := fi.tenant(workspaceId, userId, models.WorkspaceRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	return fi.WorkflowRepository.List(tenant, userId)
+}
+
+// Set replaces the workflow of a workspace, which takes an admin, or of the
+// personal todos of the user. Statuses are matched to the current ones by
+// name so that todos stay in them.
+func (fi *workflowInteractor) Set(workspaceId string, input []*model.WorkflowStatusInput, userId string) (models.Workflow, error) {
+	tenant, err := fi.tenant(workspaceId, userId, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := fi.WorkflowRepository.List(tenant, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := buildWorkflow(input, current, tenant, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fi.DBRepository.Transaction(func(tx *gorm.DB) error {
+		return fi.WorkflowRepository.WithTx(tx).Replace(tenant, userId, workflow)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fi.WorkflowRepository.List(tenant, userId)
+}
+
+// Status returns the status of a todo the caller can see already, or nil
+// when its tenant has no workflow.
+func (fi *workflowInteractor) Status(todo *models.Todo) (*models.WorkflowStatus, error) {
+	workflow, err := fi.WorkflowRepository.List(todo.WorkspaceID, todo.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return workflow.Current(todo), nil
+}
+
+// buildWorkflow turns the input into the statuses of a tenant, keeping the id
+// of the current status with the same name.
+func buildWorkflow(input []*model.WorkflowStatusInput, current models.Workflow, workspaceId *uuid.UUID, userId string) (models.Workflow, error) {
+	workflow := make(models.Workflow, 0, len(input))
+	var open, done bool
+	for i, in := range input {
+		if workflow.Named(in.Name) != nil {
+			return nil, models.ErrWorkflowDuplicate
+		}
+
+		status := &models.WorkflowStatus{
+			ID:       uuid.New(),
+			Name:     in.Name,
+			Category: in.Category,
+			Position: i,
+		}
+		if existing := current.Named(in.Name); existing != nil {
+			status.ID = existing.ID
+		}
+		if workspaceId != nil {
+			status.WorkspaceID = workspaceId
+		} else {
+			owner := uuid.MustParse(userId)
+			status.UserID = &owner
+		}
+
+		done = done || status.Done()
+		open = open || !status.Done()
+		workflow = append(workflow, status)
+	}
+
+	if len(workflow) == 0 {
+		return workflow, nil
+	}
+
+	if !open || !done {
+		return nil, models.ErrWorkflowInvalid
+	}
+
+	for i, in := range input {
+		from := workflow[i]
+
+		targets := in.Transitions
+		if targets == nil {
+			for _, status := range workflow {
+				targets = append(targets, status.Name)
+			}
+		}
+
+		for _, name := range targets {
+			to := workflow.Named(name)
+			if to == nil {
+				return nil, models.ErrWorkflowUnknownTarget
+			}
+
+			if to.ID != from.ID && !from.Allows(to) {
+				from.Transitions = append(from.Transitions, &models.WorkflowTransition{FromID: from.ID, ToID: to.ID})
+			}
+		}
+	}
+
+	return workflow, nil
+}
+
+// tenant resolves the workflow to work on: the one of the workspace, as long
+// as the user has the needed role in it, or the personal one when
+// workspaceId is empty.
+func (fi *workflowInteractor) tenant(workspaceId string, userId string, need models.WorkspaceRole) (*uuid.UUID, error) {
+	if workspaceId == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(workspaceId)
+	if err != nil {
+		return nil, models.ErrWorkspaceNotFound
+	}
+
+	member, err := fi.WorkspaceRepository.GetMember(id.String(), userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	if !member.Role.Allows(need) {
+		return nil, models.ErrWorkspaceForbidden
+	}
+
+	return &id, nil
+}
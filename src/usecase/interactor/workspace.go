@@ -0,0 +1,293 @@
+package interactor
+
+import (
+	"strings"
+	"todo-service/graph/model"
+	"todo-service/src/models"
+	"todo-service/src/usecase/presenter"
+	"todo-service/src/usecase/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type workspaceInteractor struct {
+	WorkspaceRepository repository.WorkspaceRepository
+	WorkspacePresenter  presenter.WorkspacePresenter
+	UserRepository      repository.UserRepository
+	DBRepository        repository.DBRepository
+	TodoRepository      repository.TodoRepository
+}
+
+type WorkspaceInteractor interface {
+	Create(input model.NewWorkspace, userId string) (*models.Workspace, error)
+	List(userId string) ([]*models.Workspace, error)
+	Members(workspaceId string, userId string) ([]*models.WorkspaceMember, error)
+	Invite(workspaceId string, email string, role models.WorkspaceRole, userId string) (*models.WorkspaceInvitation, error)
+	Invitations(userId string) ([]*models.WorkspaceInvitation, error)
+	AcceptInvitation(id string, userId string) (*models.Workspace, error)
+	SetMemberRole(workspaceId string, memberId string, role models.WorkspaceRole, userId string) (*models.WorkspaceMember, error)
+	RemoveMember(workspaceId string, memberId string, userId string) (bool, error)
+	SetSearchLanguage(workspaceId string, language string, userId string) (*models.Workspace, error)
+}
+
+func NewWorkspaceInteractor(
+	r repository.WorkspaceRepository, p presenter.WorkspacePresenter, ur repository.UserRepository,
+	db repository.DBRepository, tr repository.TodoRepository) WorkspaceInteractor {
+	return &workspaceInteractor{r, p, ur, db, tr}
+}
+
+// Create sets up a new workspace with the user as its owner.
+func (wi *workspaceInteractor) Create(input model.NewWorkspace, userId string) (*models.Workspace, error) {
+	workspace := &models.Workspace{
+		ID:   uuid.New(),
+		Name: input.Name,
+	}
+
+	owner := &models.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      uuid.MustParse(userId),
+		Role:        models.WorkspaceRoleOwner,
+	}
+
+	if err := wi.WorkspaceRepository.Create(workspace, owner); err != nil {
+		return nil, err
+	}
+
+	return wi.WorkspaceRepository.GetByID(workspace.ID.String(), userId)
+}
+
+func (wi *workspaceInteractor) List(userId string) ([]*models.Workspace, error) {
+	return wi.WorkspaceRepository.ListByUser(userId)
+}
+
+func (wi *workspaceInteractor) Members(workspaceId string, userId string) ([]*models.WorkspaceMember, error) {
+	if _, err := wi.member(workspaceId, userId, models.WorkspaceRoleMember); err != nil {
+		return nil, err
+	}
+
+	return wi.WorkspaceRepository.ListMembers(workspaceId)
+}
+
+// Invite lets the user with the given email join the workspace. Admins can
+// invite, and nobody can be invited as owner.
+func (wi *workspaceInteractor) Invite(workspaceId string, email string, role models.WorkspaceRole, userId string) (*models.WorkspaceInvitation, error) {
+	if _, err := wi.member(workspaceId, userId, models.WorkspaceRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if role != models.WorkspaceRoleAdmin && role != models.WorkspaceRoleMember {
+		return nil, models.ErrWorkspaceRoleInvalid
+	}
+
+	user, err := wi.UserRepository.GetByEmail(email)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if user != nil {
+		_, err := wi.WorkspaceRepository.GetMember(workspaceId, user.ID.String())
+		if err == nil {
+			return nil, models.ErrWorkspaceAlreadyMember
+		}
+		if err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+	}
+
+	invitation := &models.WorkspaceInvitation{
+		ID:          uuid.New(),
+		WorkspaceID: uuid.MustParse(workspaceId),
+		Email:       email,
+		Role:        role,
+		InvitedByID: uuid.MustParse(userId),
+	}
+
+	if err := wi.WorkspaceRepository.UpsertInvitation(invitation); err != nil {
+		return nil, err
+	}
+
+	return wi.WorkspaceRepository.GetInvitationByEmail(workspaceId, email)
+}
+
+// Invitations lists the open invitations for the email of the user.
+func (wi *workspaceInteractor) Invitations(userId string) ([]*models.WorkspaceInvitation, error) {
+	user, err := wi.UserRepository.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return wi.WorkspaceRepository.ListInvitationsByEmail(user.Email)
+}
+
+// AcceptInvitation adds the user to the workspace of an invitation sent to
+// their email and uses the invitation up.
+func (wi *workspaceInteractor) AcceptInvitation(id string, userId string) (*models.Workspace, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrWorkspaceInvitationNotFound
+	}
+
+	invitation, err := wi.WorkspaceRepository.GetInvitation(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrWorkspaceInvitationNotFound
+		}
+		return nil, err
+	}
+
+	user, err := wi.UserRepository.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, models.ErrWorkspaceInvitationNotFound
+	}
+
+	err = wi.DBRepository.Transaction(func(tx *gorm.DB) error {
+		workspaces := wi.WorkspaceRepository.WithTx(tx)
+
+		_, err := workspaces.GetMember(invitation.WorkspaceID.String(), userId)
+		if err == gorm.ErrRecordNotFound {
+			err = workspaces.AddMember(&models.WorkspaceMember{
+				WorkspaceID: invitation.WorkspaceID,
+				UserID:      user.ID,
+				Role:        invitation.Role,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		return workspaces.DeleteInvitation(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wi.WorkspaceRepository.GetByID(invitation.WorkspaceID.String(), userId)
+}
+
+// SetMemberRole changes the role of a member. Only the owner can do this, and
+// the role of the owner can't be changed.
+func (wi *workspaceInteractor) SetMemberRole(workspaceId string, memberId string, role models.WorkspaceRole, userId string) (*models.WorkspaceMember, error) {
+	if _, err := wi.member(workspaceId, userId, models.WorkspaceRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if role != models.WorkspaceRoleAdmin && role != models.WorkspaceRoleMember {
+		return nil, models.ErrWorkspaceRoleInvalid
+	}
+
+	target, err := wi.target(workspaceId, memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Role == models.WorkspaceRoleOwner {
+		return nil, models.ErrWorkspaceForbidden
+	}
+
+	if err := wi.WorkspaceRepository.UpdateMemberRole(workspaceId, memberId, role); err != nil {
+		return nil, err
+	}
+
+	return wi.WorkspaceRepository.GetMember(workspaceId, memberId)
+}
+
+// RemoveMember takes a member out of the workspace. Admins can remove other
+// members, everyone can leave. The owner can't be removed.
+func (wi *workspaceInteractor) RemoveMember(workspaceId string, memberId string, userId string) (bool, error) {
+	caller, err := wi.member(workspaceId, userId, models.WorkspaceRoleMember)
+	if err != nil {
+		return false, err
+	}
+
+	if memberId != userId && !caller.Role.Allows(models.WorkspaceRoleAdmin) {
+		return false, models.ErrWorkspaceForbidden
+	}
+
+	target, err := wi.target(workspaceId, memberId)
+	if err != nil {
+		if err == models.ErrWorkspaceMemberNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if target.Role == models.WorkspaceRoleOwner {
+		return false, models.ErrWorkspaceOwnerRemove
+	}
+
+	return wi.WorkspaceRepository.RemoveMember(workspaceId, memberId)
+}
+
+// SetSearchLanguage switches the text search configuration of the workspace
+// and reindexes all of its todos with it. Only admins can do this.
+func (wi *workspaceInteractor) SetSearchLanguage(workspaceId string, language string, userId string) (*models.Workspace, error) {
+	member, err := wi.member(workspaceId, userId, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := wi.UserRepository.SearchLanguageExists(language)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, models.ErrSearchLanguageNotFound
+	}
+
+	err = wi.DBRepository.Transaction(func(tx *gorm.DB) error {
+		if err := wi.WorkspaceRepository.WithTx(tx).UpdateSearchLanguage(workspaceId, language); err != nil {
+			return err
+		}
+
+		return wi.TodoRepository.WithTx(tx).InWorkspace(&member.WorkspaceID).UpdateSearchLanguage(language, userId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wi.WorkspaceRepository.GetByID(workspaceId, userId)
+}
+
+// member loads the membership of the user, as long as it has the needed
+// role. Workspaces the user isn't a member of are reported as missing.
+func (wi *workspaceInteractor) member(workspaceId string, userId string, need models.WorkspaceRole) (*models.WorkspaceMember, error) {
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		return nil, models.ErrWorkspaceNotFound
+	}
+
+	member, err := wi.WorkspaceRepository.GetMember(workspaceId, userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	if !member.Role.Allows(need) {
+		return nil, models.ErrWorkspaceForbidden
+	}
+
+	return member, nil
+}
+
+// target loads another member of a workspace the caller was checked for.
+func (wi *workspaceInteractor) target(workspaceId string, memberId string) (*models.WorkspaceMember, error) {
+	if _, err := uuid.Parse(memberId); err != nil {
+		return nil, models.ErrWorkspaceMemberNotFound
+	}
+
+	member, err := wi.WorkspaceRepository.GetMember(workspaceId, memberId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrWorkspaceMemberNotFound
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
@@ -0,0 +1,4 @@
+package presenter
+
+type TimeEntryPresenter interface {
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type AppPasswordRepository interface {
+	Create(password *models.AppPassword) error
+	List(userId string) ([]*models.AppPassword, error)
+	GetByHash(hash string) (*models.AppPassword, error)
+	Touch(id string, at time.Time) error
+	Delete(id string, userId string) (bool, error)
+	WithTx(tx *gorm.DB) AppPasswordRepository
+}
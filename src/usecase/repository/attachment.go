@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"todo-service/src/models"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	GetByID(id string) (*models.Attachment, error)
+	GetByKey(key string) (*models.Attachment, error)
+	ListByTodo(todoId string) ([]*models.Attachment, error)
+	ListOrphaned(limit int) ([]*models.Attachment, error)
+	Delete(id string) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"io"
+	"time"
+)
+
+// BlobStore keeps the content of uploaded files outside of the database.
+type BlobStore interface {
+	Put(key string, content io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	// SignedURL returns a link that downloads the blob as filename without
+	// further authentication until the lifetime runs out.
+	SignedURL(key string, filename string, lifetime time.Duration) (string, error)
+	// Verify checks a signature issued by SignedURL for stores that serve
+	// their blobs through this service.
+	Verify(key string, expires string, signature string) bool
+}
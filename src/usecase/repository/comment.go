@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"todo-service/src/models"
+)
+
+type CommentRepository interface {
+	Create(comment *models.Comment) error
+	GetByID(id string) (*models.Comment, error)
+	Paginate(todoId string, page models.PageArgs) (*models.CommentConnection, error)
+	Update(id string, body string) error
+	Delete(id string) (bool, error)
+}
@@ -0,0 +1,9 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+type DBRepository interface {
+	Transaction(fn func(tx *gorm.DB) error) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TodoDependencyRepository interface {
+	Add(dependency *models.TodoDependency) error
+	Remove(blockerId string, blockedId string) (bool, error)
+	ListBlockers(todoId string) ([]*models.Todo, error)
+	ListBlocked(todoId string) ([]*models.Todo, error)
+	ListByBlockers(blockerIds []string) ([]*models.TodoDependency, error)
+	ListOpenlyBlocked(todoIds []string) ([]uuid.UUID, error)
+	Lock() error
+	WithTx(tx *gorm.DB) TodoDependencyRepository
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedRepository interface {
+	Upsert(feed *models.CalendarFeed) error
+	GetByUser(userId string) (*models.CalendarFeed, error)
+	GetByTokenHash(hash string) (*models.CalendarFeed, error)
+	Delete(userId string) (bool, error)
+	WithTx(tx *gorm.DB) CalendarFeedRepository
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type TodoEventRepository interface {
+	Create(events []*models.TodoEvent) error
+	ListByTodo(todoId string) ([]*models.TodoEvent, error)
+	WithTx(tx *gorm.DB) TodoEventRepository
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type ShareRepository interface {
+	Upsert(shares []*models.Share) error
+	ListByTodo(todoId string) ([]*models.Share, error)
+	ListByRecipient(todoIds []string, userId string) ([]*models.Share, error)
+	Delete(todoId string, userId string) (bool, error)
+	Access(todoId string, userId string) (*models.TodoAccess, error)
+	TrashedAccess(todoId string, userId string) (*models.TodoAccess, error)
+	AccessMany(todoIds []string, userId string) (map[string]*models.TodoAccess, error)
+	WithTx(tx *gorm.DB) ShareRepository
+}
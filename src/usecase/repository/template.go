@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TodoTemplateRepository interface {
+	Create(template *models.TodoTemplate) error
+	GetByID(id string) (*models.TodoTemplate, error)
+	List(workspaceId *uuid.UUID, userId string) ([]*models.TodoTemplate, error)
+	Delete(id string) (bool, error)
+	WithTx(tx *gorm.DB) TodoTemplateRepository
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type TimeEntryRepository interface {
+	Create(entry *models.TimeEntry) error
+	GetByID(id string, userId string) (*models.TimeEntry, error)
+	GetRunning(userId string) (*models.TimeEntry, error)
+	Update(id string, userId string, fields map[string]interface{}) error
+	Delete(id string, userId string) (bool, error)
+	ListByTodo(todoId string) ([]*models.TimeEntry, error)
+	ListByUser(userId string, from time.Time, to time.Time) ([]*models.TimeEntry, error)
+	SumByTodos(todoIds []string, now time.Time) (map[string]int64, error)
+	WithTx(tx *gorm.DB) TimeEntryRepository
+}
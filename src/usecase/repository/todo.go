@@ -1,14 +1,42 @@
 package repository
 
 import (
+	"time"
 	"todo-service/graph/model"
 	"todo-service/src/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type TodoRepository interface {
 	Create(input model.NewTodo, userId string) (*models.Todo, error)
-	MarkComplete(id string, userId string) error
-	Delete(id string, userId string) (bool, error)
+	MarkComplete(ids []string, userId string, cascade models.CascadeRule) error
+	Delete(id string, userId string, cascade models.CascadeRule) (bool, error)
 	GetByID(id string, userId string) (*models.Todo, error)
+	GetByUID(uid string, userId string) (*models.Todo, error)
+	ListByIDs(ids []string, userId string) ([]*models.Todo, error)
 	List(userId string) ([]*models.Todo, error)
+	Each(filter *model.TodoFilter, userId string, fn func(todo *models.Todo) error) error
+	EachChanged(since time.Time, userId string, fn func(todo *models.Todo) error) error
+	Version(filter *model.TodoFilter, userId string) (*models.TodoVersion, error)
+	Paginate(page models.PageArgs, filter *model.TodoFilter, order []*model.TodoOrder, userId string) (*models.TodoConnection, error)
+	ListChildren(parentId string, userId string) ([]*models.Todo, error)
+	ListTree(rootId *string, userId string) ([]*models.Todo, error)
+	ListSubtrees(rootIds []string, userId string) ([]*models.Todo, error)
+	ListTrashed(userId string) ([]*models.Todo, error)
+	ListAssigned(assigneeId string, userId string) ([]*models.Todo, error)
+	Restore(id string, userId string) (bool, error)
+	EmptyTrash(userId string, own bool) (int64, error)
+	Purge(before time.Time) (int64, error)
+	Search(query string, limit int, userId string) ([]*models.TodoSearchResult, error)
+	UpdateSearchLanguage(language string, userId string) error
+	Insert(todo *models.Todo) error
+	Update(id string, userId string, fields map[string]interface{}) error
+	UpdateMany(ids []string, userId string, fields map[string]interface{}) error
+	UpdateSeries(seriesId string, from time.Time, userId string, fields map[string]interface{}) error
+	Adjacent(todo *models.Todo, next bool, excludeId string, userId string) (*models.Todo, error)
+	Rebalance(userId string) error
+	WithTx(tx *gorm.DB) TodoRepository
+	InWorkspace(workspaceId *uuid.UUID) TodoRepository
 }
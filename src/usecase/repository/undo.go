@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"time"
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type TodoUndoRepository interface {
+	Create(undo *models.TodoUndo) error
+	Get(id string, userId string) (*models.TodoUndo, error)
+	Delete(id string) error
+	DeleteExpired(now time.Time) (int64, error)
+	WithTx(tx *gorm.DB) TodoUndoRepository
+}
@@ -2,10 +2,16 @@ package repository
 
 import (
 	"todo-service/src/models"
+
+	"gorm.io/gorm"
 )
 
 type UserRepository interface {
 	Create(user models.User) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByID(id string) (*models.User, error)
+	UpdateTimezone(id string, timezone string) error
+	UpdateSearchLanguage(id string, language string) error
+	SearchLanguageExists(language string) (bool, error)
+	WithTx(tx *gorm.DB) UserRepository
 }
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkflowRepository interface {
+	List(workspaceId *uuid.UUID, userId string) (models.Workflow, error)
+	Replace(workspaceId *uuid.UUID, userId string, workflow models.Workflow) error
+	WithTx(tx *gorm.DB) WorkflowRepository
+}
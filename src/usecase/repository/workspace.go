@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"todo-service/src/models"
+
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepository interface {
+	Create(workspace *models.Workspace, owner *models.WorkspaceMember) error
+	GetByID(id string, userId string) (*models.Workspace, error)
+	ListByUser(userId string) ([]*models.Workspace, error)
+	GetMember(workspaceId string, userId string) (*models.WorkspaceMember, error)
+	ListMembers(workspaceId string) ([]*models.WorkspaceMember, error)
+	AddMember(member *models.WorkspaceMember) error
+	UpdateMemberRole(workspaceId string, userId string, role models.WorkspaceRole) error
+	RemoveMember(workspaceId string, userId string) (bool, error)
+	UpdateSearchLanguage(id string, language string) error
+	UpsertInvitation(invitation *models.WorkspaceInvitation) error
+	GetInvitation(id string) (*models.WorkspaceInvitation, error)
+	GetInvitationByEmail(workspaceId string, email string) (*models.WorkspaceInvitation, error)
+	ListInvitationsByEmail(email string) ([]*models.WorkspaceInvitation, error)
+	DeleteInvitation(id string) error
+	WithTx(tx *gorm.DB) WorkspaceRepository
+}
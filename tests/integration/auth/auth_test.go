@@ -77,7 +77,7 @@ var _ = BeforeSuite(func() {
 	router = echo.New()
 
 	// Initialize Echo instance
-	graphql.NewGraphqlRouter(router, useCase)
+	graphql.NewGraphqlRouter(router, useCase, logger)
 })
 
 var _ = Describe("Auth", func() {
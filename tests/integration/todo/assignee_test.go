@@ -0,0 +1,176 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type assignTodo struct {
+	Data struct {
+		ID   uuid.UUID `json:"id"`
+		User struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"user"`
+		Assignee *struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"assignee"`
+	} `graphql:"assignTodo(todoID: $todoID, userID: $userID)"`
+}
+
+type unassignTodo struct {
+	Data struct {
+		ID       uuid.UUID `json:"id"`
+		Assignee *struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"assignee"`
+	} `graphql:"unassignTodo(todoID: $todoID)"`
+}
+
+type assignedToMe struct {
+	Todos []struct {
+		ID uuid.UUID `json:"id"`
+	} `graphql:"assignedToMe"`
+}
+
+var _ = Describe("Assigning todos", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	shareWithUser2 := func(todo models.Todo) {
+		share := models.Share{
+			ID:     uuid.New(),
+			TodoID: todo.ID,
+			UserID: signInUser2Resp.User.ID,
+			Role:   models.TodoRoleViewer,
+		}
+		Expect(db.Omit("Todo", "User").Create(&share).Error).To(BeNil())
+	}
+
+	assign := func(todo models.Todo, userId uuid.UUID) (assignTodo, error) {
+		var q assignTodo
+		variables := map[string]interface{}{
+			"todoID": todo.ID.String(),
+			"userID": userId.String(),
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	Context("Assign todo", func() {
+		It("assigns a todo to a user it is shared with and keeps the creator", func() {
+
+			todo := signInUser1Resp.Todos[0]
+			shareWithUser2(todo)
+
+			q, err := assign(todo, signInUser2Resp.User.ID)
+			Expect(err).To(BeNil())
+			Expect(q.Data.User.ID).To(Equal(signInUser1Resp.User.ID))
+			Expect(q.Data.Assignee).ToNot(BeNil())
+			Expect(q.Data.Assignee.ID).To(Equal(signInUser2Resp.User.ID))
+
+			var assigned assignedToMe
+			err = tools.DoQuery(&assigned, nil, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(assigned.Todos).To(HaveLen(1))
+			Expect(assigned.Todos[0].ID).To(Equal(todo.ID))
+
+			err = tools.DoQuery(&assigned, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(assigned.Todos).To(BeEmpty())
+		})
+
+		It("hides assigned todos the assignee lost access to", func() {
+
+			todo := signInUser1Resp.Todos[0]
+			shareWithUser2(todo)
+
+			_, err := assign(todo, signInUser2Resp.User.ID)
+			Expect(err).To(BeNil())
+
+			Expect(db.Where("todo_id = ?", todo.ID).Delete(&models.Share{}).Error).To(BeNil())
+
+			var assigned assignedToMe
+			err = tools.DoQuery(&assigned, nil, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(assigned.Todos).To(BeEmpty())
+		})
+
+		It("error: assignee without access to the todo", func() {
+
+			_, err := assign(signInUser1Resp.Todos[0], signInUser2Resp.User.ID)
+			Expect(err.Error()).To(Equal("Message: assignee has no access to the todo, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: assignee that doesn't exist", func() {
+
+			_, err := assign(signInUser1Resp.Todos[0], uuid.New())
+			Expect(err.Error()).To(Equal("Message: assignee has no access to the todo, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: todo shared as viewer", func() {
+
+			todo := signInUser2Resp.Todos[0]
+			share := models.Share{
+				ID:     uuid.New(),
+				TodoID: todo.ID,
+				UserID: signInUser1Resp.User.ID,
+				Role:   models.TodoRoleViewer,
+			}
+			Expect(db.Omit("Todo", "User").Create(&share).Error).To(BeNil())
+
+			_, err := assign(todo, signInUser1Resp.User.ID)
+			Expect(err.Error()).To(Equal("Message: you don't have permission to do this with the todo, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: todo of another user", func() {
+
+			_, err := assign(signInUser2Resp.Todos[0], signInUser1Resp.User.ID)
+			Expect(err.Error()).To(Equal("Message: record not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Unassign todo", func() {
+		It("removes the assignee", func() {
+
+			todo := signInUser1Resp.Todos[0]
+
+			_, err := assign(todo, signInUser1Resp.User.ID)
+			Expect(err).To(BeNil())
+
+			var q unassignTodo
+			variables := map[string]interface{}{
+				"todoID": todo.ID.String(),
+			}
+
+			err = tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Data.Assignee).To(BeNil())
+
+			var stored models.Todo
+			Expect(db.Take(&stored, "id = ?", todo.ID).Error).To(BeNil())
+			Expect(stored.AssigneeID).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,257 @@
+package todo
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const attachFileQuery = `mutation ($todoID: String!, $file: Upload!) {
+	attachFile(todoID: $todoID, file: $file) { id filename contentType size url }
+}`
+
+type attachFile struct {
+	Attachment struct {
+		ID          string `json:"id"`
+		Filename    string `json:"filename"`
+		ContentType string `json:"contentType"`
+		Size        int    `json:"size"`
+		URL         string `json:"url"`
+	} `json:"attachFile"`
+}
+
+type todoAttachments struct {
+	Todos struct {
+		Edges []struct {
+			Node struct {
+				ID          string `json:"id"`
+				Attachments []struct {
+					ID       string `json:"id"`
+					Filename string `json:"filename"`
+				} `json:"attachments"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `graphql:"todos(orderBy: [{field: MANUAL}])"`
+}
+
+type deleteAttachment struct {
+	DeleteAttachmentRes bool `graphql:"deleteAttachment(attachmentID: $attachmentID)"`
+}
+
+var _ = Describe("Attachments", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+		Expect(db.Migrator().DropTable(&models.Attachment{})).To(Succeed())
+		Expect(db.AutoMigrate(&models.Attachment{})).To(Succeed())
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	uploadAs := func(token string, todoID string, filename string, content []byte) (attachFile, error) {
+		var q attachFile
+		variables := map[string]interface{}{
+			"todoID": todoID,
+		}
+
+		err := tools.DoUpload(&q, attachFileQuery, variables, filename, content, token, router)
+		return q, err
+	}
+
+	upload := func(todoID string, filename string, content []byte) (attachFile, error) {
+		return uploadAs(signInUser1Resp.Auth.Data.AccessToken, todoID, filename, content)
+	}
+
+	deleteAs := func(token string, attachmentID string) (bool, error) {
+		var m deleteAttachment
+		variables := map[string]interface{}{
+			"attachmentID": attachmentID,
+		}
+
+		err := tools.DoMutate(&m, variables, token, router)
+		return m.DeleteAttachmentRes, err
+	}
+
+	shareWithUser2 := func(todo models.Todo, role models.TodoRole) {
+		share := models.Share{
+			ID:     uuid.New(),
+			TodoID: todo.ID,
+			UserID: signInUser2Resp.User.ID,
+			Role:   role,
+		}
+		Expect(db.Omit("Todo", "User").Create(&share).Error).To(BeNil())
+	}
+
+	download := func(signed string) *httptest.ResponseRecorder {
+		link, err := url.Parse(signed)
+		Expect(err).To(BeNil())
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link.RequestURI(), nil))
+		return w
+	}
+
+	Context("Attach a file", func() {
+		It("stores the file and serves it through the signed url", func() {
+
+			q, err := upload(signInUser1Resp.Todos[0].ID.String(), "notes.txt", []byte("remember the milk"))
+			Expect(err).To(BeNil())
+			Expect(q.Attachment.Filename).To(Equal("notes.txt"))
+			Expect(q.Attachment.ContentType).To(Equal("text/plain"))
+			Expect(q.Attachment.Size).To(Equal(17))
+
+			w := download(q.Attachment.URL)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("remember the milk"))
+			Expect(w.Header().Get("Content-Disposition")).To(ContainSubstring("notes.txt"))
+		})
+
+		It("refuses a tampered url", func() {
+
+			q, err := upload(signInUser1Resp.Todos[0].ID.String(), "notes.txt", []byte("remember the milk"))
+			Expect(err).To(BeNil())
+
+			w := download(q.Attachment.URL + "0")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("error: todo of another user", func() {
+
+			_, err := upload(signInUser2Resp.Todos[0].ID.String(), "notes.txt", []byte("remember the milk"))
+			Expect(err.Error()).To(Equal("todo not found"))
+		})
+
+		It("error: file too large", func() {
+
+			_, err := upload(signInUser1Resp.Todos[0].ID.String(), "big.txt", bytes.Repeat([]byte("a"), 2048))
+			Expect(err.Error()).To(Equal("attachment is too large"))
+		})
+
+		It("error: type not allowed", func() {
+
+			png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+			_, err := upload(signInUser1Resp.Todos[0].ID.String(), "notes.txt", png)
+			Expect(err.Error()).To(Equal("attachment type is not allowed"))
+		})
+	})
+
+	Context("Delete an attachment", func() {
+		It("removes the file from the blob store", func() {
+
+			q, err := upload(signInUser1Resp.Todos[0].ID.String(), "notes.txt", []byte("remember the milk"))
+			Expect(err).To(BeNil())
+
+			deleted, err := deleteAs(signInUser1Resp.Auth.Data.AccessToken, q.Attachment.ID)
+			Expect(err).To(BeNil())
+			Expect(deleted).To(BeTrue())
+
+			w := download(q.Attachment.URL)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("error: viewer who didn't upload the file", func() {
+
+			shareWithUser2(signInUser1Resp.Todos[0], models.TodoRoleViewer)
+			q, err := upload(signInUser1Resp.Todos[0].ID.String(), "notes.txt", []byte("remember the milk"))
+			Expect(err).To(BeNil())
+
+			_, err = deleteAs(signInUser2Resp.Auth.Data.AccessToken, q.Attachment.ID)
+			Expect(err.Error()).To(Equal(models.ErrTodoForbidden.Message))
+
+			deleted, err := deleteAs(signInUser2Resp.Auth.Data.AccessToken, uuid.NewString())
+			Expect(err).To(BeNil())
+			Expect(deleted).To(BeFalse())
+		})
+	})
+
+	Context("Shared todos", func() {
+		It("lets an editor attach files the owner sees and remove them", func() {
+
+			shareWithUser2(signInUser1Resp.Todos[0], models.TodoRoleEditor)
+			q, err := uploadAs(signInUser2Resp.Auth.Data.AccessToken, signInUser1Resp.Todos[0].ID.String(),
+				"notes.txt", []byte("remember the milk"))
+			Expect(err).To(BeNil())
+
+			var attachment models.Attachment
+			Expect(db.Where("id = ?", q.Attachment.ID).Take(&attachment).Error).To(BeNil())
+			Expect(attachment.UserID).To(Equal(signInUser1Resp.User.ID))
+			Expect(attachment.UploaderID).To(Equal(signInUser2Resp.User.ID))
+
+			var list todoAttachments
+			Expect(tools.DoQuery(&list, nil, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+			found := false
+			for _, edge := range list.Todos.Edges {
+				if edge.Node.ID == signInUser1Resp.Todos[0].ID.String() {
+					Expect(edge.Node.Attachments).To(HaveLen(1))
+					Expect(edge.Node.Attachments[0].ID).To(Equal(q.Attachment.ID))
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+
+			deleted, err := deleteAs(signInUser2Resp.Auth.Data.AccessToken, q.Attachment.ID)
+			Expect(err).To(BeNil())
+			Expect(deleted).To(BeTrue())
+		})
+
+		It("lets the uploader remove their file after losing edit rights", func() {
+
+			shareWithUser2(signInUser1Resp.Todos[0], models.TodoRoleEditor)
+			q, err := uploadAs(signInUser2Resp.Auth.Data.AccessToken, signInUser1Resp.Todos[0].ID.String(),
+				"notes.txt", []byte("remember the milk"))
+			Expect(err).To(BeNil())
+
+			Expect(db.Model(&models.Share{}).Where("todo_id = ?", signInUser1Resp.Todos[0].ID).
+				Update("role", models.TodoRoleViewer).Error).To(BeNil())
+
+			deleted, err := deleteAs(signInUser2Resp.Auth.Data.AccessToken, q.Attachment.ID)
+			Expect(err).To(BeNil())
+			Expect(deleted).To(BeTrue())
+		})
+	})
+
+	Context("Empty the trash", func() {
+		It("removes the attachments of deleted todos", func() {
+
+			q, err := upload(signInUser1Resp.Todos[0].ID.String(), "notes.txt", []byte("remember the milk"))
+			Expect(err).To(BeNil())
+
+			db.Delete(&models.Todo{}, "id = ?", signInUser1Resp.Todos[0].ID)
+
+			var m emptyTrash
+			err = tools.DoMutate(&m, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			var left int64
+			db.Model(&models.Attachment{}).Count(&left)
+			Expect(left).To(BeZero())
+
+			key := signInUser1Resp.User.ID.String() + "/" + signInUser1Resp.Todos[0].ID.String() + "/" + q.Attachment.ID
+			_, err = os.Stat(filepath.Join(viper.GetString("attachments.local.dir"), key))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,174 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type TodoPatch map[string]interface{}
+
+type bulkTodoResult struct {
+	ID      string  `json:"id"`
+	Success bool    `json:"success"`
+	Error   *string `json:"error"`
+}
+
+type bulkUpdateTodos struct {
+	Results []bulkTodoResult `graphql:"bulkUpdateTodos(ids: $ids, patch: $patch)"`
+}
+
+type bulkDeleteTodos struct {
+	Results []bulkTodoResult `graphql:"bulkDeleteTodos(ids: $ids)"`
+}
+
+var _ = Describe("Bulk todo operations", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	shareWithUser2 := func(todo models.Todo, role models.TodoRole) {
+		share := models.Share{
+			ID:     uuid.New(),
+			TodoID: todo.ID,
+			UserID: signInUser2Resp.User.ID,
+			Role:   role,
+		}
+		Expect(db.Omit("Todo", "User").Create(&share).Error).To(BeNil())
+
+		var err error
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		Expect(err).To(BeNil())
+	}
+
+	Context("Bulk update", func() {
+		It("updates the todos of the user and reports the others", func() {
+
+			var q bulkUpdateTodos
+			variables := map[string]interface{}{
+				"ids": []string{
+					signInUser1Resp.Todos[0].ID.String(),
+					signInUser1Resp.Todos[1].ID.String(),
+					signInUser2Resp.Todos[0].ID.String(),
+					"not-a-uuid",
+				},
+				"patch": TodoPatch{"done": true, "text": "bulk"},
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results).To(HaveLen(4))
+			Expect(q.Results[0].Success).To(BeTrue())
+			Expect(q.Results[1].Success).To(BeTrue())
+			Expect(q.Results[2].Success).To(BeFalse())
+			Expect(*q.Results[2].Error).To(Equal("todo not found"))
+			Expect(q.Results[3].Success).To(BeFalse())
+
+			var done int64
+			db.Model(&models.Todo{}).Where("done = true AND text = ?", "bulk").Count(&done)
+			Expect(done).To(Equal(int64(2)))
+
+			var other models.Todo
+			db.Take(&other, "id = ?", signInUser2Resp.Todos[0].ID)
+			Expect(other.Done).To(BeFalse())
+		})
+
+		It("error: invalid patch", func() {
+
+			var q bulkUpdateTodos
+			variables := map[string]interface{}{
+				"ids":   []string{signInUser1Resp.Todos[0].ID.String()},
+				"patch": TodoPatch{"text": ""},
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: Parameters incorrectly formatted or out of range (Text), Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Shared todos", func() {
+		It("updates todos shared for editing along with the user's own", func() {
+
+			shareWithUser2(signInUser1Resp.Todos[0], models.TodoRoleEditor)
+			shareWithUser2(signInUser1Resp.Todos[1], models.TodoRoleViewer)
+
+			var q bulkUpdateTodos
+			variables := map[string]interface{}{
+				"ids": []string{
+					signInUser1Resp.Todos[0].ID.String(),
+					signInUser2Resp.Todos[0].ID.String(),
+					signInUser1Resp.Todos[1].ID.String(),
+				},
+				"patch": TodoPatch{"text": "bulk"},
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results[0].Success).To(BeTrue())
+			Expect(q.Results[1].Success).To(BeTrue())
+			Expect(q.Results[2].Success).To(BeFalse())
+			Expect(*q.Results[2].Error).To(Equal(models.ErrTodoForbidden.Message))
+
+			var shared models.Todo
+			db.Take(&shared, "id = ?", signInUser1Resp.Todos[0].ID)
+			Expect(shared.Text).To(Equal("bulk"))
+			Expect(shared.UserID).To(Equal(signInUser1Resp.User.ID))
+		})
+
+		It("leaves deleting to the owner", func() {
+
+			shareWithUser2(signInUser1Resp.Todos[0], models.TodoRoleEditor)
+
+			var q bulkDeleteTodos
+			variables := map[string]interface{}{
+				"ids": []string{signInUser1Resp.Todos[0].ID.String()},
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results[0].Success).To(BeFalse())
+			Expect(*q.Results[0].Error).To(Equal(models.ErrTodoForbidden.Message))
+		})
+	})
+
+	Context("Bulk delete", func() {
+		It("moves the todos of the user to the trash", func() {
+
+			var q bulkDeleteTodos
+			variables := map[string]interface{}{
+				"ids": []string{
+					signInUser1Resp.Todos[0].ID.String(),
+					signInUser1Resp.Todos[2].ID.String(),
+					signInUser2Resp.Todos[1].ID.String(),
+				},
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results[0].Success).To(BeTrue())
+			Expect(q.Results[1].Success).To(BeTrue())
+			Expect(q.Results[2].Success).To(BeFalse())
+
+			var left int64
+			db.Model(&models.Todo{}).Where("user_id = ?", signInUser1Resp.User.ID).Count(&left)
+			Expect(left).To(Equal(int64(1)))
+
+			db.Model(&models.Todo{}).Where("user_id = ?", signInUser2Resp.User.ID).Count(&left)
+			Expect(left).To(Equal(int64(len(signInUser2Resp.Todos))))
+		})
+	})
+})
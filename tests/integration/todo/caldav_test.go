@@ -0,0 +1,267 @@
+package todo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"todo-service/src/infrastructure/delivery/caldav"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type createAppPassword struct {
+	AppPassword struct {
+		ID       string  `json:"id"`
+		Name     string  `json:"name"`
+		Password *string `json:"password"`
+	} `graphql:"createAppPassword(name: $name)"`
+}
+
+type appPasswords struct {
+	AppPasswords []struct {
+		ID       string  `json:"id"`
+		Name     string  `json:"name"`
+		Password *string `json:"password"`
+	} `graphql:"appPasswords"`
+}
+
+type deleteAppPassword struct {
+	Deleted bool `graphql:"deleteAppPassword(appPasswordID: $appPasswordID)"`
+}
+
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VTODO">
+        <c:prop-filter name="SUMMARY"><c:text-match>%s</c:text-match></c:prop-filter>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+const syncCollection = `<?xml version="1.0" encoding="utf-8"?>
+<d:sync-collection xmlns:d="DAV:">
+  <d:sync-token>%s</d:sync-token>
+  <d:sync-level>1</d:sync-level>
+  <d:prop><d:getetag/></d:prop>
+</d:sync-collection>`
+
+const calendarObject = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\nBEGIN:VTODO\r\n" +
+	"UID:%s\r\nSUMMARY:%s\r\nSTATUS:NEEDS-ACTION\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+var syncTokenPattern = regexp.MustCompile(`<d:sync-token>([^<]*)</d:sync-token>`)
+
+var _ = Describe("CalDAV", func() {
+	var password string
+	personal := caldav.Prefix + "/calendars/personal/"
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		var q createAppPassword
+		variables := map[string]interface{}{
+			"name": "Phone",
+		}
+		Expect(tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		Expect(q.AppPassword.Password).NotTo(BeNil())
+		password = *q.AppPassword.Password
+	})
+
+	dav := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		var r io.Reader
+		if body != "" {
+			r = strings.NewReader(body)
+		}
+		req := httptest.NewRequest(method, path, r)
+		req.SetBasicAuth(signInUser1Resp.User.Email, password)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	object := func(uid, summary string) string {
+		return fmt.Sprintf(calendarObject, uid, summary)
+	}
+
+	syncToken := func(token string) (*httptest.ResponseRecorder, string) {
+		w := dav("REPORT", personal, fmt.Sprintf(syncCollection, token), nil)
+		match := syncTokenPattern.FindStringSubmatch(w.Body.String())
+		if match == nil {
+			return w, ""
+		}
+		return w, match[1]
+	}
+
+	It("signs in with an app password only", func() {
+		req := httptest.NewRequest("PROPFIND", caldav.Prefix+"/", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		Expect(w.Header().Get("WWW-Authenticate")).To(HavePrefix("Basic"))
+
+		req = httptest.NewRequest("PROPFIND", caldav.Prefix+"/", nil)
+		req.SetBasicAuth(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+
+		Expect(dav("PROPFIND", caldav.Prefix+"/", "", map[string]string{"Depth": "0"}).Code).To(Equal(http.StatusMultiStatus))
+
+		var list appPasswords
+		Expect(tools.DoQuery(&list, nil, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		Expect(list.AppPasswords).To(HaveLen(1))
+		Expect(list.AppPasswords[0].Name).To(Equal("Phone"))
+		Expect(list.AppPasswords[0].Password).To(BeNil())
+
+		var q deleteAppPassword
+		variables := map[string]interface{}{
+			"appPasswordID": list.AppPasswords[0].ID,
+		}
+		Expect(tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		Expect(q.Deleted).To(BeTrue())
+		Expect(dav("PROPFIND", caldav.Prefix+"/", "", nil).Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("redirects the well-known path", func() {
+		req := httptest.NewRequest(http.MethodGet, caldav.WellKnownPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(w.Header().Get("Location")).To(Equal(caldav.Prefix + "/"))
+	})
+
+	It("lists the todo lists as calendars and the todos as objects", func() {
+		w := dav("PROPFIND", caldav.Prefix+"/calendars/", "", map[string]string{"Depth": "1"})
+		Expect(w.Code).To(Equal(http.StatusMultiStatus))
+		Expect(w.Body.String()).To(ContainSubstring("<d:href>" + personal + "</d:href>"))
+		Expect(w.Body.String()).To(ContainSubstring(`<c:comp name="VTODO"/>`))
+
+		w = dav("PROPFIND", personal, "", map[string]string{"Depth": "1"})
+		Expect(w.Code).To(Equal(http.StatusMultiStatus))
+		for _, todo := range signInUser1Resp.Todos {
+			Expect(w.Body.String()).To(ContainSubstring(personal + todo.ID.String() + ".ics"))
+		}
+		Expect(w.Body.String()).NotTo(ContainSubstring(signInUser2Resp.Todos[0].ID.String()))
+	})
+
+	It("creates, reads, updates and deletes objects with ETags", func() {
+		path := personal + "new-uid.ics"
+		w := dav(http.MethodPut, path, object("new-uid", "from phone"), map[string]string{"If-None-Match": "*"})
+		Expect(w.Code).To(Equal(http.StatusCreated))
+
+		w = dav(http.MethodPut, path, object("new-uid", "again"), map[string]string{"If-None-Match": "*"})
+		Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+
+		w = dav(http.MethodGet, path, "", nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring("SUMMARY:from phone\r\n"))
+		etag := w.Header().Get("ETag")
+		Expect(etag).NotTo(BeEmpty())
+
+		var todo models.Todo
+		Expect(db.Where("uid = ?", "new-uid").First(&todo).Error).To(BeNil())
+		Expect(todo.UserID).To(Equal(signInUser1Resp.User.ID))
+		Expect(todo.Text).To(Equal("from phone"))
+
+		w = dav(http.MethodPut, path, object("new-uid", "changed"), map[string]string{"If-Match": `"stale"`})
+		Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+
+		w = dav(http.MethodPut, path, object("new-uid", "changed"), map[string]string{"If-Match": etag})
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		Expect(dav(http.MethodGet, path, "", nil).Header().Get("ETag")).NotTo(Equal(etag))
+
+		w = dav(http.MethodPut, path, object("other-uid", "changed"), nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+
+		w = dav(http.MethodDelete, path, "", map[string]string{"If-Match": etag})
+		Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+
+		w = dav(http.MethodDelete, path, "", nil)
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		Expect(dav(http.MethodGet, path, "", nil).Code).To(Equal(http.StatusNotFound))
+		Expect(dav(http.MethodDelete, path, "", nil).Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("answers calendar queries", func() {
+		w := dav("REPORT", personal, fmt.Sprintf(calendarQuery, "TODO_2"), map[string]string{"Depth": "1"})
+		Expect(w.Code).To(Equal(http.StatusMultiStatus))
+		Expect(w.Body.String()).To(ContainSubstring(signInUser1Resp.Todos[1].ID.String() + ".ics"))
+		Expect(w.Body.String()).NotTo(ContainSubstring(signInUser1Resp.Todos[0].ID.String() + ".ics"))
+		Expect(w.Body.String()).To(ContainSubstring("<d:getetag>"))
+	})
+
+	It("reports the changes since a sync token", func() {
+		w, token := syncToken("")
+		Expect(w.Code).To(Equal(http.StatusMultiStatus))
+		Expect(token).To(HavePrefix(models.CalendarSyncTokenPrefix))
+		Expect(strings.Count(w.Body.String(), "<d:response>")).To(Equal(len(signInUser1Resp.Todos)))
+
+		changed := signInUser1Resp.Todos[1].ID.String()
+		Expect(dav(http.MethodPut, personal+changed+".ics", object(changed, "changed"), nil).Code).
+			To(Equal(http.StatusNoContent))
+
+		w, next := syncToken(token)
+		Expect(w.Code).To(Equal(http.StatusMultiStatus))
+		Expect(next).NotTo(Equal(token))
+		Expect(strings.Count(w.Body.String(), "<d:response>")).To(Equal(1))
+		Expect(w.Body.String()).To(ContainSubstring(changed + ".ics"))
+
+		deleted := signInUser1Resp.Todos[2].ID.String()
+		Expect(dav(http.MethodDelete, personal+deleted+".ics", "", nil).Code).To(Equal(http.StatusNoContent))
+
+		w, _ = syncToken(next)
+		Expect(w.Body.String()).To(ContainSubstring(deleted + ".ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>"))
+
+		w, _ = syncToken(models.CalendarSyncTokenPrefix + "invalid")
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("<d:valid-sync-token/>"))
+	})
+
+	It("refuses sync tokens from before the trash was emptied", func() {
+		_, token := syncToken("")
+		Expect(token).NotTo(BeEmpty())
+
+		deleted := signInUser1Resp.Todos[2].ID.String()
+		Expect(dav(http.MethodDelete, personal+deleted+".ics", "", nil).Code).To(Equal(http.StatusNoContent))
+
+		var m emptyTrash
+		Expect(tools.DoMutate(&m, nil, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+
+		w, _ := syncToken(token)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("<d:valid-sync-token/>"))
+
+		w, next := syncToken("")
+		Expect(w.Code).To(Equal(http.StatusMultiStatus))
+		Expect(w.Body.String()).NotTo(ContainSubstring(deleted))
+
+		w, _ = syncToken(next)
+		Expect(w.Code).To(Equal(http.StatusMultiStatus))
+		Expect(strings.Count(w.Body.String(), "<d:response>")).To(Equal(0))
+	})
+
+	It("error: unknown workspace", func() {
+		w := dav("PROPFIND", caldav.Prefix+"/calendars/unknown/", "", map[string]string{"Depth": "0"})
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+})
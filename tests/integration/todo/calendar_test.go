@@ -0,0 +1,151 @@
+package todo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const groceries = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example//EN\r\n" +
+	"BEGIN:VTODO\r\nUID:groceries@example.com\r\nDTSTAMP:20300501T080000Z\r\nSUMMARY:Groceries\r\n" +
+	"DUE:20300502T170000Z\r\nRRULE:FREQ=WEEKLY\r\nPRIORITY:2\r\nCATEGORIES:home,errands\r\nEND:VTODO\r\n" +
+	"BEGIN:VTODO\r\nUID:milk@example.com\r\nDTSTAMP:20300501T080000Z\r\nSUMMARY:Milk\r\nSTATUS:COMPLETED\r\n" +
+	"COMPLETED:20300501T090000Z\r\nRELATED-TO:groceries@example.com\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+var _ = Describe("Todo calendar", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	importCalendar := func(calendar string) (importTodos, error) {
+		var q importTodos
+		variables := map[string]interface{}{
+			"format": "ICS",
+		}
+
+		err := tools.DoUpload(&q, importTodosQuery, variables, "todos.ics", []byte(calendar),
+			signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	exportCalendar := func() string {
+		var q exportTodos
+		variables := map[string]interface{}{
+			"format": TodoFileFormat("ICS"),
+		}
+
+		Expect(tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		return q.Export
+	}
+
+	countTodos := func() int64 {
+		var count int64
+		Expect(db.Model(&models.Todo{}).Where("user_id = ?", signInUser1Resp.User.ID).Count(&count).Error).To(BeNil())
+		return count
+	}
+
+	Context("Export", func() {
+		It("exports the todos as VTODO components", func() {
+			Expect(db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[0].ID).
+				Updates(map[string]interface{}{"done": true, "completed_at": "2030-05-01 09:00:00+00"}).Error).To(BeNil())
+
+			calendar := exportCalendar()
+			Expect(calendar).To(HavePrefix("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+			Expect(calendar).To(HaveSuffix("END:VCALENDAR\r\n"))
+			Expect(strings.Count(calendar, "BEGIN:VTODO")).To(Equal(len(signInUser1Resp.Todos)))
+			Expect(calendar).To(ContainSubstring("UID:" + signInUser1Resp.Todos[0].ID.String() + "\r\n"))
+			Expect(calendar).To(ContainSubstring("SUMMARY:" + signInUser1Resp.Todos[0].Text + "\r\n"))
+			Expect(calendar).To(ContainSubstring("STATUS:COMPLETED\r\nCOMPLETED:20300501T090000Z\r\n"))
+		})
+
+		It("serves the calendar as a download", func() {
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/todos/export?format=ics", nil)
+			req.Header.Set("Authorization", "Bearer "+signInUser1Resp.Auth.Data.AccessToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("text/calendar; charset=utf-8"))
+			Expect(w.Header().Get("Content-Disposition")).To(Equal(`attachment; filename=todos.ics`))
+			Expect(w.Body.String()).To(Equal(exportCalendar()))
+		})
+	})
+
+	Context("Import", func() {
+		It("imports todos of another calendar", func() {
+			before := countTodos()
+
+			q, err := importCalendar(groceries)
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(2))
+			Expect(q.Result.Updated).To(Equal(0))
+			Expect(q.Result.Todos).To(HaveLen(1))
+			Expect(q.Result.Todos[0].Text).To(Equal("Groceries"))
+			Expect(q.Result.Todos[0].Priority).To(Equal("HIGH"))
+			Expect(q.Result.Todos[0].Tags).To(Equal([]string{"home", "errands"}))
+			Expect(q.Result.Todos[0].Children).To(HaveLen(1))
+			Expect(countTodos()).To(Equal(before + 2))
+
+			var milk models.Todo
+			Expect(db.Where("uid = ?", "milk@example.com").Take(&milk).Error).To(BeNil())
+			Expect(milk.Done).To(BeTrue())
+			Expect(milk.CompletedAt.UTC().Format("2006-01-02T15:04")).To(Equal("2030-05-01T09:00"))
+		})
+
+		It("updates the todos of the same UID instead of duplicating them", func() {
+			_, err := importCalendar(groceries)
+			Expect(err).To(BeNil())
+			before := countTodos()
+
+			q, err := importCalendar(strings.Replace(groceries, "SUMMARY:Groceries", "SUMMARY:Weekly groceries", 1))
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(2))
+			Expect(q.Result.Updated).To(Equal(2))
+			Expect(q.Result.Todos[0].Text).To(Equal("Weekly groceries"))
+			Expect(countTodos()).To(Equal(before))
+		})
+
+		It("round-trips an export", func() {
+			before := countTodos()
+			calendar := exportCalendar()
+
+			q, err := importCalendar(calendar)
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(int(before)))
+			Expect(q.Result.Updated).To(Equal(int(before)))
+			Expect(countTodos()).To(Equal(before))
+
+			Expect(exportCalendar()).To(ContainSubstring("UID:" + signInUser1Resp.Todos[0].ID.String() + "\r\n"))
+		})
+
+		It("reports todos it can't import", func() {
+
+			q, err := importCalendar("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:1\r\nSUMMARY:No due\r\nRRULE:FREQ=DAILY\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:2\r\nDUE:later\r\nEND:VTODO\r\nEND:VCALENDAR\r\n")
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(0))
+			Expect(q.Result.Failed).To(Equal(2))
+			Expect(q.Result.Errors[0].Errors).To(Equal([]string{"recurring todo requires a due date"}))
+			Expect(q.Result.Errors[1].Errors).To(Equal([]string{
+				"Parameters incorrectly formatted or out of range (DUE)",
+				"Required parameters not passed (Text)",
+			}))
+		})
+	})
+})
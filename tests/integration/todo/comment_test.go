@@ -0,0 +1,182 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type NewComment map[string]interface{}
+
+type UpdateComment map[string]interface{}
+
+type commentNode struct {
+	ID   string `json:"id"`
+	Body string `json:"body"`
+	User struct {
+		ID uuid.UUID `json:"id"`
+	} `json:"user"`
+}
+
+type createComment struct {
+	Comment commentNode `graphql:"createComment(todoID: $todoID, input: $input)"`
+}
+
+type updateComment struct {
+	Comment commentNode `graphql:"updateComment(commentID: $commentID, input: $input)"`
+}
+
+type deleteComment struct {
+	Deleted bool `graphql:"deleteComment(commentID: $commentID)"`
+}
+
+type todoComments struct {
+	Todos struct {
+		Edges []struct {
+			Node struct {
+				ID           uuid.UUID `json:"id"`
+				CommentCount int       `json:"commentCount" graphql:"commentCount"`
+				Comments     struct {
+					Edges []struct {
+						Cursor string      `json:"cursor"`
+						Node   commentNode `json:"node"`
+					} `json:"edges"`
+					PageInfo struct {
+						HasNextPage bool `json:"hasNextPage" graphql:"hasNextPage"`
+					} `json:"pageInfo" graphql:"pageInfo"`
+					TotalCount int `json:"totalCount" graphql:"totalCount"`
+				} `graphql:"comments(first: $first)"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `graphql:"todos(orderBy: [{field: MANUAL}])"`
+}
+
+var _ = Describe("Comments", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	comment := func(todoId uuid.UUID, body string) (commentNode, error) {
+		var q createComment
+		variables := map[string]interface{}{
+			"todoID": todoId.String(),
+			"input":  NewComment{"body": body},
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q.Comment, err
+	}
+
+	Context("Create comment", func() {
+		It("adds a comment authored by the user", func() {
+
+			c, err := comment(signInUser1Resp.Todos[0].ID, "**first**")
+			Expect(err).To(BeNil())
+			Expect(c.Body).To(Equal("**first**"))
+			Expect(c.User.ID).To(Equal(signInUser1Resp.User.ID))
+		})
+
+		It("error: todo of another user", func() {
+
+			_, err := comment(signInUser2Resp.Todos[0].ID, "hello")
+			Expect(err.Error()).To(Equal("Message: todo not found, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: empty body", func() {
+
+			_, err := comment(signInUser1Resp.Todos[0].ID, "")
+			Expect(err.Error()).To(Equal("Message: Parameters incorrectly formatted or out of range (Body), Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("List comments", func() {
+		It("pages through the comments and counts them per todo", func() {
+
+			for _, body := range []string{"one", "two", "three"} {
+				_, err := comment(signInUser1Resp.Todos[0].ID, body)
+				Expect(err).To(BeNil())
+			}
+
+			var q todoComments
+			variables := map[string]interface{}{
+				"first": 2,
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			first := q.Todos.Edges[0].Node
+			Expect(first.CommentCount).To(Equal(3))
+			Expect(first.Comments.TotalCount).To(Equal(3))
+			Expect(first.Comments.PageInfo.HasNextPage).To(BeTrue())
+			Expect(first.Comments.Edges).To(HaveLen(2))
+			Expect(first.Comments.Edges[0].Node.Body).To(Equal("one"))
+
+			Expect(q.Todos.Edges[1].Node.CommentCount).To(Equal(0))
+		})
+	})
+
+	Context("Edit and delete comment", func() {
+		It("lets the author edit and delete the comment", func() {
+
+			c, err := comment(signInUser1Resp.Todos[0].ID, "draft")
+			Expect(err).To(BeNil())
+
+			var u updateComment
+			variables := map[string]interface{}{
+				"commentID": c.ID,
+				"input":     UpdateComment{"body": "final"},
+			}
+
+			err = tools.DoMutate(&u, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(u.Comment.Body).To(Equal("final"))
+
+			var d deleteComment
+			variables = map[string]interface{}{
+				"commentID": c.ID,
+			}
+
+			err = tools.DoMutate(&d, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(d.Deleted).To(BeTrue())
+
+			var left int64
+			db.Model(&models.Comment{}).Count(&left)
+			Expect(left).To(Equal(int64(0)))
+		})
+
+		It("error: comment of another author", func() {
+
+			c := models.Comment{
+				ID:     uuid.New(),
+				TodoID: signInUser1Resp.Todos[0].ID,
+				UserID: signInUser2Resp.User.ID,
+				Body:   "someone else",
+			}
+			db.Omit("Todo", "User").Create(&c)
+
+			var d deleteComment
+			variables := map[string]interface{}{
+				"commentID": c.ID.String(),
+			}
+
+			err := tools.DoMutate(&d, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: only the author can change a comment, Locations: [], Extensions: map[]"))
+		})
+	})
+})
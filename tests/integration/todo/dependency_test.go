@@ -0,0 +1,194 @@
+package todo
+
+import (
+	"time"
+	interfacePresenter "todo-service/src/interface/presenter"
+	interfaceRepository "todo-service/src/interface/repository"
+	"todo-service/src/models"
+	"todo-service/src/usecase/interactor"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type addTodoDependency struct {
+	Todo struct {
+		ID        uuid.UUID `json:"id"`
+		BlockedBy []struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"blockedBy" graphql:"blockedBy"`
+	} `graphql:"addTodoDependency(blockerID: $blockerID, blockedID: $blockedID)"`
+}
+
+type removeTodoDependency struct {
+	Removed bool `graphql:"removeTodoDependency(blockerID: $blockerID, blockedID: $blockedID)"`
+}
+
+type dependentTodos struct {
+	Todos []struct {
+		ID     uuid.UUID `json:"id"`
+		Blocks []struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"blocks"`
+	} `graphql:"todoTree(rootID: $rootID)"`
+}
+
+var _ = Describe("Todo dependencies", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	depend := func(blocker models.Todo, blocked models.Todo) (addTodoDependency, error) {
+		var q addTodoDependency
+		variables := map[string]interface{}{
+			"blockerID": blocker.ID.String(),
+			"blockedID": blocked.ID.String(),
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	complete := func(todo models.Todo) error {
+		var q markCompleteTodo
+		variables := map[string]interface{}{
+			"todoID": todo.ID.String(),
+		}
+
+		return tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+	}
+
+	Context("Add dependency", func() {
+		It("exposes both sides of the dependency", func() {
+			a, b := signInUser1Resp.Todos[0], signInUser1Resp.Todos[1]
+
+			q, err := depend(a, b)
+			Expect(err).To(BeNil())
+			Expect(q.Todo.ID).To(Equal(b.ID))
+			Expect(q.Todo.BlockedBy).To(HaveLen(1))
+			Expect(q.Todo.BlockedBy[0].ID).To(Equal(a.ID))
+
+			var tree dependentTodos
+			variables := map[string]interface{}{
+				"rootID": a.ID.String(),
+			}
+			err = tools.DoQuery(&tree, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(tree.Todos[0].Blocks).To(HaveLen(1))
+			Expect(tree.Todos[0].Blocks[0].ID).To(Equal(b.ID))
+		})
+
+		It("error: dependency on itself", func() {
+			a := signInUser1Resp.Todos[0]
+
+			_, err := depend(a, a)
+			Expect(err.Error()).To(Equal("Message: dependency would create a cycle, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: dependency that closes a cycle", func() {
+			a, b, c := signInUser1Resp.Todos[0], signInUser1Resp.Todos[1], signInUser1Resp.Todos[2]
+
+			_, err := depend(a, b)
+			Expect(err).To(BeNil())
+			_, err = depend(b, c)
+			Expect(err).To(BeNil())
+
+			_, err = depend(c, a)
+			Expect(err.Error()).To(Equal("Message: dependency would create a cycle, Locations: [], Extensions: map[]"))
+
+			var count int64
+			Expect(db.Model(&models.TodoDependency{}).Count(&count).Error).To(BeNil())
+			Expect(count).To(Equal(int64(2)))
+		})
+
+		It("error: todo of another user", func() {
+
+			_, err := depend(signInUser2Resp.Todos[0], signInUser1Resp.Todos[0])
+			Expect(err.Error()).To(Equal("Message: todo not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Complete blocked todo", func() {
+		It("error: blocked by an open todo", func() {
+			a, b := signInUser1Resp.Todos[0], signInUser1Resp.Todos[1]
+
+			_, err := depend(a, b)
+			Expect(err).To(BeNil())
+
+			err = complete(b)
+			Expect(err.Error()).To(Equal("Message: todo is blocked by open todos, Locations: [], Extensions: map[]"))
+		})
+
+		It("completes once the blockers are done", func() {
+			a, b := signInUser1Resp.Todos[0], signInUser1Resp.Todos[1]
+
+			_, err := depend(a, b)
+			Expect(err).To(BeNil())
+
+			Expect(complete(a)).To(Succeed())
+			Expect(complete(b)).To(Succeed())
+		})
+
+		It("completes once the dependency is removed", func() {
+			a, b := signInUser1Resp.Todos[0], signInUser1Resp.Todos[1]
+
+			_, err := depend(a, b)
+			Expect(err).To(BeNil())
+
+			var q removeTodoDependency
+			variables := map[string]interface{}{
+				"blockerID": a.ID.String(),
+				"blockedID": b.ID.String(),
+			}
+			err = tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Removed).To(BeTrue())
+
+			Expect(complete(b)).To(Succeed())
+		})
+
+		It("warns instead when configured to", func() {
+			a, b := signInUser1Resp.Todos[0], signInUser1Resp.Todos[1]
+
+			_, err := depend(a, b)
+			Expect(err).To(BeNil())
+
+			todos := interactor.NewTodoInteractor(interfaceRepository.NewTodoRepository(db),
+				interfacePresenter.NewTodoPresenter(), interfaceRepository.NewDBRepository(db),
+				interfaceRepository.NewShareRepository(db), interfaceRepository.NewWorkspaceRepository(db),
+				interfaceRepository.NewTodoEventRepository(db), interfaceRepository.NewTodoUndoRepository(db),
+				interfaceRepository.NewTodoDependencyRepository(db), interfaceRepository.NewWorkflowRepository(db),
+				interfaceRepository.NewUserRepository(db), models.TodoSettings{
+					CompleteCascade: models.CascadeChildren,
+					DeleteCascade:   models.CascadeChildren,
+					PageSize:        50,
+					MaxPageSize:     100,
+					UndoWindow:      time.Minute,
+					BlockedComplete: models.BlockedWarn,
+				})
+
+			todo, err := todos.MarkComplete(b.ID.String(), signInUser1Resp.User.ID.String())
+			Expect(err).To(BeNil())
+			Expect(todo.Done).To(BeTrue())
+			Expect(todo.Warnings).To(ConsistOf(models.ErrTodoBlocked.Message))
+		})
+	})
+})
@@ -0,0 +1,146 @@
+package todo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type regenerateCalendarFeed struct {
+	Feed struct {
+		URL *string `json:"url"`
+	} `graphql:"regenerateCalendarFeed"`
+}
+
+type calendarFeed struct {
+	Feed *struct {
+		URL *string `json:"url"`
+	} `graphql:"calendarFeed"`
+}
+
+type revokeCalendarFeed struct {
+	Revoked bool `graphql:"revokeCalendarFeed"`
+}
+
+var _ = Describe("Calendar feed", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	regenerate := func() string {
+		var q regenerateCalendarFeed
+		Expect(tools.DoMutate(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		Expect(q.Feed.URL).NotTo(BeNil())
+
+		link, err := url.Parse(*q.Feed.URL)
+		Expect(err).To(BeNil())
+		Expect(link.Path).To(HavePrefix(models.CalendarFeedPath))
+		Expect(link.Path).To(HaveSuffix(models.CalendarFeedExtension))
+		return link.Path
+	}
+
+	get := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("serves the open todos without a bearer token", func() {
+		Expect(db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[0].ID).Update("done", true).Error).To(BeNil())
+
+		w := get(regenerate(), nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("text/calendar; charset=utf-8"))
+		Expect(w.Header().Get("ETag")).NotTo(BeEmpty())
+		Expect(w.Header().Get("Last-Modified")).NotTo(BeEmpty())
+		Expect(strings.Count(w.Body.String(), "BEGIN:VTODO")).To(Equal(len(signInUser1Resp.Todos) - 1))
+		Expect(w.Body.String()).NotTo(ContainSubstring("UID:" + signInUser1Resp.Todos[0].ID.String() + "\r\n"))
+		Expect(w.Body.String()).To(ContainSubstring("UID:" + signInUser1Resp.Todos[1].ID.String() + "\r\n"))
+	})
+
+	It("answers unchanged todos with 304", func() {
+		path := regenerate()
+		first := get(path, nil)
+
+		w := get(path, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+		Expect(w.Code).To(Equal(http.StatusNotModified))
+		Expect(w.Body.Len()).To(Equal(0))
+
+		w = get(path, map[string]string{"If-Modified-Since": first.Header().Get("Last-Modified")})
+		Expect(w.Code).To(Equal(http.StatusNotModified))
+
+		Expect(db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[1].ID).
+			Update("text", "changed").Error).To(BeNil())
+
+		w = get(path, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("ETag")).NotTo(Equal(first.Header().Get("ETag")))
+		Expect(w.Body.String()).To(ContainSubstring("SUMMARY:changed\r\n"))
+	})
+
+	It("filters the todos by tag and workspace", func() {
+		Expect(db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[2].ID).
+			Update("tags", models.TodoTags{"home"}).Error).To(BeNil())
+		path := regenerate()
+
+		w := get(path+"?tag=%23Home", nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(strings.Count(w.Body.String(), "BEGIN:VTODO")).To(Equal(1))
+		Expect(w.Body.String()).To(ContainSubstring("UID:" + signInUser1Resp.Todos[2].ID.String() + "\r\n"))
+
+		workspace := models.Workspace{ID: uuid.New(), Name: "A"}
+		Expect(db.Create(&workspace).Error).To(BeNil())
+		w = get(path+"?workspace="+workspace.ID.String(), nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+
+		Expect(db.Omit("Workspace", "User").Create(&models.WorkspaceMember{WorkspaceID: workspace.ID,
+			UserID: signInUser1Resp.User.ID, Role: models.WorkspaceRoleOwner}).Error).To(BeNil())
+		w = get(path+"?workspace="+workspace.ID.String(), nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).NotTo(ContainSubstring("BEGIN:VTODO"))
+	})
+
+	It("stops serving the old link once regenerated or revoked", func() {
+		old := regenerate()
+		path := regenerate()
+		Expect(path).NotTo(Equal(old))
+		Expect(get(old, nil).Code).To(Equal(http.StatusNotFound))
+		Expect(get(path, nil).Code).To(Equal(http.StatusOK))
+
+		var feed calendarFeed
+		Expect(tools.DoQuery(&feed, nil, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		Expect(feed.Feed).NotTo(BeNil())
+		Expect(feed.Feed.URL).To(BeNil())
+
+		var q revokeCalendarFeed
+		Expect(tools.DoMutate(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		Expect(q.Revoked).To(BeTrue())
+		Expect(get(path, nil).Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("error: unknown token", func() {
+		Expect(get(models.CalendarFeedPath+"unknown"+models.CalendarFeedExtension, nil).Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,92 @@
+package todo
+
+import (
+	"todo-service/tests/tools"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type TodoFilter map[string]interface{}
+
+type filteredTodos struct {
+	Todos todoPage `graphql:"todos(filter: $filter)"`
+}
+
+type orderedTodos struct {
+	Todos todoPage `graphql:"todos(orderBy: [{field: DONE, direction: DESC}, {field: TEXT, direction: DESC}])"`
+}
+
+var _ = Describe("Filter and order todos", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	Context("Filter by text", func() {
+		It("returns the matching todos only", func() {
+
+			var q filteredTodos
+			variables := map[string]interface{}{
+				"filter": TodoFilter{"textContains": "TODO_2"},
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.TotalCount).To(Equal(1))
+			Expect(q.Todos.Edges[0].Node.ID).To(Equal(signInUser1Resp.Todos[1].ID))
+		})
+
+		It("treats LIKE wildcards literally", func() {
+
+			var q filteredTodos
+			variables := map[string]interface{}{
+				"filter": TodoFilter{"textContains": "%"},
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.TotalCount).To(BeZero())
+		})
+	})
+
+	Context("Filter by done state", func() {
+		It("returns the open todos", func() {
+
+			db.Model(&signInUser1Resp.Todos[0]).Update("done", true)
+
+			var q filteredTodos
+			variables := map[string]interface{}{
+				"filter": TodoFilter{"done": false},
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.TotalCount).To(Equal(2))
+		})
+	})
+
+	Context("Order by several keys", func() {
+		It("sorts by done first and text second", func() {
+
+			db.Model(&signInUser1Resp.Todos[0]).Update("done", true)
+
+			var q orderedTodos
+			err := tools.DoQuery(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.Edges).To(HaveLen(3))
+			Expect(q.Todos.Edges[0].Node.ID).To(Equal(signInUser1Resp.Todos[0].ID))
+			Expect(q.Todos.Edges[1].Node.ID).To(Equal(signInUser1Resp.Todos[2].ID))
+			Expect(q.Todos.Edges[2].Node.ID).To(Equal(signInUser1Resp.Todos[1].ID))
+		})
+	})
+})
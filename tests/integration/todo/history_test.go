@@ -0,0 +1,166 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type updateTodoText struct {
+	Data struct {
+		ID uuid.UUID `json:"id"`
+	} `graphql:"updateTodo(todoID: $todoID, input: {text:$text}, scope: THIS_OCCURRENCE)"`
+}
+
+type todoEvent struct {
+	Actor struct {
+		ID uuid.UUID `json:"id"`
+	} `json:"actor"`
+	Action   models.TodoEventAction `json:"action"`
+	Field    *string                `json:"field"`
+	OldValue *string                `json:"oldValue" graphql:"oldValue"`
+	NewValue *string                `json:"newValue" graphql:"newValue"`
+}
+
+type todoHistory struct {
+	Todos []struct {
+		History []todoEvent `json:"history"`
+	} `graphql:"todoTree(rootID: $rootID)"`
+}
+
+var _ = Describe("Todo history", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	history := func(id uuid.UUID, access string) ([]todoEvent, error) {
+		var q todoHistory
+		variables := map[string]interface{}{
+			"rootID": id.String(),
+		}
+
+		err := tools.DoQuery(&q, variables, access, router)
+		if err != nil || len(q.Todos) == 0 {
+			return nil, err
+		}
+
+		return q.Todos[0].History, nil
+	}
+
+	value := func(v string) *string {
+		return &v
+	}
+
+	It("records creating, editing and completing a todo in order", func() {
+		access := signInUser1Resp.Auth.Data.AccessToken
+
+		var created createTodo
+		err := tools.DoMutate(&created, map[string]interface{}{"text": "draft"}, access, router)
+		Expect(err).To(BeNil())
+		id := created.Data.ID
+
+		var updated updateTodoText
+		variables := map[string]interface{}{
+			"todoID": id.String(),
+			"text":   "final",
+		}
+		err = tools.DoMutate(&updated, variables, access, router)
+		Expect(err).To(BeNil())
+
+		var completed markCompleteTodo
+		err = tools.DoMutate(&completed, map[string]interface{}{"todoID": id.String()}, access, router)
+		Expect(err).To(BeNil())
+
+		events, err := history(id, access)
+		Expect(err).To(BeNil())
+		Expect(events).To(HaveLen(3))
+
+		Expect(events[0].Action).To(Equal(models.TodoEventCreated))
+		Expect(events[0].Field).To(BeNil())
+
+		Expect(events[1].Action).To(Equal(models.TodoEventUpdated))
+		Expect(events[1].Field).To(Equal(value("text")))
+		Expect(events[1].OldValue).To(Equal(value("draft")))
+		Expect(events[1].NewValue).To(Equal(value("final")))
+
+		Expect(events[2].Action).To(Equal(models.TodoEventCompleted))
+		Expect(events[2].Field).To(Equal(value("done")))
+		Expect(events[2].OldValue).To(Equal(value("false")))
+		Expect(events[2].NewValue).To(Equal(value("true")))
+
+		for _, event := range events {
+			Expect(event.Actor.ID).To(Equal(signInUser1Resp.User.ID))
+		}
+	})
+
+	It("records the user who made the change rather than the owner", func() {
+		todo := signInUser1Resp.Todos[0]
+		share := models.Share{
+			ID:     uuid.New(),
+			TodoID: todo.ID,
+			UserID: signInUser2Resp.User.ID,
+			Role:   models.TodoRoleEditor,
+		}
+		Expect(db.Omit("Todo", "User").Create(&share).Error).To(BeNil())
+
+		var completed markCompleteTodo
+		err := tools.DoMutate(&completed, map[string]interface{}{"todoID": todo.ID.String()}, signInUser2Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+
+		events, err := history(todo.ID, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Actor.ID).To(Equal(signInUser2Resp.User.ID))
+	})
+
+	It("records deleting and restoring a todo", func() {
+		todo := signInUser1Resp.Todos[0]
+		access := signInUser1Resp.Auth.Data.AccessToken
+
+		var deleted deleteTodo
+		err := tools.DoMutate(&deleted, map[string]interface{}{"todoID": todo.ID.String()}, access, router)
+		Expect(err).To(BeNil())
+		Expect(deleted.DeleteTodoRes).To(BeTrue())
+
+		var restored restoreTodo
+		err = tools.DoMutate(&restored, map[string]interface{}{"todoID": todo.ID.String()}, access, router)
+		Expect(err).To(BeNil())
+
+		events, err := history(todo.ID, access)
+		Expect(err).To(BeNil())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Action).To(Equal(models.TodoEventDeleted))
+		Expect(events[1].Action).To(Equal(models.TodoEventRestored))
+	})
+
+	It("doesn't record a change that fails", func() {
+		todo := signInUser1Resp.Todos[0]
+
+		var completed markCompleteTodo
+		err := tools.DoMutate(&completed, map[string]interface{}{"todoID": todo.ID.String()}, signInUser2Resp.Auth.Data.AccessToken, router)
+		Expect(err).ToNot(BeNil())
+
+		var count int64
+		Expect(db.Model(&models.TodoEvent{}).Where("todo_id = ?", todo.ID).Count(&count).Error).To(BeNil())
+		Expect(count).To(BeZero())
+	})
+})
@@ -0,0 +1,160 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type moveTodo struct {
+	Todo struct {
+		ID       uuid.UUID `json:"id"`
+		Position float64   `json:"position"`
+	} `graphql:"moveTodo(todoID: $todoID, beforeId: $beforeId, afterId: $afterId)"`
+}
+
+type manualTodos struct {
+	Todos todoPage `graphql:"todos(orderBy: [{field: MANUAL}])"`
+}
+
+var _ = Describe("Manual order of todos", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	moveAs := func(token string, id uuid.UUID, beforeId *string, afterId *string) error {
+		var q moveTodo
+		variables := map[string]interface{}{
+			"todoID":   id.String(),
+			"beforeId": beforeId,
+			"afterId":  afterId,
+		}
+
+		return tools.DoMutate(&q, variables, token, router)
+	}
+
+	move := func(id uuid.UUID, beforeId *string, afterId *string) error {
+		return moveAs(signInUser1Resp.Auth.Data.AccessToken, id, beforeId, afterId)
+	}
+
+	manualOrder := func() []uuid.UUID {
+		var q manualTodos
+		err := tools.DoQuery(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+
+		ids := make([]uuid.UUID, 0, len(q.Todos.Edges))
+		for _, edge := range q.Todos.Edges {
+			ids = append(ids, edge.Node.ID)
+		}
+		return ids
+	}
+
+	Context("Move a todo to the top", func() {
+		It("updates only the moved todo", func() {
+
+			todos := signInUser1Resp.Todos
+			before := todos[0].ID.String()
+
+			err := move(todos[2].ID, &before, nil)
+			Expect(err).To(BeNil())
+			Expect(manualOrder()).To(Equal([]uuid.UUID{todos[2].ID, todos[0].ID, todos[1].ID}))
+		})
+	})
+
+	Context("Move a todo between two others", func() {
+		It("rebalances tied positions and keeps the order", func() {
+
+			todos := signInUser1Resp.Todos
+			after, before := todos[1].ID.String(), todos[2].ID.String()
+
+			err := move(todos[0].ID, &before, &after)
+			Expect(err).To(BeNil())
+			Expect(manualOrder()).To(Equal([]uuid.UUID{todos[1].ID, todos[0].ID, todos[2].ID}))
+		})
+
+		It("error: neighbours in the wrong order", func() {
+
+			todos := signInUser1Resp.Todos
+			db.Model(&models.Todo{}).Where("id = ?", todos[2].ID).Update("position", 2*models.TodoPositionStep)
+			after, before := todos[2].ID.String(), todos[0].ID.String()
+
+			err := move(todos[1].ID, &before, &after)
+			Expect(err.Error()).To(Equal("Message: afterId must come before beforeId, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: neighbour of another user", func() {
+
+			before := signInUser2Resp.Todos[0].ID.String()
+
+			err := move(signInUser1Resp.Todos[0].ID, &before, nil)
+			Expect(err.Error()).To(Equal("Message: todo not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Move a shared todo", func() {
+		share := func(todo models.Todo, role models.TodoRole) {
+			share := models.Share{
+				ID:     uuid.New(),
+				TodoID: todo.ID,
+				UserID: signInUser2Resp.User.ID,
+				Role:   role,
+			}
+			Expect(db.Omit("Todo", "User").Create(&share).Error).To(BeNil())
+
+			var err error
+			signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+			Expect(err).To(BeNil())
+		}
+
+		It("moves it in the order of its owner for an editor", func() {
+
+			todos := signInUser1Resp.Todos
+			share(todos[2], models.TodoRoleEditor)
+			before := todos[0].ID.String()
+
+			err := moveAs(signInUser2Resp.Auth.Data.AccessToken, todos[2].ID, &before, nil)
+			Expect(err).To(BeNil())
+			Expect(manualOrder()).To(Equal([]uuid.UUID{todos[2].ID, todos[0].ID, todos[1].ID}))
+		})
+
+		It("error: viewer", func() {
+
+			todos := signInUser1Resp.Todos
+			share(todos[2], models.TodoRoleViewer)
+			before := todos[0].ID.String()
+
+			err := moveAs(signInUser2Resp.Auth.Data.AccessToken, todos[2].ID, &before, nil)
+			Expect(err.Error()).To(Equal("Message: " + models.ErrTodoForbidden.Message + ", Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Create a todo", func() {
+		It("appends it to the end of the manual order", func() {
+
+			var q createTodo
+			variables := map[string]interface{}{
+				"text": "last",
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			order := manualOrder()
+			Expect(order[len(order)-1]).To(Equal(q.Data.ID))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package todo
+
+import (
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type todoPage struct {
+	Edges []struct {
+		Cursor string `json:"cursor"`
+		Node   struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"node"`
+	} `json:"edges"`
+	PageInfo struct {
+		HasNextPage     bool    `json:"hasNextPage"`
+		HasPreviousPage bool    `json:"hasPreviousPage"`
+		EndCursor       *string `json:"endCursor"`
+	} `json:"pageInfo"`
+	TotalCount int `json:"totalCount"`
+}
+
+type firstTodos struct {
+	Todos todoPage `graphql:"todos(first: $first)"`
+}
+
+type firstTodosAfter struct {
+	Todos todoPage `graphql:"todos(first: $first, after: $after)"`
+}
+
+type lastTodos struct {
+	Todos todoPage `graphql:"todos(last: $last)"`
+}
+
+var _ = Describe("Paginate todos", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	Context("Walk forward with first and after", func() {
+		It("returns consecutive pages", func() {
+
+			var first firstTodos
+			err := tools.DoQuery(&first, map[string]interface{}{"first": 2}, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(first.Todos.TotalCount).To(Equal(3))
+			Expect(first.Todos.Edges).To(HaveLen(2))
+			Expect(first.Todos.Edges[0].Node.ID).To(Equal(signInUser1Resp.Todos[0].ID))
+			Expect(first.Todos.PageInfo.HasNextPage).To(BeTrue())
+			Expect(first.Todos.PageInfo.HasPreviousPage).To(BeFalse())
+
+			var next firstTodosAfter
+			variables := map[string]interface{}{
+				"first": 2,
+				"after": *first.Todos.PageInfo.EndCursor,
+			}
+
+			err = tools.DoQuery(&next, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(next.Todos.Edges).To(HaveLen(1))
+			Expect(next.Todos.Edges[0].Node.ID).To(Equal(signInUser1Resp.Todos[2].ID))
+			Expect(next.Todos.PageInfo.HasNextPage).To(BeFalse())
+			Expect(next.Todos.PageInfo.HasPreviousPage).To(BeTrue())
+		})
+	})
+
+	Context("Walk backward with last", func() {
+		It("returns the tail in order", func() {
+
+			var q lastTodos
+			err := tools.DoQuery(&q, map[string]interface{}{"last": 2}, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.Edges).To(HaveLen(2))
+			Expect(q.Todos.Edges[0].Node.ID).To(Equal(signInUser1Resp.Todos[1].ID))
+			Expect(q.Todos.Edges[1].Node.ID).To(Equal(signInUser1Resp.Todos[2].ID))
+			Expect(q.Todos.PageInfo.HasPreviousPage).To(BeTrue())
+		})
+	})
+
+	Context("With a malformed cursor", func() {
+		It("error: invalid cursor", func() {
+
+			var q firstTodosAfter
+			variables := map[string]interface{}{
+				"first": 2,
+				"after": "not-a-cursor",
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: invalid cursor, Locations: [], Extensions: map[]"))
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package todo
+
+import (
+	"time"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type TodoPriority string
+
+type parseTodo struct {
+	Parsed struct {
+		Text     string     `json:"text"`
+		DueAt    *time.Time `json:"dueAt" graphql:"dueAt"`
+		Priority string     `json:"priority"`
+		Tags     []string   `json:"tags"`
+		Rrule    *string    `json:"rrule"`
+	} `graphql:"parseTodo(text: $text)"`
+}
+
+type quickAddTodo struct {
+	Todo struct {
+		ID       uuid.UUID  `json:"id"`
+		Text     string     `json:"text"`
+		DueAt    *time.Time `json:"dueAt" graphql:"dueAt"`
+		Priority string     `json:"priority"`
+		Tags     []string   `json:"tags"`
+		Rrule    *string    `json:"rrule"`
+	} `graphql:"createTodo(input: {text: $text, parse: true, priority: $priority, tags: $tags})"`
+}
+
+var _ = Describe("Quick-add", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	It("previews the parsed todo", func() {
+
+		var q parseTodo
+		variables := map[string]interface{}{
+			"text": "Pay rent tomorrow 9am !high #finance every month",
+		}
+		err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		Expect(q.Parsed.Text).To(Equal("Pay rent"))
+		Expect(q.Parsed.Priority).To(Equal("HIGH"))
+		Expect(q.Parsed.Tags).To(Equal([]string{"finance"}))
+		Expect(q.Parsed.Rrule).NotTo(BeNil())
+		Expect(*q.Parsed.Rrule).To(Equal("FREQ=MONTHLY"))
+		Expect(q.Parsed.DueAt).NotTo(BeNil())
+		Expect(q.Parsed.DueAt.UTC().Hour()).To(Equal(9))
+
+		var count int64
+		Expect(db.Table("todos").Count(&count).Error).To(BeNil())
+		Expect(count).To(BeZero())
+	})
+
+	It("creates the parsed todo with given fields winning", func() {
+
+		var q quickAddTodo
+		variables := map[string]interface{}{
+			"text":     "Pay rent tomorrow 9am !high #finance every month",
+			"priority": TodoPriority("LOW"),
+			"tags":     []string{"home"},
+		}
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		Expect(q.Todo.Text).To(Equal("Pay rent"))
+		Expect(q.Todo.Priority).To(Equal("LOW"))
+		Expect(q.Todo.Tags).To(Equal([]string{"home", "finance"}))
+		Expect(q.Todo.Rrule).NotTo(BeNil())
+		Expect(q.Todo.DueAt).NotTo(BeNil())
+		Expect(q.Todo.DueAt.After(time.Now())).To(BeTrue())
+	})
+})
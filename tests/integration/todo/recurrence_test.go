@@ -0,0 +1,99 @@
+package todo
+
+import (
+	"time"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type createRecurringTodo struct {
+	Data struct {
+		ID    uuid.UUID `json:"id"`
+		Rrule *string   `json:"rrule"`
+		DueAt time.Time `json:"dueAt"`
+	} `graphql:"createTodo(input: {text:$text, dueAt:$dueAt, rrule:$rrule})"`
+}
+
+type updateTodoRRule struct {
+	Data struct {
+		ID uuid.UUID `json:"id"`
+	} `graphql:"updateTodo(todoID: $todoID, input: {rrule:$rrule}, scope: THIS_OCCURRENCE)"`
+}
+
+var _ = Describe("Recurring todos", func() {
+	dueAt := time.Date(2026, 3, 7, 14, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	createDaily := func() createRecurringTodo {
+		var q createRecurringTodo
+		variables := map[string]interface{}{
+			"text":  "water plants",
+			"dueAt": dueAt,
+			"rrule": "RRULE:FREQ=DAILY",
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		return q
+	}
+
+	Context("Create todo with a rule", func() {
+		It("stores the normalized rule", func() {
+			q := createDaily()
+			Expect(q.Data.Rrule).ToNot(BeNil())
+			Expect(*q.Data.Rrule).To(Equal("FREQ=DAILY"))
+			Expect(q.Data.DueAt).To(BeTemporally("==", dueAt))
+		})
+	})
+
+	Context("Mark complete an occurrence", func() {
+		It("generates the next occurrence", func() {
+			created := createDaily()
+
+			var q markCompleteTodo
+			variables := map[string]interface{}{
+				"todoID": created.Data.ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			var next models.Todo
+			err = db.Where("done = false AND user_id = ?", signInUser1Resp.User.ID).Take(&next).Error
+			Expect(err).To(BeNil())
+			Expect(next.ID).ToNot(Equal(created.Data.ID))
+			Expect(*next.DueAt).To(BeTemporally("==", dueAt.AddDate(0, 0, 1)))
+		})
+	})
+
+	Context("Change the rule of a single occurrence", func() {
+		It("error: rule can only be changed for future occurrences", func() {
+			created := createDaily()
+
+			var q updateTodoRRule
+			variables := map[string]interface{}{
+				"todoID": created.Data.ID.String(),
+				"rrule":  "FREQ=WEEKLY",
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: recurrence rule can only be changed for future occurrences, Locations: [], Extensions: map[]"))
+		})
+	})
+})
@@ -0,0 +1,224 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type searchTodos struct {
+	Results []struct {
+		Todo struct {
+			ID   uuid.UUID `json:"id"`
+			User struct {
+				ID uuid.UUID `json:"id"`
+			} `json:"user"`
+		} `json:"todo"`
+		Rank    float64 `json:"rank"`
+		Snippet string  `json:"snippet"`
+	} `graphql:"searchTodos(query: $query)"`
+}
+
+type setWorkspaceSearchLanguage struct {
+	Workspace struct {
+		SearchLanguage string `json:"searchLanguage" graphql:"searchLanguage"`
+	} `graphql:"setWorkspaceSearchLanguage(workspaceID: $workspaceID, language: $language)"`
+}
+
+type setSearchLanguage struct {
+	User struct {
+		SearchLanguage string `json:"searchLanguage" graphql:"searchLanguage"`
+	} `graphql:"setSearchLanguage(language: $language)"`
+}
+
+var _ = Describe("Search todos", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		texts := []string{"buy fresh milk", "call the plumber about the kitchen sink", "buy a new kitchen table"}
+		for i, text := range texts {
+			db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[i].ID).Update("text", text)
+		}
+		db.Model(&models.Todo{}).Where("id = ?", signInUser2Resp.Todos[0].ID).Update("text", "buy milk too")
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	Context("Search by words", func() {
+		It("returns only matching todos of the user", func() {
+
+			var q searchTodos
+			variables := map[string]interface{}{
+				"query": "buy",
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results).To(HaveLen(2))
+		})
+
+		It("highlights the matched words in the snippet", func() {
+
+			var q searchTodos
+			variables := map[string]interface{}{
+				"query": "milk",
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results).To(HaveLen(1))
+			Expect(q.Results[0].Todo.ID).To(Equal(signInUser1Resp.Todos[0].ID))
+			Expect(q.Results[0].Snippet).To(ContainSubstring("<mark>milk</mark>"))
+		})
+	})
+
+	Context("Search by prefix", func() {
+		It("matches words starting with the prefix", func() {
+
+			var q searchTodos
+			variables := map[string]interface{}{
+				"query": "kitch*",
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results).To(HaveLen(2))
+		})
+	})
+
+	Context("Search by phrase", func() {
+		It("matches the words in order only", func() {
+
+			var q searchTodos
+			variables := map[string]interface{}{
+				"query": `"kitchen table"`,
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results).To(HaveLen(1))
+			Expect(q.Results[0].Todo.ID).To(Equal(signInUser1Resp.Todos[2].ID))
+		})
+	})
+
+	Context("Search a workspace", func() {
+		It("returns each todo with the user who created it", func() {
+
+			workspace := models.Workspace{ID: uuid.New(), Name: "Home"}
+			Expect(db.Create(&workspace).Error).To(BeNil())
+			members := []models.WorkspaceMember{
+				{WorkspaceID: workspace.ID, UserID: signInUser1Resp.User.ID, Role: models.WorkspaceRoleOwner},
+				{WorkspaceID: workspace.ID, UserID: signInUser2Resp.User.ID, Role: models.WorkspaceRoleMember},
+			}
+			Expect(db.Omit("Workspace", "User").Create(&members).Error).To(BeNil())
+			Expect(db.Model(&models.Todo{}).Where("id IN ?", []uuid.UUID{signInUser1Resp.Todos[0].ID, signInUser2Resp.Todos[0].ID}).
+				Update("workspace_id", workspace.ID).Error).To(BeNil())
+
+			var q searchTodos
+			variables := map[string]interface{}{
+				"query": "milk",
+			}
+
+			err := tools.DoQueryInWorkspace(&q, variables, signInUser1Resp.Auth.Data.AccessToken, workspace.ID.String(), router)
+			Expect(err).To(BeNil())
+			Expect(q.Results).To(HaveLen(2))
+			for _, result := range q.Results {
+				creator := signInUser1Resp.User.ID
+				if result.Todo.ID == signInUser2Resp.Todos[0].ID {
+					creator = signInUser2Resp.User.ID
+				}
+				Expect(result.Todo.User.ID).To(Equal(creator))
+			}
+		})
+	})
+
+	Context("Set search language", func() {
+		It("stems words with the new language", func() {
+
+			var m setSearchLanguage
+			variables := map[string]interface{}{
+				"language": "english",
+			}
+
+			err := tools.DoMutate(&m, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(m.User.SearchLanguage).To(Equal("english"))
+
+			var q searchTodos
+			variables = map[string]interface{}{
+				"query": "calling",
+			}
+
+			err = tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Results).To(HaveLen(1))
+		})
+
+		It("keeps the todos of workspaces on the language of the workspace", func() {
+
+			workspace := models.Workspace{ID: uuid.New(), Name: "Home"}
+			Expect(db.Create(&workspace).Error).To(BeNil())
+			members := []models.WorkspaceMember{
+				{WorkspaceID: workspace.ID, UserID: signInUser1Resp.User.ID, Role: models.WorkspaceRoleOwner},
+				{WorkspaceID: workspace.ID, UserID: signInUser2Resp.User.ID, Role: models.WorkspaceRoleMember},
+			}
+			Expect(db.Omit("Workspace", "User").Create(&members).Error).To(BeNil())
+			Expect(db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[1].ID).
+				Update("workspace_id", workspace.ID).Error).To(BeNil())
+
+			var m setSearchLanguage
+			variables := map[string]interface{}{
+				"language": "english",
+			}
+			Expect(tools.DoMutate(&m, variables, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+
+			language := func(id uuid.UUID) string {
+				var todo models.Todo
+				Expect(db.Take(&todo, "id = ?", id).Error).To(BeNil())
+				return todo.SearchLanguage
+			}
+			Expect(language(signInUser1Resp.Todos[0].ID)).To(Equal("english"))
+			Expect(language(signInUser1Resp.Todos[1].ID)).To(Equal("simple"))
+
+			var w setWorkspaceSearchLanguage
+			variables = map[string]interface{}{
+				"workspaceID": workspace.ID.String(),
+				"language":    "german",
+			}
+
+			var err error
+			signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+			Expect(err).To(BeNil())
+			err = tools.DoMutate(&w, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(ContainSubstring(models.ErrWorkspaceForbidden.Message))
+
+			Expect(tools.DoMutate(&w, variables, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+			Expect(w.Workspace.SearchLanguage).To(Equal("german"))
+			Expect(language(signInUser1Resp.Todos[1].ID)).To(Equal("german"))
+			Expect(language(signInUser1Resp.Todos[0].ID)).To(Equal("english"))
+		})
+
+		It("error: search language not found", func() {
+
+			var m setSearchLanguage
+			variables := map[string]interface{}{
+				"language": "klingon",
+			}
+
+			err := tools.DoMutate(&m, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: search language not found, Locations: [], Extensions: map[]"))
+		})
+	})
+})
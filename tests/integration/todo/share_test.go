@@ -0,0 +1,175 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type TodoRole string
+
+type shareTodos struct {
+	Shares []struct {
+		TodoID string          `json:"todoId" graphql:"todoId"`
+		Role   models.TodoRole `json:"role"`
+	} `graphql:"shareTodos(todoIds: $todoIds, email: $email, role: $role)"`
+}
+
+type unshareTodo struct {
+	Removed bool `graphql:"unshareTodo(todoID: $todoID, userID: $userID)"`
+}
+
+type sharedTodos struct {
+	Todos struct {
+		Edges []struct {
+			Node struct {
+				ID    uuid.UUID `json:"id"`
+				Owner struct {
+					ID uuid.UUID `json:"id"`
+				} `json:"owner"`
+				MyRole models.TodoRole `json:"myRole" graphql:"myRole"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `graphql:"todos(first: 50)"`
+}
+
+var _ = Describe("Sharing todos", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	share := func(role TodoRole, todos ...models.Todo) error {
+		ids := make([]string, len(todos))
+		for i, todo := range todos {
+			ids[i] = todo.ID.String()
+		}
+
+		var q shareTodos
+		variables := map[string]interface{}{
+			"todoIds": ids,
+			"email":   signInUser2Resp.User.Email,
+			"role":    role,
+		}
+
+		return tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+	}
+
+	complete := func(id uuid.UUID) error {
+		var q markCompleteTodo
+		variables := map[string]interface{}{
+			"todoID": id.String(),
+		}
+
+		return tools.DoMutate(&q, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+	}
+
+	Context("Share todos", func() {
+		It("lists the shared todos of the recipient with their owner and role", func() {
+
+			err := share("VIEWER", signInUser1Resp.Todos[0], signInUser1Resp.Todos[1])
+			Expect(err).To(BeNil())
+
+			var q sharedTodos
+			err = tools.DoQuery(&q, nil, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.Edges).To(HaveLen(len(signInUser2Resp.Todos) + 2))
+
+			roles := map[uuid.UUID]models.TodoRole{}
+			for _, edge := range q.Todos.Edges {
+				roles[edge.Node.ID] = edge.Node.MyRole
+				if edge.Node.ID == signInUser1Resp.Todos[0].ID {
+					Expect(edge.Node.Owner.ID).To(Equal(signInUser1Resp.User.ID))
+				}
+			}
+			Expect(roles[signInUser1Resp.Todos[0].ID]).To(Equal(models.TodoRoleViewer))
+			Expect(roles[signInUser2Resp.Todos[0].ID]).To(Equal(models.TodoRoleOwner))
+		})
+
+		It("error: todo of another user", func() {
+
+			var q shareTodos
+			variables := map[string]interface{}{
+				"todoIds": []string{signInUser2Resp.Todos[0].ID.String()},
+				"email":   signInUser2Resp.User.Email,
+				"role":    TodoRole("EDITOR"),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: todo not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Roles", func() {
+		It("lets an editor complete the todo and its subtasks", func() {
+
+			subtasks := addSubtasksToDb(signInUser1Resp.Todos[0])
+			Expect(share("EDITOR", signInUser1Resp.Todos[0])).To(BeNil())
+
+			Expect(complete(subtasks[0].ID)).To(BeNil())
+
+			var done models.Todo
+			db.Take(&done, "id = ?", subtasks[0].ID)
+			Expect(done.Done).To(BeTrue())
+		})
+
+		It("error: a viewer can't change the todo", func() {
+
+			Expect(share("VIEWER", signInUser1Resp.Todos[0])).To(BeNil())
+
+			err := complete(signInUser1Resp.Todos[0].ID)
+			Expect(err.Error()).To(Equal("Message: you don't have permission to do this with the todo, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: an editor can't delete the todo", func() {
+
+			Expect(share("EDITOR", signInUser1Resp.Todos[0])).To(BeNil())
+
+			var q deleteTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser1Resp.Todos[0].ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: you don't have permission to do this with the todo, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Unshare todo", func() {
+		It("takes the access away from the recipient", func() {
+
+			Expect(share("EDITOR", signInUser1Resp.Todos[0])).To(BeNil())
+
+			var q unshareTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser1Resp.Todos[0].ID.String(),
+				"userID": signInUser2Resp.User.ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Removed).To(BeTrue())
+
+			err = complete(signInUser1Resp.Todos[0].ID)
+			Expect(err.Error()).To(Equal("Message: record not found, Locations: [], Extensions: map[]"))
+		})
+	})
+})
@@ -0,0 +1,226 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type createSubtask struct {
+	Data struct {
+		ID       uuid.UUID `json:"id"`
+		Text     string    `json:"text"`
+		ParentID *string   `json:"parentId" graphql:"parentId"`
+	} `graphql:"createTodo(input: {text:$text, parentId:$parentId})"`
+}
+
+type todoTree struct {
+	Tree []struct {
+		ID       uuid.UUID `json:"id"`
+		Progress float64   `json:"progress"`
+		Children []struct {
+			ID       uuid.UUID `json:"id"`
+			Done     bool      `json:"done"`
+			Progress float64   `json:"progress"`
+		} `json:"children"`
+	} `graphql:"todoTree(rootID: $rootID)"`
+}
+
+type todoProgressPage struct {
+	Todos struct {
+		Edges []struct {
+			Node struct {
+				ID       uuid.UUID `json:"id"`
+				Progress float64   `json:"progress"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `graphql:"todos(first: 50)"`
+}
+
+func resetTodoTables() {
+	err := db.Migrator().DropTable(&models.Comment{}, &models.Share{}, &models.TodoEvent{}, &models.TodoUndo{}, &models.TrashEmptied{},
+		&models.TodoDependency{}, &models.TimeEntry{}, &models.TodoTemplate{}, &models.WorkflowTransition{}, &models.WorkflowStatus{},
+		&models.WorkspaceInvitation{}, &models.WorkspaceMember{}, &models.Workspace{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = db.Migrator().DropTable(&models.Todo{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = db.Migrator().DropTable(&models.CalendarFeed{}, &models.AppPassword{}, &models.User{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = db.AutoMigrate(&models.User{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.Workspace{}, &models.WorkspaceMember{}, &models.WorkspaceInvitation{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.WorkflowStatus{}, &models.WorkflowTransition{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.Todo{})
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&models.Comment{}, &models.Share{}, &models.TodoEvent{}, &models.TodoUndo{}, &models.TrashEmptied{},
+		&models.TodoDependency{}, &models.TimeEntry{}, &models.TodoTemplate{}, &models.CalendarFeed{},
+		&models.AppPassword{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func addSubtasksToDb(parent models.Todo) []models.Todo {
+	subtasks := []models.Todo{
+		{
+			ID:       uuid.MustParse("0c1d7a02-3f45-4b0e-9a77-2c8f0e0d5b11"),
+			Text:     "subtask_1",
+			UserID:   parent.UserID,
+			ParentID: &parent.ID,
+		},
+		{
+			ID:       uuid.MustParse("5b7e3d4c-2a1f-4c9d-8e6b-7f0a1b2c3d44"),
+			Text:     "subtask_2",
+			Done:     true,
+			UserID:   parent.UserID,
+			ParentID: &parent.ID,
+		},
+	}
+
+	result := db.Create(&subtasks)
+	if result.Error != nil {
+		panic(result.Error)
+	}
+
+	return subtasks
+}
+
+var _ = Describe("Subtasks", func() {
+	var subtasks []models.Todo
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+		subtasks = addSubtasksToDb(signInUser1Resp.Todos[0])
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	Context("Create todo with a parent of the same user", func() {
+		It("success create subtask", func() {
+
+			var q createSubtask
+			parentID := signInUser1Resp.Todos[1].ID.String()
+			variables := map[string]interface{}{
+				"text":     "child",
+				"parentId": parentID,
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Data.ParentID).ToNot(BeNil())
+			Expect(*q.Data.ParentID).To(Equal(parentID))
+		})
+	})
+
+	Context("Create todo with a parent of another user", func() {
+		It("error: parent todo not found", func() {
+
+			var q createSubtask
+			variables := map[string]interface{}{
+				"text":     "child",
+				"parentId": signInUser2Resp.Todos[0].ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: parent todo not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Load a subtree", func() {
+		It("returns the nested children with rolled up progress", func() {
+
+			var q todoTree
+			variables := map[string]interface{}{
+				"rootID": signInUser1Resp.Todos[0].ID.String(),
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Tree).To(HaveLen(1))
+			Expect(q.Tree[0].ID).To(Equal(signInUser1Resp.Todos[0].ID))
+			Expect(q.Tree[0].Children).To(HaveLen(len(subtasks)))
+			Expect(q.Tree[0].Progress).To(Equal(0.5))
+		})
+	})
+
+	Context("List todos with their progress", func() {
+		It("rolls up the progress of every todo of the page", func() {
+
+			var q todoProgressPage
+			err := tools.DoQuery(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			progress := make(map[uuid.UUID]float64, len(q.Todos.Edges))
+			for _, edge := range q.Todos.Edges {
+				progress[edge.Node.ID] = edge.Node.Progress
+			}
+			Expect(progress).To(HaveKeyWithValue(signInUser1Resp.Todos[0].ID, 0.5))
+			Expect(progress).To(HaveKeyWithValue(signInUser1Resp.Todos[1].ID, 0.0))
+		})
+	})
+
+	Context("Mark complete a parent", func() {
+		It("completes every subtask", func() {
+
+			var q markCompleteTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser1Resp.Todos[0].ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			var open int64
+			db.Model(&models.Todo{}).Where("parent_id = ? AND done = false", signInUser1Resp.Todos[0].ID).Count(&open)
+			Expect(open).To(BeZero())
+		})
+	})
+
+	Context("Delete a parent", func() {
+		It("deletes every subtask", func() {
+
+			var q deleteTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser1Resp.Todos[0].ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.DeleteTodoRes).To(BeTrue())
+
+			var left int64
+			db.Model(&models.Todo{}).Where("parent_id = ?", signInUser1Resp.Todos[0].ID).Count(&left)
+			Expect(left).To(BeZero())
+		})
+	})
+})
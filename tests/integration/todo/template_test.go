@@ -0,0 +1,240 @@
+package todo
+
+import (
+	"strings"
+	"time"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type TodoTemplateItemInput struct {
+	Text      string                  `json:"text"`
+	DueOffset *int                    `json:"dueOffset,omitempty"`
+	Children  []TodoTemplateItemInput `json:"children,omitempty"`
+}
+
+type NewTodoTemplate struct {
+	Name  string                  `json:"name"`
+	Items []TodoTemplateItemInput `json:"items"`
+}
+
+type TemplateVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type todoTemplate struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Items []struct {
+		Text      string              `json:"text"`
+		DueOffset *int                `json:"dueOffset" graphql:"dueOffset"`
+		Priority  models.TodoPriority `json:"priority"`
+		Tags      []string            `json:"tags"`
+		Children  []struct {
+			Text      string `json:"text"`
+			DueOffset *int   `json:"dueOffset" graphql:"dueOffset"`
+		} `json:"children"`
+	} `json:"items"`
+}
+
+type createTodoTemplate struct {
+	Template todoTemplate `graphql:"createTodoTemplate(input: $input)"`
+}
+
+type saveTodoAsTemplate struct {
+	Template todoTemplate `graphql:"saveTodoAsTemplate(todoID: $todoID, name: $name)"`
+}
+
+type instantiateTemplate struct {
+	Todos []struct {
+		ID       uuid.UUID           `json:"id"`
+		Text     string              `json:"text"`
+		DueAt    *time.Time          `json:"dueAt" graphql:"dueAt"`
+		Priority models.TodoPriority `json:"priority"`
+		Tags     []string            `json:"tags"`
+		Children []struct {
+			Text  string     `json:"text"`
+			DueAt *time.Time `json:"dueAt" graphql:"dueAt"`
+		} `json:"children"`
+	} `graphql:"instantiateTemplate(templateID: $templateID, variables: $variables, start: $start)"`
+}
+
+func offset(d time.Duration) *int {
+	seconds := int(d / time.Second)
+	return &seconds
+}
+
+var _ = Describe("Todo templates", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	onboarding := NewTodoTemplate{
+		Name: "Onboarding",
+		Items: []TodoTemplateItemInput{
+			{
+				Text:      "Onboard {{name}} on {{date}}",
+				DueOffset: offset(7 * 24 * time.Hour),
+				Children: []TodoTemplateItemInput{
+					{Text: "Set up a laptop for {{ name }}", DueOffset: offset(0)},
+					{Text: "Introduce the team"},
+				},
+			},
+			{Text: "Check in after a month", DueOffset: offset(30 * 24 * time.Hour)},
+		},
+	}
+
+	create := func(input NewTodoTemplate) (createTodoTemplate, error) {
+		var q createTodoTemplate
+		variables := map[string]interface{}{
+			"input": input,
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	instantiate := func(id uuid.UUID, values []TemplateVariable, start time.Time, access string) (instantiateTemplate, error) {
+		var q instantiateTemplate
+		variables := map[string]interface{}{
+			"templateID": id.String(),
+			"variables":  values,
+			"start":      start,
+		}
+
+		err := tools.DoMutate(&q, variables, access, router)
+		return q, err
+	}
+
+	Context("Instantiate template", func() {
+		It("creates the whole structure with placeholders filled in", func() {
+			t, err := create(onboarding)
+			Expect(err).To(BeNil())
+			Expect(t.Template.Items).To(HaveLen(2))
+			Expect(t.Template.Items[0].Children).To(HaveLen(2))
+
+			start := time.Date(2024, time.May, 6, 9, 0, 0, 0, time.UTC)
+			q, err := instantiate(t.Template.ID, []TemplateVariable{{Name: "name", Value: "Ada"}}, start,
+				signInUser1Resp.Auth.Data.AccessToken)
+			Expect(err).To(BeNil())
+			Expect(q.Todos).To(HaveLen(2))
+
+			onboard := q.Todos[0]
+			Expect(onboard.Text).To(Equal("Onboard Ada on 2024-05-06"))
+			Expect(onboard.DueAt.Equal(start.AddDate(0, 0, 7))).To(BeTrue())
+			Expect(onboard.Children).To(HaveLen(2))
+			Expect(onboard.Children[0].Text).To(Equal("Set up a laptop for Ada"))
+			Expect(onboard.Children[0].DueAt.Equal(start)).To(BeTrue())
+			Expect(onboard.Children[1].DueAt).To(BeNil())
+
+			Expect(q.Todos[1].DueAt.Equal(start.AddDate(0, 0, 30))).To(BeTrue())
+		})
+
+		It("counts the length of the filled in text in characters", func() {
+			t, err := create(NewTodoTemplate{
+				Name:  "Umlauts",
+				Items: []TodoTemplateItemInput{{Text: strings.Repeat("ü", 200) + "{{name}}"}},
+			})
+			Expect(err).To(BeNil())
+
+			q, err := instantiate(t.Template.ID, []TemplateVariable{{Name: "name", Value: "Ada"}}, time.Now(),
+				signInUser1Resp.Auth.Data.AccessToken)
+			Expect(err).To(BeNil())
+			Expect(q.Todos).To(HaveLen(1))
+			Expect(q.Todos[0].Text).To(Equal(strings.Repeat("ü", 200) + "Ada"))
+		})
+
+		It("error: missing variable creates nothing", func() {
+			t, err := create(onboarding)
+			Expect(err).To(BeNil())
+
+			var before int64
+			Expect(db.Table("todos").Count(&before).Error).To(BeNil())
+
+			_, err = instantiate(t.Template.ID, []TemplateVariable{}, time.Now(), signInUser1Resp.Auth.Data.AccessToken)
+			Expect(err.Error()).To(Equal("Message: missing value for a template variable, Locations: [], Extensions: map[]"))
+
+			var after int64
+			Expect(db.Table("todos").Count(&after).Error).To(BeNil())
+			Expect(after).To(Equal(before))
+		})
+
+		It("error: template of another user", func() {
+			t, err := create(onboarding)
+			Expect(err).To(BeNil())
+
+			_, err = instantiate(t.Template.ID, []TemplateVariable{{Name: "name", Value: "Ada"}}, time.Now(),
+				signInUser2Resp.Auth.Data.AccessToken)
+			Expect(err.Error()).To(Equal("Message: template not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Save todo as template", func() {
+		It("keeps subtasks and due offsets", func() {
+			parent := signInUser1Resp.Todos[0]
+			due := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+			Expect(db.Table("todos").Where("id = ?", parent.ID).Update("due_at", due).Error).To(BeNil())
+			subtasks := addSubtasksToDb(parent)
+			Expect(db.Table("todos").Where("id = ?", subtasks[0].ID).Update("due_at", due.Add(-24*time.Hour)).Error).To(BeNil())
+
+			var q saveTodoAsTemplate
+			variables := map[string]interface{}{
+				"todoID": parent.ID.String(),
+				"name":   "Copy",
+			}
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Template.Items).To(HaveLen(1))
+			Expect(q.Template.Items[0].Text).To(Equal(parent.Text))
+			Expect(*q.Template.Items[0].DueOffset).To(Equal(0))
+			Expect(q.Template.Items[0].Children).To(HaveLen(2))
+			Expect(*q.Template.Items[0].Children[0].DueOffset).To(Equal(-24 * 3600))
+			Expect(q.Template.Items[0].Children[1].DueOffset).To(BeNil())
+		})
+
+		It("keeps the priority and tags", func() {
+			parent := signInUser1Resp.Todos[0]
+			Expect(db.Table("todos").Where("id = ?", parent.ID).Updates(map[string]interface{}{
+				"priority": models.TodoPriorityHigh,
+				"tags":     models.TodoTags{"work", "urgent"},
+			}).Error).To(BeNil())
+
+			var q saveTodoAsTemplate
+			variables := map[string]interface{}{
+				"todoID": parent.ID.String(),
+				"name":   "Copy",
+			}
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Template.Items[0].Priority).To(Equal(models.TodoPriorityHigh))
+			Expect(q.Template.Items[0].Tags).To(Equal([]string{"work", "urgent"}))
+
+			created, err := instantiate(q.Template.ID, []TemplateVariable{}, time.Now(), signInUser1Resp.Auth.Data.AccessToken)
+			Expect(err).To(BeNil())
+			Expect(created.Todos).To(HaveLen(1))
+			Expect(created.Todos[0].Priority).To(Equal(models.TodoPriorityHigh))
+			Expect(created.Todos[0].Tags).To(Equal([]string{"work", "urgent"}))
+		})
+	})
+})
@@ -0,0 +1,265 @@
+package todo
+
+import (
+	"time"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type timeEntry struct {
+	ID   uuid.UUID `json:"id"`
+	Todo struct {
+		ID uuid.UUID `json:"id"`
+	} `json:"todo"`
+	Started time.Time  `json:"started"`
+	Stopped *time.Time `json:"stopped"`
+	Note    string     `json:"note"`
+	Seconds int        `json:"seconds"`
+}
+
+type startTimer struct {
+	Entry timeEntry `graphql:"startTimer(todoID: $todoID)"`
+}
+
+type stopTimer struct {
+	Entry timeEntry `graphql:"stopTimer"`
+}
+
+type runningTimer struct {
+	Entry *timeEntry `graphql:"runningTimer"`
+}
+
+type addTimeEntry struct {
+	Entry timeEntry `graphql:"addTimeEntry(todoID: $todoID, input: {started: $started, stopped: $stopped, note: $note})"`
+}
+
+type updateTimeEntry struct {
+	Entry timeEntry `graphql:"updateTimeEntry(timeEntryID: $timeEntryID, input: {stopped: $stopped})"`
+}
+
+type deleteTimeEntry struct {
+	Deleted bool `graphql:"deleteTimeEntry(timeEntryID: $timeEntryID)"`
+}
+
+type TimeReportGroup string
+
+type timeReport struct {
+	Rows []struct {
+		Day  *time.Time `json:"day"`
+		Todo *struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"todo"`
+		Seconds int `json:"seconds"`
+	} `graphql:"timeReport(from: $from, to: $to, groupBy: $groupBy)"`
+}
+
+type timeSpent struct {
+	Tree []struct {
+		ID        uuid.UUID `json:"id"`
+		TimeSpent int       `json:"timeSpent" graphql:"timeSpent"`
+	} `graphql:"todoTree(rootID: $rootID)"`
+}
+
+var _ = Describe("Time tracking", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	start := func(todo models.Todo) (startTimer, error) {
+		var q startTimer
+		variables := map[string]interface{}{
+			"todoID": todo.ID.String(),
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	add := func(todo models.Todo, started time.Time, stopped time.Time) (addTimeEntry, error) {
+		var q addTimeEntry
+		variables := map[string]interface{}{
+			"todoID":  todo.ID.String(),
+			"started": started,
+			"stopped": stopped,
+			"note":    "manual",
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	Context("Timer", func() {
+		It("starts and stops a timer", func() {
+			todo := signInUser1Resp.Todos[0]
+
+			started, err := start(todo)
+			Expect(err).To(BeNil())
+			Expect(started.Entry.Todo.ID).To(Equal(todo.ID))
+			Expect(started.Entry.Stopped).To(BeNil())
+
+			var running runningTimer
+			err = tools.DoQuery(&running, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(running.Entry).NotTo(BeNil())
+			Expect(running.Entry.ID).To(Equal(started.Entry.ID))
+
+			var stopped stopTimer
+			err = tools.DoMutate(&stopped, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(stopped.Entry.ID).To(Equal(started.Entry.ID))
+			Expect(stopped.Entry.Stopped).NotTo(BeNil())
+
+			running = runningTimer{}
+			err = tools.DoQuery(&running, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(running.Entry).To(BeNil())
+		})
+
+		It("stops the running timer when another one starts", func() {
+			first, err := start(signInUser1Resp.Todos[0])
+			Expect(err).To(BeNil())
+			second, err := start(signInUser1Resp.Todos[1])
+			Expect(err).To(BeNil())
+
+			var entries []models.TimeEntry
+			Expect(db.Where("stopped IS NULL").Find(&entries).Error).To(BeNil())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].ID).To(Equal(second.Entry.ID))
+
+			var entry models.TimeEntry
+			Expect(db.Take(&entry, "id = ?", first.Entry.ID).Error).To(BeNil())
+			Expect(entry.Stopped).NotTo(BeNil())
+		})
+
+		It("error: no running timer", func() {
+
+			var q stopTimer
+			err := tools.DoMutate(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: no timer is running, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: todo of another user", func() {
+
+			_, err := start(signInUser2Resp.Todos[0])
+			Expect(err.Error()).To(Equal("Message: todo not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Manual entries", func() {
+		It("adds, edits and deletes an entry", func() {
+			todo := signInUser1Resp.Todos[0]
+			started := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+
+			q, err := add(todo, started, started.Add(30*time.Minute))
+			Expect(err).To(BeNil())
+			Expect(q.Entry.Seconds).To(Equal(1800))
+			Expect(q.Entry.Note).To(Equal("manual"))
+
+			var u updateTimeEntry
+			variables := map[string]interface{}{
+				"timeEntryID": q.Entry.ID.String(),
+				"stopped":     started.Add(time.Hour),
+			}
+			err = tools.DoMutate(&u, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(u.Entry.Seconds).To(Equal(3600))
+
+			var spent timeSpent
+			variables = map[string]interface{}{
+				"rootID": todo.ID.String(),
+			}
+			err = tools.DoQuery(&spent, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(spent.Tree[0].TimeSpent).To(Equal(3600))
+
+			var d deleteTimeEntry
+			variables = map[string]interface{}{
+				"timeEntryID": q.Entry.ID.String(),
+			}
+			err = tools.DoMutate(&d, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(d.Deleted).To(BeTrue())
+		})
+
+		It("error: entry ends before it starts", func() {
+			started := time.Now().Add(-time.Hour)
+
+			_, err := add(signInUser1Resp.Todos[0], started, started.Add(-time.Minute))
+			Expect(err.Error()).To(Equal("Message: time entry has to end after it starts, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: entry of another user", func() {
+			started := time.Now().Add(-time.Hour)
+			q, err := add(signInUser1Resp.Todos[0], started, started.Add(time.Minute))
+			Expect(err).To(BeNil())
+
+			var u updateTimeEntry
+			variables := map[string]interface{}{
+				"timeEntryID": q.Entry.ID.String(),
+				"stopped":     started.Add(time.Hour),
+			}
+			err = tools.DoMutate(&u, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: time entry not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Report", func() {
+		It("adds up the time by day and by todo", func() {
+			a, b := signInUser1Resp.Todos[0], signInUser1Resp.Todos[1]
+			day := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+			// One hour on a before midnight and one after, two hours on b the next day.
+			_, err := add(a, day.Add(23*time.Hour), day.Add(25*time.Hour))
+			Expect(err).To(BeNil())
+			_, err = add(b, day.Add(34*time.Hour), day.Add(36*time.Hour))
+			Expect(err).To(BeNil())
+			// Outside of the report.
+			_, err = add(b, day.Add(-5*time.Hour), day.Add(-4*time.Hour))
+			Expect(err).To(BeNil())
+
+			var q timeReport
+			variables := map[string]interface{}{
+				"from":    day,
+				"to":      day.AddDate(0, 0, 7),
+				"groupBy": TimeReportGroup("DAY"),
+			}
+			err = tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Rows).To(HaveLen(2))
+			Expect(q.Rows[0].Day.Equal(day)).To(BeTrue())
+			Expect(q.Rows[0].Seconds).To(Equal(3600))
+			Expect(q.Rows[1].Day.Equal(day.AddDate(0, 0, 1))).To(BeTrue())
+			Expect(q.Rows[1].Seconds).To(Equal(3 * 3600))
+
+			q = timeReport{}
+			variables["groupBy"] = TimeReportGroup("TODO")
+			err = tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Rows).To(HaveLen(2))
+			Expect(q.Rows[0].Todo.ID).To(Equal(a.ID))
+			Expect(q.Rows[0].Seconds).To(Equal(2 * 3600))
+			Expect(q.Rows[1].Todo.ID).To(Equal(b.ID))
+			Expect(q.Rows[1].Seconds).To(Equal(2 * 3600))
+		})
+	})
+})
@@ -1,9 +1,11 @@
 package todo
 
 import (
+	"os"
 	"testing"
 	"time"
 	"todo-service/src/infrastructure/authentication"
+	"todo-service/src/infrastructure/delivery/caldav"
 	"todo-service/src/infrastructure/delivery/graphql"
 	"todo-service/src/infrastructure/storage"
 	"todo-service/src/models"
@@ -66,16 +68,23 @@ type markCompleteTodo struct {
 	} `graphql:"markCompleteTodo(todoID: $todoID)"`
 }
 
+type todoNode struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+	Done bool      `json:"done"`
+	User struct {
+		ID    uuid.UUID `json:"id"`
+		Name  string    `json:"name"`
+		Email string    `json:"email"`
+	}
+}
+
 type listTodos struct {
-	Todos []struct {
-		ID   uuid.UUID `json:"id"`
-		Text string    `json:"text"`
-		Done bool      `json:"done"`
-		User struct {
-			ID    uuid.UUID `json:"id"`
-			Name  string    `json:"name"`
-			Email string    `json:"email"`
-		}
+	Todos struct {
+		Edges []struct {
+			Node todoNode `json:"node"`
+		} `json:"edges"`
+		TotalCount int `json:"totalCount"`
 	} `graphql:"todos"`
 }
 
@@ -114,16 +123,7 @@ func initListTodosStruct(ui userInfo) listTodos {
 	var list listTodos
 
 	for _, todo := range ui.Todos {
-		Data := struct {
-			ID   uuid.UUID `json:"id"`
-			Text string    `json:"text"`
-			Done bool      `json:"done"`
-			User struct {
-				ID    uuid.UUID `json:"id"`
-				Name  string    `json:"name"`
-				Email string    `json:"email"`
-			}
-		}{
+		Data := todoNode{
 			ID:   todo.ID,
 			Text: todo.Text,
 			Done: todo.Done,
@@ -137,8 +137,11 @@ func initListTodosStruct(ui userInfo) listTodos {
 				Email: ui.User.Email,
 			},
 		}
-		list.Todos = append(list.Todos, Data)
+		list.Todos.Edges = append(list.Todos.Edges, struct {
+			Node todoNode `json:"node"`
+		}{Node: Data})
 	}
+	list.Todos.TotalCount = len(ui.Todos)
 	return list
 }
 
@@ -177,6 +180,7 @@ func TestTodo(t *testing.T) {
 
 var router *echo.Echo
 var db *gorm.DB
+var useCase registry.UseCase
 
 var _ = BeforeSuite(func() {
 	viper.AddConfigPath("../../../conf")
@@ -195,16 +199,26 @@ var _ = BeforeSuite(func() {
 
 	db = storage.InitPostgres(logger)
 
+	attachmentsDir, err := os.MkdirTemp("", "attachments")
+	if err != nil {
+		panic(err)
+	}
+	viper.Set("attachments.store", "local")
+	viper.Set("attachments.local.dir", attachmentsDir)
+	viper.Set("attachments.max_size", 1024)
+	viper.Set("attachments.allowed_types", []string{"text/plain"})
+
 	jc := authentication.NewJwtConfigurator(logger, "../../../rsa_keys/private_key.pem",
 		"../../../rsa_keys/public_key.pem")
 
 	// Register and create controller
-	useCase := registry.NewRegistry(db, jc).NewUseCase()
+	useCase = registry.NewRegistry(db, jc).NewUseCase()
 
 	router = echo.New()
 
 	// Initialize Echo instance
-	graphql.NewGraphqlRouter(router, useCase)
+	graphql.NewGraphqlRouter(router, useCase, logger)
+	caldav.NewCalDAVRouter(router, useCase)
 })
 
 func checkCreateTodoResult(err error, wantErr error, q createTodo, wantQ createTodo) {
@@ -263,6 +277,7 @@ func AddUsersToDb() {
 func AddTodosToDb() {
 	signInUser1Resp.Todos = make([]models.Todo, 0)
 	signInUser2Resp.Todos = make([]models.Todo, 0)
+	now := time.Now().Truncate(time.Second)
 	todos := []models.Todo{
 		//User ID = 48f875c5-4d1f-4eb6-abbc-5e85dae826af
 		{
@@ -270,21 +285,21 @@ func AddTodosToDb() {
 			Text:    "text_todo_1",
 			Done:    false,
 			UserID:  uuid.MustParse("48f875c5-4d1f-4eb6-abbc-5e85dae826af"),
-			Created: time.Now(),
+			Created: now.Add(1 * time.Second),
 		},
 		{
 			ID:      uuid.MustParse("e51baf4c-cb01-4371-a422-7d94b70951d7"),
 			Text:    "text_todo_2",
 			Done:    false,
 			UserID:  uuid.MustParse("48f875c5-4d1f-4eb6-abbc-5e85dae826af"),
-			Created: time.Now(),
+			Created: now.Add(2 * time.Second),
 		},
 		{
 			ID:      uuid.MustParse("9e701781-8e26-492b-9cf2-f67b4faf24f2"),
 			Text:    "text_todo_3",
 			Done:    false,
 			UserID:  uuid.MustParse("48f875c5-4d1f-4eb6-abbc-5e85dae826af"),
-			Created: time.Now(),
+			Created: now.Add(3 * time.Second),
 		},
 		//User ID = 2fd3d635-3a46-4085-a854-81f76a25cfd0
 		{
@@ -292,21 +307,21 @@ func AddTodosToDb() {
 			Text:    "text_todo_4",
 			Done:    false,
 			UserID:  uuid.MustParse("2fd3d635-3a46-4085-a854-81f76a25cfd0"),
-			Created: time.Now(),
+			Created: now.Add(4 * time.Second),
 		},
 		{
 			ID:      uuid.MustParse("3203170d-6ed5-4a0f-afcf-9337f079590b"),
 			Text:    "text_todo_5",
 			Done:    false,
 			UserID:  uuid.MustParse("2fd3d635-3a46-4085-a854-81f76a25cfd0"),
-			Created: time.Now(),
+			Created: now.Add(5 * time.Second),
 		},
 		{
 			ID:      uuid.MustParse("8258b38d-8c90-4226-8e16-129088704914"),
 			Text:    "text_todo_6",
 			Done:    false,
 			UserID:  uuid.MustParse("2fd3d635-3a46-4085-a854-81f76a25cfd0"),
-			Created: time.Now(),
+			Created: now.Add(6 * time.Second),
 		},
 	}
 
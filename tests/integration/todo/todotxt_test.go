@@ -0,0 +1,175 @@
+package todo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const todoTxt = "(A) 2030-04-20 Call Mom +Family @phone due:2030-05-02 rec:1w id:call\n" +
+	"\n" +
+	"x 2030-05-01 2030-04-21 Buy a card +Family pri:B p:call\n" +
+	"2030-04-22 Read https://example.com/post lang:en\n"
+
+var todoTxtLinks = regexp.MustCompile(` (id|p):[0-9a-f-]{36}`)
+
+// withoutLinks returns the lines of a todo.txt file without the ids of the
+// todos, which an import doesn't keep.
+func withoutLinks(content string) []string {
+	return strings.Split(todoTxtLinks.ReplaceAllString(content, " $1:"), "\n")
+}
+
+var _ = Describe("Todo todo.txt", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	importTodoTxt := func(content string) (importTodos, error) {
+		var q importTodos
+		variables := map[string]interface{}{
+			"format": "TODO_TXT",
+		}
+
+		err := tools.DoUpload(&q, importTodosQuery, variables, "todo.txt", []byte(content),
+			signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	exportTodoTxt := func() string {
+		var q exportTodos
+		variables := map[string]interface{}{
+			"format": TodoFileFormat("TODO_TXT"),
+		}
+
+		Expect(tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)).To(Succeed())
+		return q.Export
+	}
+
+	It("imports lines with their dates, priority, tags and extensions", func() {
+		q, err := importTodoTxt(todoTxt)
+		Expect(err).To(BeNil())
+		Expect(q.Result.Imported).To(Equal(3))
+		Expect(q.Result.Failed).To(Equal(0))
+		Expect(q.Result.Todos).To(HaveLen(2))
+		Expect(q.Result.Todos[0].Text).To(Equal("Call Mom +Family @phone"))
+		Expect(q.Result.Todos[0].Priority).To(Equal("HIGH"))
+		Expect(q.Result.Todos[0].Tags).To(Equal([]string{"family", "@phone"}))
+		Expect(q.Result.Todos[0].Children).To(HaveLen(1))
+		Expect(q.Result.Todos[1].Text).To(Equal("Read https://example.com/post lang:en"))
+
+		var call models.Todo
+		Expect(db.Where("text = ?", "Call Mom +Family @phone").Take(&call).Error).To(BeNil())
+		Expect(call.RRule).To(Equal("FREQ=WEEKLY"))
+		Expect(call.DueAt.UTC().Format("2006-01-02T15:04")).To(Equal("2030-05-02T00:00"))
+		Expect(call.Created.UTC().Format("2006-01-02")).To(Equal("2030-04-20"))
+
+		var card models.Todo
+		Expect(db.Where("text = ?", "Buy a card +Family").Take(&card).Error).To(BeNil())
+		Expect(card.Done).To(BeTrue())
+		Expect(card.Priority).To(Equal(models.TodoPriorityMedium))
+		Expect(card.CompletedAt.UTC().Format("2006-01-02")).To(Equal("2030-05-01"))
+		Expect(card.ParentID).To(Equal(&call.ID))
+	})
+
+	It("round-trips an export", func() {
+		_, err := importTodoTxt(todoTxt)
+		Expect(err).To(BeNil())
+
+		exported := exportTodoTxt()
+		Expect(exported).To(ContainSubstring("(A) 2030-04-20 Call Mom +Family @phone due:2030-05-02 rec:1w id:"))
+		Expect(exported).To(ContainSubstring("x 2030-05-01 2030-04-21 Buy a card +Family pri:B p:"))
+		Expect(exported).To(ContainSubstring("2030-04-22 Read https://example.com/post lang:en\n"))
+		Expect(strings.Count(exported, "\n")).To(Equal(len(signInUser1Resp.Todos) + 3))
+
+		resetTodoTables()
+		AddUsersToDb()
+
+		q, err := importTodoTxt(exported)
+		Expect(err).To(BeNil())
+		Expect(q.Result.Failed).To(Equal(0))
+		Expect(withoutLinks(exportTodoTxt())).To(ConsistOf(withoutLinks(exported)))
+	})
+
+	It("round-trips priorities after C and due times", func() {
+		resetTodoTables()
+		AddUsersToDb()
+
+		content := "(D) 2030-04-20 Water plants due:2030-05-02T1430\n" +
+			"x 2030-05-01 2030-04-21 Old task pri:Q\n" +
+			"(C) 2030-04-22 Keep going due:2030-05-03\n"
+
+		q, err := importTodoTxt(content)
+		Expect(err).To(BeNil())
+		Expect(q.Result.Failed).To(Equal(0))
+
+		var plants models.Todo
+		Expect(db.Where("text = ?", "Water plants pri:D").Take(&plants).Error).To(BeNil())
+		Expect(plants.Priority).To(Equal(models.TodoPriorityLow))
+		Expect(plants.DueAt.UTC().Format("2006-01-02T15:04")).To(Equal("2030-05-02T14:30"))
+
+		exported := exportTodoTxt()
+		Expect(strings.Split(exported, "\n")).To(ConsistOf(strings.Split(content, "\n")))
+
+		resetTodoTables()
+		AddUsersToDb()
+
+		_, err = importTodoTxt(exported)
+		Expect(err).To(BeNil())
+		Expect(strings.Split(exportTodoTxt(), "\n")).To(ConsistOf(strings.Split(content, "\n")))
+	})
+
+	It("drops a kept letter once the priority changed", func() {
+		_, err := importTodoTxt("(D) 2030-04-20 Water plants\n")
+		Expect(err).To(BeNil())
+		Expect(db.Model(&models.Todo{}).Where("text = ?", "Water plants pri:D").
+			Update("priority", models.TodoPriorityHigh).Error).To(BeNil())
+
+		Expect(exportTodoTxt()).To(ContainSubstring("(A) 2030-04-20 Water plants\n"))
+	})
+
+	It("adds tags the text doesn't mention", func() {
+		Expect(db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[0].ID).
+			Update("tags", models.TodoTags{"home", "@errands"}).Error).To(BeNil())
+
+		Expect(exportTodoTxt()).To(ContainSubstring(signInUser1Resp.Todos[0].Text + " +home @errands\n"))
+	})
+
+	It("serves the file as a download", func() {
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/todos/export?format=todo_txt", nil)
+		req.Header.Set("Authorization", "Bearer "+signInUser1Resp.Auth.Data.AccessToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+		Expect(w.Header().Get("Content-Disposition")).To(Equal(`attachment; filename=todo.txt`))
+		Expect(w.Body.String()).To(Equal(exportTodoTxt()))
+	})
+
+	It("reports lines it can't import", func() {
+		q, err := importTodoTxt("Water plants rec:1d\nPay rent due:someday\n(Z) due:2030-05-01\n")
+		Expect(err).To(BeNil())
+		Expect(q.Result.Imported).To(Equal(0))
+		Expect(q.Result.Failed).To(Equal(3))
+		Expect(q.Result.Errors[0].Errors).To(Equal([]string{"recurring todo requires a due date"}))
+		Expect(q.Result.Errors[1].Errors).To(Equal([]string{"Parameters incorrectly formatted or out of range (due)"}))
+		Expect(q.Result.Errors[2].Errors).To(Equal([]string{"Required parameters not passed (Text)"}))
+	})
+})
@@ -0,0 +1,241 @@
+package todo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const importTodosQuery = `mutation ($file: Upload!, $format: TodoFileFormat!, $mapping: [TodoColumnMapping!], $dryRun: Boolean) {
+	importTodos(file: $file, format: $format, mapping: $mapping, dryRun: $dryRun) {
+		dryRun imported updated failed errors { row errors } todos { id text done priority tags children { text } }
+	}
+}`
+
+type importTodos struct {
+	Result struct {
+		DryRun   bool `json:"dryRun"`
+		Imported int  `json:"imported"`
+		Updated  int  `json:"updated"`
+		Failed   int  `json:"failed"`
+		Errors   []struct {
+			Row    int      `json:"row"`
+			Errors []string `json:"errors"`
+		} `json:"errors"`
+		Todos []struct {
+			ID       string   `json:"id"`
+			Text     string   `json:"text"`
+			Done     bool     `json:"done"`
+			Priority string   `json:"priority"`
+			Tags     []string `json:"tags"`
+			Children []struct {
+				Text string `json:"text"`
+			} `json:"children"`
+		} `json:"todos"`
+	} `json:"importTodos"`
+}
+
+type TodoFileFormat string
+
+type exportTodos struct {
+	Export string `graphql:"exportTodos(format: $format)"`
+}
+
+var _ = Describe("Todo import and export", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	importFile := func(format string, content string, mapping []map[string]interface{}, dryRun bool) (importTodos, error) {
+		var q importTodos
+		variables := map[string]interface{}{
+			"format": format,
+			"dryRun": dryRun,
+		}
+		if mapping != nil {
+			variables["mapping"] = mapping
+		}
+
+		err := tools.DoUpload(&q, importTodosQuery, variables, "todos."+strings.ToLower(format), []byte(content),
+			signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	export := func(format string) string {
+		var q exportTodos
+		variables := map[string]interface{}{
+			"format": TodoFileFormat(format),
+		}
+
+		err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		return q.Export
+	}
+
+	countTodos := func() int64 {
+		var count int64
+		Expect(db.Model(&models.Todo{}).Where("user_id = ?", signInUser1Resp.User.ID).Count(&count).Error).To(BeNil())
+		return count
+	}
+
+	Context("Export", func() {
+		It("exports the todos of the user as CSV", func() {
+
+			records, err := csv.NewReader(strings.NewReader(export("CSV"))).ReadAll()
+			Expect(err).To(BeNil())
+			Expect(records[0]).To(Equal([]string{"id", "parentId", "text", "done", "dueAt", "priority", "tags", "rrule", "created"}))
+			Expect(records[1:]).To(HaveLen(len(signInUser1Resp.Todos)))
+
+			texts := make([]string, 0)
+			for _, record := range records[1:] {
+				texts = append(texts, record[2])
+			}
+			for _, todo := range signInUser1Resp.Todos {
+				Expect(texts).To(ContainElement(todo.Text))
+			}
+		})
+
+		It("exports the todos of the user as JSON", func() {
+
+			var records []map[string]interface{}
+			Expect(json.Unmarshal([]byte(export("JSON")), &records)).To(Succeed())
+			Expect(records).To(HaveLen(len(signInUser1Resp.Todos)))
+			Expect(records[0]).To(HaveKey("text"))
+			Expect(records[0]["tags"]).To(Equal([]interface{}{}))
+		})
+
+		It("streams the export as a download", func() {
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/todos/export?format=csv", nil)
+			req.Header.Set("Authorization", "Bearer "+signInUser1Resp.Auth.Data.AccessToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Disposition")).To(Equal(`attachment; filename=todos.csv`))
+			Expect(w.Body.String()).To(Equal(export("CSV")))
+		})
+
+		It("error: download without a token", func() {
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/todos/export?format=csv", nil))
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("Import", func() {
+		It("imports CSV with subtasks linked by id", func() {
+			before := countTodos()
+
+			file := "id,parentId,text,done,dueAt,priority,tags,rrule\n" +
+				"1,,Plan trip,false,2030-05-01,high,\"travel,summer\",\n" +
+				"2,1,Book flights,true,,,,\n"
+
+			q, err := importFile("CSV", file, nil, false)
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(2))
+			Expect(q.Result.Failed).To(Equal(0))
+			Expect(q.Result.Todos).To(HaveLen(1))
+			Expect(q.Result.Todos[0].Text).To(Equal("Plan trip"))
+			Expect(q.Result.Todos[0].Priority).To(Equal("HIGH"))
+			Expect(q.Result.Todos[0].Tags).To(Equal([]string{"travel", "summer"}))
+			Expect(q.Result.Todos[0].Children).To(HaveLen(1))
+			Expect(countTodos()).To(Equal(before + 2))
+		})
+
+		It("imports an export again", func() {
+			before := countTodos()
+
+			q, err := importFile("JSON", export("JSON"), nil, false)
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(int(before)))
+			Expect(q.Result.Errors).To(BeEmpty())
+			Expect(countTodos()).To(Equal(2 * before))
+		})
+
+		It("maps columns", func() {
+
+			file := "Task,Finished\nWater plants,true\n"
+			mapping := []map[string]interface{}{
+				{"column": "Task", "field": "TEXT"},
+				{"column": "Finished", "field": "DONE"},
+			}
+
+			q, err := importFile("CSV", file, mapping, false)
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(1))
+			Expect(q.Result.Todos[0].Text).To(Equal("Water plants"))
+			Expect(q.Result.Todos[0].Done).To(BeTrue())
+		})
+
+		It("reports invalid rows and imports the others", func() {
+			before := countTodos()
+
+			file := "text,priority,dueAt,rrule,parentId\n" +
+				"Valid,,,,\n" +
+				",,,,\n" +
+				"Bad priority,urgent,,,\n" +
+				"Bad date,,someday,FREQ=DAILY,\n" +
+				"Orphan,,,," + signInUser1Resp.User.ID.String() + "\n"
+
+			q, err := importFile("CSV", file, nil, false)
+			Expect(err).To(BeNil())
+			Expect(q.Result.Imported).To(Equal(1))
+			Expect(q.Result.Failed).To(Equal(4))
+			Expect(q.Result.Errors[0].Row).To(Equal(2))
+			Expect(q.Result.Errors[0].Errors).To(Equal([]string{"Required parameters not passed (Text)"}))
+			Expect(q.Result.Errors[1].Errors).To(Equal([]string{"Parameters incorrectly formatted or out of range (Priority)"}))
+			Expect(q.Result.Errors[2].Errors).To(Equal([]string{
+				"Parameters incorrectly formatted or out of range (DueAt)",
+				"recurring todo requires a due date",
+			}))
+			Expect(q.Result.Errors[3].Errors).To(Equal([]string{"parent todo not found in the file or the list"}))
+			Expect(countTodos()).To(Equal(before + 1))
+		})
+
+		It("keeps nothing on a dry run", func() {
+			before := countTodos()
+
+			q, err := importFile("JSON", `[{"text": "One"}, {"text": "Two", "tags": ["a", "b"]}]`, nil, true)
+			Expect(err).To(BeNil())
+			Expect(q.Result.DryRun).To(BeTrue())
+			Expect(q.Result.Imported).To(Equal(2))
+			Expect(q.Result.Todos).To(BeEmpty())
+			Expect(countTodos()).To(Equal(before))
+		})
+
+		It("error: no column for the text", func() {
+
+			mapping := []map[string]interface{}{
+				{"column": "Finished", "field": "DONE"},
+			}
+
+			_, err := importFile("CSV", "Finished\ntrue\n", mapping, false)
+			Expect(err.Error()).To(Equal("no column is mapped to the text of the todos"))
+		})
+
+		It("error: file in another format", func() {
+
+			_, err := importFile("JSON", "text\nnot json\n", nil, false)
+			Expect(err.Error()).To(Equal("file can't be read in the given format"))
+		})
+	})
+})
@@ -0,0 +1,227 @@
+package todo
+
+import (
+	"time"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type trashedTodos struct {
+	Todos []struct {
+		ID        uuid.UUID  `json:"id"`
+		DeletedAt *time.Time `json:"deletedAt" graphql:"deletedAt"`
+	} `graphql:"trashedTodos"`
+}
+
+type restoreTodo struct {
+	Todo struct {
+		ID       uuid.UUID `json:"id"`
+		ParentID *string   `json:"parentId" graphql:"parentId"`
+	} `graphql:"restoreTodo(todoID: $todoID)"`
+}
+
+type emptyTrash struct {
+	Count int `graphql:"emptyTrash"`
+}
+
+var _ = Describe("Trash", func() {
+	var subtasks []models.Todo
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+		subtasks = addSubtasksToDb(signInUser1Resp.Todos[0])
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	trash := func(id uuid.UUID) {
+		var q deleteTodo
+		variables := map[string]interface{}{
+			"todoID": id.String(),
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		Expect(q.DeleteTodoRes).To(BeTrue())
+	}
+
+	Context("Delete todo", func() {
+		It("moves the todo and its subtasks to the trash", func() {
+
+			trash(signInUser1Resp.Todos[0].ID)
+
+			var kept int64
+			db.Unscoped().Model(&models.Todo{}).Where("id = ? OR parent_id = ?", signInUser1Resp.Todos[0].ID, signInUser1Resp.Todos[0].ID).Count(&kept)
+			Expect(kept).To(Equal(int64(1 + len(subtasks))))
+
+			var q trashedTodos
+			err := tools.DoQuery(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos).To(HaveLen(1 + len(subtasks)))
+			Expect(q.Todos[0].DeletedAt).ToNot(BeNil())
+		})
+
+		It("hides trashed todos from the todos query", func() {
+
+			trash(signInUser1Resp.Todos[1].ID)
+
+			var q firstTodos
+			variables := map[string]interface{}{
+				"first": 10,
+			}
+
+			err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			for _, edge := range q.Todos.Edges {
+				Expect(edge.Node.ID).ToNot(Equal(signInUser1Resp.Todos[1].ID))
+			}
+		})
+	})
+
+	Context("Restore todo", func() {
+		It("brings back the todo with the subtasks trashed along with it", func() {
+
+			trash(subtasks[0].ID)
+			trash(signInUser1Resp.Todos[0].ID)
+
+			var q restoreTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser1Resp.Todos[0].ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todo.ID).To(Equal(signInUser1Resp.Todos[0].ID))
+
+			var live int64
+			db.Model(&models.Todo{}).Where("parent_id = ?", signInUser1Resp.Todos[0].ID).Count(&live)
+			Expect(live).To(Equal(int64(len(subtasks) - 1)))
+		})
+
+		It("moves a subtask of a trashed parent to the top level", func() {
+
+			trash(signInUser1Resp.Todos[0].ID)
+
+			var q restoreTodo
+			variables := map[string]interface{}{
+				"todoID": subtasks[0].ID.String(),
+			}
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todo.ParentID).To(BeNil())
+		})
+
+		It("error: todo of another user", func() {
+
+			var q restoreTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser2Resp.Todos[0].ID.String(),
+			}
+
+			db.Delete(&models.Todo{}, "id = ?", signInUser2Resp.Todos[0].ID)
+
+			err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: todo not found in trash, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Empty trash", func() {
+		It("permanently deletes the trashed todos of the user only", func() {
+
+			trash(signInUser1Resp.Todos[0].ID)
+			db.Delete(&models.Todo{}, "id = ?", signInUser2Resp.Todos[0].ID)
+
+			var q emptyTrash
+			err := tools.DoMutate(&q, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Count).To(Equal(1 + len(subtasks)))
+
+			var left int64
+			db.Unscoped().Model(&models.Todo{}).Where("deleted IS NOT NULL").Count(&left)
+			Expect(left).To(Equal(int64(1)))
+		})
+	})
+
+	Context("Workspace trash", func() {
+		var workspace models.Workspace
+
+		BeforeEach(func() {
+			workspace = models.Workspace{ID: uuid.New(), Name: "Home"}
+			Expect(db.Create(&workspace).Error).To(BeNil())
+			members := []models.WorkspaceMember{
+				{WorkspaceID: workspace.ID, UserID: signInUser1Resp.User.ID, Role: models.WorkspaceRoleOwner},
+				{WorkspaceID: workspace.ID, UserID: signInUser2Resp.User.ID, Role: models.WorkspaceRoleMember},
+			}
+			Expect(db.Omit("Workspace", "User").Create(&members).Error).To(BeNil())
+
+			ids := []uuid.UUID{signInUser1Resp.Todos[1].ID, signInUser2Resp.Todos[0].ID}
+			Expect(db.Model(&models.Todo{}).Where("id IN ?", ids).Update("workspace_id", workspace.ID).Error).To(BeNil())
+			Expect(db.Delete(&models.Todo{}, "id IN ?", ids).Error).To(BeNil())
+
+			var err error
+			signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+			Expect(err).To(BeNil())
+		})
+
+		It("error: member restores a todo of another member", func() {
+
+			var q restoreTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser1Resp.Todos[1].ID.String(),
+			}
+
+			err := tools.DoMutateInWorkspace(&q, variables, signInUser2Resp.Auth.Data.AccessToken, workspace.ID.String(), router)
+			Expect(err.Error()).To(Equal("Message: " + models.ErrTodoForbidden.Message + ", Locations: [], Extensions: map[]"))
+
+			err = tools.DoMutateInWorkspace(&q, variables, signInUser1Resp.Auth.Data.AccessToken, workspace.ID.String(), router)
+			Expect(err).To(BeNil())
+			Expect(q.Todo.ID).To(Equal(signInUser1Resp.Todos[1].ID))
+		})
+
+		It("empties only the todos a member created", func() {
+
+			var q emptyTrash
+			err := tools.DoMutateInWorkspace(&q, nil, signInUser2Resp.Auth.Data.AccessToken, workspace.ID.String(), router)
+			Expect(err).To(BeNil())
+			Expect(q.Count).To(Equal(1))
+
+			var left int64
+			db.Unscoped().Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[1].ID).Count(&left)
+			Expect(left).To(Equal(int64(1)))
+
+			err = tools.DoMutateInWorkspace(&q, nil, signInUser1Resp.Auth.Data.AccessToken, workspace.ID.String(), router)
+			Expect(err).To(BeNil())
+			Expect(q.Count).To(Equal(1))
+		})
+	})
+
+	Context("Purge trash", func() {
+		It("permanently deletes todos trashed before the retention period", func() {
+
+			expired := time.Now().Add(-90 * 24 * time.Hour)
+			db.Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[1].ID).Update("deleted", expired)
+			db.Delete(&models.Todo{}, "id = ?", signInUser1Resp.Todos[2].ID)
+
+			purged, err := useCase.Todo.PurgeTrash()
+			Expect(err).To(BeNil())
+			Expect(purged).To(Equal(int64(1)))
+
+			var left int64
+			db.Unscoped().Model(&models.Todo{}).Where("id = ?", signInUser1Resp.Todos[2].ID).Count(&left)
+			Expect(left).To(Equal(int64(1)))
+		})
+	})
+})
@@ -0,0 +1,257 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type undoTokens struct {
+	UndoTokens map[string]string `json:"undoTokens"`
+}
+
+type undoTodo struct {
+	Todo *struct {
+		ID   uuid.UUID `json:"id"`
+		Text string    `json:"text"`
+		Done bool      `json:"done"`
+	} `graphql:"undo(token: $token)"`
+}
+
+var _ = Describe("Undo", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	// mutate runs a mutation of user1 and returns the undo token it got.
+	mutate := func(query string, variables map[string]interface{}) string {
+		var data map[string]interface{}
+		var extensions undoTokens
+
+		err := tools.DoRequest(&data, &extensions, query, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		Expect(extensions.UndoTokens).To(HaveLen(1))
+
+		for _, token := range extensions.UndoTokens {
+			return token
+		}
+		return ""
+	}
+
+	undo := func(token string, access string) (undoTodo, error) {
+		var q undoTodo
+		variables := map[string]interface{}{
+			"token": token,
+		}
+
+		err := tools.DoMutate(&q, variables, access, router)
+		return q, err
+	}
+
+	stored := func(id uuid.UUID) models.Todo {
+		var todo models.Todo
+		Expect(db.Unscoped().Take(&todo, "id = ?", id).Error).To(BeNil())
+		return todo
+	}
+
+	It("returns the token by the name of the mutation in the response", func() {
+		var data map[string]interface{}
+		var extensions undoTokens
+
+		query := `mutation($a: String!, $b: String!) {
+			first: markCompleteTodo(todoID: $a) { id }
+			second: deleteTodo(todoID: $b)
+		}`
+		variables := map[string]interface{}{
+			"a": signInUser1Resp.Todos[0].ID.String(),
+			"b": signInUser1Resp.Todos[1].ID.String(),
+		}
+
+		err := tools.DoRequest(&data, &extensions, query, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		Expect(extensions.UndoTokens).To(HaveKey("first"))
+		Expect(extensions.UndoTokens).To(HaveKey("second"))
+	})
+
+	It("returns the token on the returned todo as well", func() {
+		var data struct {
+			CreateTodo struct {
+				UndoToken *string `json:"undoToken"`
+			} `json:"createTodo"`
+		}
+		var extensions undoTokens
+
+		query := `mutation { createTodo(input: {text: "oops"}) { undoToken } }`
+		err := tools.DoRequest(&data, &extensions, query, nil, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		Expect(data.CreateTodo.UndoToken).ToNot(BeNil())
+		Expect(*data.CreateTodo.UndoToken).To(Equal(extensions.UndoTokens["createTodo"]))
+	})
+
+	It("returns no token for a bulk change", func() {
+		var data map[string]interface{}
+		var extensions undoTokens
+
+		query := `mutation($ids: [String!]!) { bulkUpdateTodos(ids: $ids, patch: {done: true}) { id } }`
+		variables := map[string]interface{}{
+			"ids": []string{signInUser1Resp.Todos[0].ID.String(), signInUser1Resp.Todos[1].ID.String()},
+		}
+
+		err := tools.DoRequest(&data, &extensions, query, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		Expect(extensions.UndoTokens).To(BeEmpty())
+	})
+
+	It("undoes creating a todo", func() {
+		token := mutate(`mutation { createTodo(input: {text: "oops"}) { id } }`, nil)
+
+		q, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(q.Todo).To(BeNil())
+
+		var count int64
+		Expect(db.Model(&models.Todo{}).Where("text = ?", "oops").Count(&count).Error).To(BeNil())
+		Expect(count).To(BeZero())
+	})
+
+	It("undoes editing a todo", func() {
+		todo := signInUser1Resp.Todos[0]
+		token := mutate(`mutation($id: String!) { updateTodo(todoID: $id, input: {text: "edited"}) { id } }`,
+			map[string]interface{}{"id": todo.ID.String()})
+
+		q, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(q.Todo.Text).To(Equal(todo.Text))
+		Expect(stored(todo.ID).Text).To(Equal(todo.Text))
+	})
+
+	It("undoes completing a todo together with its subtasks", func() {
+		todo := signInUser1Resp.Todos[0]
+		subtasks := addSubtasksToDb(todo)
+
+		token := mutate(`mutation($id: String!) { markCompleteTodo(todoID: $id) { id } }`,
+			map[string]interface{}{"id": todo.ID.String()})
+		Expect(stored(subtasks[0].ID).Done).To(BeTrue())
+
+		q, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(q.Todo.Done).To(BeFalse())
+		for _, subtask := range subtasks {
+			Expect(stored(subtask.ID).Done).To(Equal(subtask.Done))
+		}
+	})
+
+	It("undoes deleting a todo", func() {
+		todo := signInUser1Resp.Todos[0]
+		token := mutate(`mutation($id: String!) { deleteTodo(todoID: $id) }`,
+			map[string]interface{}{"id": todo.ID.String()})
+		Expect(stored(todo.ID).Deleted.Valid).To(BeTrue())
+
+		q, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(q.Todo.ID).To(Equal(todo.ID))
+		Expect(stored(todo.ID).Deleted.Valid).To(BeFalse())
+	})
+
+	It("undoes restoring a todo", func() {
+		todo := signInUser1Resp.Todos[0]
+		mutate(`mutation($id: String!) { deleteTodo(todoID: $id) }`,
+			map[string]interface{}{"id": todo.ID.String()})
+
+		token := mutate(`mutation($id: String!) { restoreTodo(todoID: $id) { id } }`,
+			map[string]interface{}{"id": todo.ID.String()})
+		Expect(stored(todo.ID).Deleted.Valid).To(BeFalse())
+
+		q, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(q.Todo).To(BeNil())
+		Expect(stored(todo.ID).Deleted.Valid).To(BeTrue())
+	})
+
+	It("undoes moving a todo", func() {
+		todo := signInUser1Resp.Todos[0]
+		position := stored(todo.ID).Position
+
+		token := mutate(`mutation($id: String!, $after: String) { moveTodo(todoID: $id, afterId: $after) { id } }`,
+			map[string]interface{}{"id": todo.ID.String(), "after": signInUser1Resp.Todos[2].ID.String()})
+		Expect(stored(todo.ID).Position).ToNot(Equal(position))
+
+		_, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(stored(todo.ID).Position).To(Equal(position))
+	})
+
+	It("undoes assigning a todo", func() {
+		todo := signInUser1Resp.Todos[0]
+		token := mutate(`mutation($id: String!, $user: String!) { assignTodo(todoID: $id, userID: $user) { id } }`,
+			map[string]interface{}{"id": todo.ID.String(), "user": signInUser1Resp.User.ID.String()})
+		Expect(stored(todo.ID).AssigneeID).ToNot(BeNil())
+
+		_, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(stored(todo.ID).AssigneeID).To(BeNil())
+	})
+
+	It("error: todo changed since", func() {
+		todo := signInUser1Resp.Todos[0]
+		token := mutate(`mutation($id: String!) { markCompleteTodo(todoID: $id) { id } }`,
+			map[string]interface{}{"id": todo.ID.String()})
+
+		mutate(`mutation($id: String!) { updateTodo(todoID: $id, input: {text: "later"}) { id } }`,
+			map[string]interface{}{"id": todo.ID.String()})
+
+		_, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err.Error()).To(Equal("Message: todo was changed since, the change can't be undone, Locations: [], Extensions: map[]"))
+		Expect(stored(todo.ID).Done).To(BeTrue())
+	})
+
+	It("error: token used twice", func() {
+		token := mutate(`mutation($id: String!) { deleteTodo(todoID: $id) }`,
+			map[string]interface{}{"id": signInUser1Resp.Todos[0].ID.String()})
+
+		_, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err).To(BeNil())
+
+		_, err = undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err.Error()).To(Equal("Message: nothing to undo or undo expired, Locations: [], Extensions: map[]"))
+	})
+
+	It("error: expired token", func() {
+		token := mutate(`mutation($id: String!) { deleteTodo(todoID: $id) }`,
+			map[string]interface{}{"id": signInUser1Resp.Todos[0].ID.String()})
+
+		Expect(db.Model(&models.TodoUndo{}).Where("id = ?", token).
+			Update("expires", gorm.Expr("now() - interval '1 second'")).Error).To(BeNil())
+
+		_, err := undo(token, signInUser1Resp.Auth.Data.AccessToken)
+		Expect(err.Error()).To(Equal("Message: nothing to undo or undo expired, Locations: [], Extensions: map[]"))
+	})
+
+	It("error: token of another user", func() {
+		token := mutate(`mutation($id: String!) { deleteTodo(todoID: $id) }`,
+			map[string]interface{}{"id": signInUser1Resp.Todos[0].ID.String()})
+
+		_, err := undo(token, signInUser2Resp.Auth.Data.AccessToken)
+		Expect(err.Error()).To(Equal("Message: nothing to undo or undo expired, Locations: [], Extensions: map[]"))
+	})
+})
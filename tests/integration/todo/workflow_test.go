@@ -0,0 +1,212 @@
+package todo
+
+import (
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type WorkflowStatusInput struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Transitions []string `json:"transitions"`
+}
+
+type workflowStatus struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Category    string    `json:"category"`
+	Transitions []struct {
+		Name string `json:"name"`
+	} `json:"transitions"`
+}
+
+type setWorkflow struct {
+	Statuses []workflowStatus `graphql:"setWorkflow(statuses: $statuses)"`
+}
+
+type transitionTodo struct {
+	Todo struct {
+		ID     uuid.UUID `json:"id"`
+		Done   bool      `json:"done"`
+		Status *struct {
+			Name string `json:"name"`
+		} `json:"status"`
+	} `graphql:"transitionTodo(todoID: $todoID, status: $status)"`
+}
+
+type todoStatus struct {
+	Tree []struct {
+		ID     uuid.UUID `json:"id"`
+		Done   bool      `json:"done"`
+		Status *struct {
+			Name string `json:"name"`
+		} `json:"status"`
+	} `graphql:"todoTree(rootID: $rootID)"`
+}
+
+// kanban is a board where work has to go through review before it's done,
+// and done work can only be reopened into the backlog.
+var kanban = []WorkflowStatusInput{
+	{Name: "Backlog", Category: "TODO", Transitions: []string{"In Progress"}},
+	{Name: "In Progress", Category: "IN_PROGRESS", Transitions: []string{"Backlog", "Review"}},
+	{Name: "Review", Category: "IN_PROGRESS", Transitions: []string{"In Progress", "Done"}},
+	{Name: "Done", Category: "DONE", Transitions: []string{"Backlog"}},
+}
+
+var _ = Describe("Workflow statuses", func() {
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	define := func(statuses []WorkflowStatusInput) (setWorkflow, error) {
+		var q setWorkflow
+		variables := map[string]interface{}{
+			"statuses": statuses,
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	transition := func(todo models.Todo, status string) (transitionTodo, error) {
+		var q transitionTodo
+		variables := map[string]interface{}{
+			"todoID": todo.ID.String(),
+			"status": status,
+		}
+
+		err := tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		return q, err
+	}
+
+	status := func(todo models.Todo) todoStatus {
+		var q todoStatus
+		variables := map[string]interface{}{
+			"rootID": todo.ID.String(),
+		}
+
+		err := tools.DoQuery(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+		Expect(err).To(BeNil())
+		return q
+	}
+
+	Context("Define workflow", func() {
+		It("stores the statuses in order", func() {
+
+			q, err := define(kanban)
+			Expect(err).To(BeNil())
+			Expect(q.Statuses).To(HaveLen(4))
+			Expect(q.Statuses[0].Name).To(Equal("Backlog"))
+			Expect(q.Statuses[2].Transitions).To(HaveLen(2))
+			Expect(q.Statuses[3].Category).To(Equal("DONE"))
+		})
+
+		It("keeps the ids of statuses that keep their name", func() {
+			first, err := define(kanban)
+			Expect(err).To(BeNil())
+
+			second, err := define([]WorkflowStatusInput{
+				{Name: "In Progress", Category: "IN_PROGRESS"},
+				{Name: "Done", Category: "DONE"},
+			})
+			Expect(err).To(BeNil())
+			Expect(second.Statuses).To(HaveLen(2))
+			Expect(second.Statuses[0].ID).To(Equal(first.Statuses[1].ID))
+			Expect(second.Statuses[1].ID).To(Equal(first.Statuses[3].ID))
+			Expect(second.Statuses[0].Transitions).To(HaveLen(1))
+		})
+
+		It("error: no done status", func() {
+
+			_, err := define(kanban[:3])
+			Expect(err.Error()).To(Equal("Message: workflow needs at least one open and one done status, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: duplicate status", func() {
+
+			_, err := define(append([]WorkflowStatusInput{{Name: "Done", Category: "TODO"}}, kanban...))
+			Expect(err.Error()).To(Equal("Message: status names have to be unique in a workflow, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Transition todo", func() {
+		It("moves a todo through the workflow", func() {
+			todo := signInUser1Resp.Todos[0]
+			_, err := define(kanban)
+			Expect(err).To(BeNil())
+
+			Expect(status(todo).Tree[0].Status.Name).To(Equal("Backlog"))
+
+			for _, name := range []string{"In Progress", "Review"} {
+				q, err := transition(todo, name)
+				Expect(err).To(BeNil())
+				Expect(q.Todo.Status.Name).To(Equal(name))
+				Expect(q.Todo.Done).To(BeFalse())
+			}
+
+			q, err := transition(todo, "Done")
+			Expect(err).To(BeNil())
+			Expect(q.Todo.Status.Name).To(Equal("Done"))
+			Expect(q.Todo.Done).To(BeTrue())
+
+			q, err = transition(todo, "Backlog")
+			Expect(err).To(BeNil())
+			Expect(q.Todo.Done).To(BeFalse())
+		})
+
+		It("follows done when it's changed directly", func() {
+			todo := signInUser1Resp.Todos[0]
+			_, err := define(kanban)
+			Expect(err).To(BeNil())
+
+			_, err = transition(todo, "In Progress")
+			Expect(err).To(BeNil())
+
+			var q markCompleteTodo
+			variables := map[string]interface{}{
+				"todoID": todo.ID.String(),
+			}
+			err = tools.DoMutate(&q, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			Expect(status(todo).Tree[0].Status.Name).To(Equal("Done"))
+		})
+
+		It("error: transition that isn't allowed", func() {
+			_, err := define(kanban)
+			Expect(err).To(BeNil())
+
+			_, err = transition(signInUser1Resp.Todos[0], "Done")
+			Expect(err.Error()).To(Equal("Message: transition to this status isn't allowed, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: no workflow", func() {
+
+			_, err := transition(signInUser1Resp.Todos[0], "Done")
+			Expect(err.Error()).To(Equal("Message: no workflow is defined for the todo, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: unknown status", func() {
+			_, err := define(kanban)
+			Expect(err).To(BeNil())
+
+			_, err = transition(signInUser1Resp.Todos[0], "Shipped")
+			Expect(err.Error()).To(Equal("Message: status not found in the workflow, Locations: [], Extensions: map[]"))
+		})
+	})
+})
@@ -0,0 +1,285 @@
+package todo
+
+import (
+	"todo-service/graph/model"
+	interfaceRepository "todo-service/src/interface/repository"
+	"todo-service/src/models"
+	"todo-service/tests/tools"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type WorkspaceRole string
+
+type inviteToWorkspace struct {
+	Invitation struct {
+		ID string `json:"id"`
+	} `graphql:"inviteToWorkspace(workspaceID: $workspaceID, email: $email, role: $role)"`
+}
+
+type acceptWorkspaceInvitation struct {
+	Workspace struct {
+		ID     string               `json:"id"`
+		MyRole models.WorkspaceRole `json:"myRole" graphql:"myRole"`
+	} `graphql:"acceptWorkspaceInvitation(invitationID: $invitationID)"`
+}
+
+var _ = Describe("Workspaces", func() {
+	workspaceA := uuid.MustParse("9a0c3e52-6a43-4d0b-8d0e-3b7f5c2a1e01")
+	workspaceB := uuid.MustParse("c4e1b7a9-2d5f-4f3a-9b6c-8e0d1f2a3b02")
+
+	BeforeEach(func() {
+		resetTodoTables()
+
+		AddUsersToDb()
+		AddTodosToDb()
+
+		workspaces := []models.Workspace{{ID: workspaceA, Name: "A"}, {ID: workspaceB, Name: "B"}}
+		Expect(db.Create(&workspaces).Error).To(BeNil())
+
+		members := []models.WorkspaceMember{
+			{WorkspaceID: workspaceA, UserID: signInUser1Resp.User.ID, Role: models.WorkspaceRoleOwner},
+			{WorkspaceID: workspaceB, UserID: signInUser2Resp.User.ID, Role: models.WorkspaceRoleOwner},
+		}
+		Expect(db.Omit("Workspace", "User").Create(&members).Error).To(BeNil())
+
+		Expect(db.Model(&models.Todo{}).Where("user_id = ?", signInUser2Resp.User.ID).
+			Update("workspace_id", workspaceB).Error).To(BeNil())
+
+		var err error
+		signInUser1Resp.signIn, err = SignIn(signInUser1Resp.User.Email, signInUser1Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+
+		signInUser2Resp.signIn, err = SignIn(signInUser2Resp.User.Email, signInUser2Resp.User.Password)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	Context("TodoRepository tenant isolation", func() {
+		var (
+			repo   interfaceRepository.TodoRepository
+			target models.Todo
+			owner  string
+		)
+
+		BeforeEach(func() {
+			repo = interfaceRepository.NewTodoRepository(db)
+			target = signInUser2Resp.Todos[0]
+			// The owner of the todo is passed on purpose: the tenant alone
+			// has to keep the todo out of reach.
+			owner = signInUser2Resp.User.ID.String()
+		})
+
+		unchanged := func() {
+			var todo models.Todo
+			Expect(db.Unscoped().Take(&todo, "id = ?", target.ID).Error).To(BeNil())
+			Expect(todo.Text).To(Equal(target.Text))
+			Expect(todo.Done).To(Equal(target.Done))
+			Expect(todo.Deleted.Valid).To(Equal(target.Deleted.Valid))
+		}
+
+		for _, tenant := range []struct {
+			name      string
+			workspace *uuid.UUID
+		}{{"another workspace", &workspaceA}, {"personal todos", nil}} {
+			tenant := tenant
+
+			It("can't read todos of "+tenant.name, func() {
+
+				scoped := repo.InWorkspace(tenant.workspace)
+				id := target.ID.String()
+
+				_, err := scoped.GetByID(id, owner)
+				Expect(err).To(Equal(gorm.ErrRecordNotFound))
+
+				todos, err := scoped.ListByIDs([]string{id}, owner)
+				Expect(err).To(BeNil())
+				Expect(todos).To(BeEmpty())
+
+				todos, err = scoped.List(owner)
+				Expect(err).To(BeNil())
+				for _, todo := range todos {
+					Expect(todo.WorkspaceID).ToNot(Equal(&workspaceB))
+				}
+
+				first := 50
+				page, err := scoped.Paginate(models.PageArgs{First: &first}, nil, nil, owner)
+				Expect(err).To(BeNil())
+				for _, edge := range page.Edges {
+					Expect(edge.Node.ID).ToNot(Equal(target.ID))
+				}
+
+				todos, err = scoped.ListChildren(id, owner)
+				Expect(err).To(BeNil())
+				Expect(todos).To(BeEmpty())
+
+				todos, err = scoped.ListTree(&id, owner)
+				Expect(err).To(BeNil())
+				Expect(todos).To(BeEmpty())
+
+				results, err := scoped.Search(target.Text, 10, owner)
+				Expect(err).To(BeNil())
+				Expect(results).To(BeEmpty())
+
+				adjacent, err := scoped.Adjacent(&target, true, id, owner)
+				Expect(err).To(BeNil())
+				if adjacent != nil {
+					Expect(adjacent.WorkspaceID).ToNot(Equal(&workspaceB))
+				}
+			})
+
+			It("can't change todos of "+tenant.name, func() {
+
+				scoped := repo.InWorkspace(tenant.workspace)
+				id := target.ID.String()
+
+				Expect(scoped.Update(id, owner, map[string]interface{}{"text": "changed"})).To(Succeed())
+				Expect(scoped.UpdateMany([]string{id}, owner, map[string]interface{}{"text": "changed"})).To(Succeed())
+				Expect(scoped.MarkComplete([]string{id}, owner, models.CascadeChildren)).To(Succeed())
+				Expect(scoped.Rebalance(owner)).To(Succeed())
+
+				deleted, err := scoped.Delete(id, owner, models.CascadeChildren)
+				Expect(err).To(BeNil())
+				Expect(deleted).To(BeFalse())
+
+				unchanged()
+			})
+
+			It("can't reach the trash of "+tenant.name, func() {
+
+				Expect(db.Delete(&models.Todo{}, "id = ?", target.ID).Error).To(BeNil())
+				Expect(db.Unscoped().Take(&target, "id = ?", target.ID).Error).To(BeNil())
+				scoped := repo.InWorkspace(tenant.workspace)
+
+				todos, err := scoped.ListTrashed(owner)
+				Expect(err).To(BeNil())
+				for _, todo := range todos {
+					Expect(todo.ID).ToNot(Equal(target.ID))
+				}
+
+				restored, err := scoped.Restore(target.ID.String(), owner)
+				Expect(err).To(BeNil())
+				Expect(restored).To(BeFalse())
+
+				_, err = scoped.EmptyTrash(owner, false)
+				Expect(err).To(BeNil())
+
+				unchanged()
+			})
+		}
+
+		It("keeps new todos in the workspace of the repository", func() {
+
+			todo, err := repo.InWorkspace(&workspaceA).Create(model.NewTodo{Text: "in A"}, signInUser1Resp.User.ID.String())
+			Expect(err).To(BeNil())
+			Expect(todo.WorkspaceID).To(Equal(&workspaceA))
+
+			_, err = repo.GetByID(todo.ID.String(), signInUser1Resp.User.ID.String())
+			Expect(err).To(Equal(gorm.ErrRecordNotFound))
+		})
+	})
+
+	Context("Active workspace", func() {
+		It("lists the todos of the selected workspace only", func() {
+
+			var q firstTodos
+			variables := map[string]interface{}{
+				"first": 50,
+			}
+
+			err := tools.DoQueryInWorkspace(&q, variables, signInUser2Resp.Auth.Data.AccessToken, workspaceB.String(), router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.TotalCount).To(Equal(len(signInUser2Resp.Todos)))
+
+			err = tools.DoQuery(&q, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(q.Todos.TotalCount).To(Equal(0))
+		})
+
+		It("error: workspace the user isn't a member of", func() {
+
+			var q firstTodos
+			variables := map[string]interface{}{
+				"first": 50,
+			}
+
+			err := tools.DoQueryInWorkspace(&q, variables, signInUser1Resp.Auth.Data.AccessToken, workspaceB.String(), router)
+			Expect(err.Error()).To(Equal("Message: workspace not found, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: todo of another workspace", func() {
+
+			var q markCompleteTodo
+			variables := map[string]interface{}{
+				"todoID": signInUser2Resp.Todos[0].ID.String(),
+			}
+
+			err := tools.DoMutateInWorkspace(&q, variables, signInUser1Resp.Auth.Data.AccessToken, workspaceA.String(), router)
+			Expect(err.Error()).To(Equal("Message: record not found, Locations: [], Extensions: map[]"))
+		})
+	})
+
+	Context("Invitations", func() {
+		It("lets an invited user join and work on the todos of the workspace", func() {
+
+			var invite inviteToWorkspace
+			variables := map[string]interface{}{
+				"workspaceID": workspaceB.String(),
+				"email":       signInUser1Resp.User.Email,
+				"role":        WorkspaceRole("MEMBER"),
+			}
+
+			err := tools.DoMutate(&invite, variables, signInUser2Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+
+			var accept acceptWorkspaceInvitation
+			variables = map[string]interface{}{
+				"invitationID": invite.Invitation.ID,
+			}
+
+			err = tools.DoMutate(&accept, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err).To(BeNil())
+			Expect(accept.Workspace.MyRole).To(Equal(models.WorkspaceRoleMember))
+
+			var complete markCompleteTodo
+			variables = map[string]interface{}{
+				"todoID": signInUser2Resp.Todos[0].ID.String(),
+			}
+
+			err = tools.DoMutateInWorkspace(&complete, variables, signInUser1Resp.Auth.Data.AccessToken, workspaceB.String(), router)
+			Expect(err).To(BeNil())
+			Expect(complete.Data.Done).To(BeTrue())
+
+			var remove deleteTodo
+			err = tools.DoMutateInWorkspace(&remove, variables, signInUser1Resp.Auth.Data.AccessToken, workspaceB.String(), router)
+			Expect(err.Error()).To(Equal("Message: you don't have permission to do this with the todo, Locations: [], Extensions: map[]"))
+		})
+
+		It("error: invitation for another email", func() {
+
+			invitation := models.WorkspaceInvitation{
+				ID:          uuid.New(),
+				WorkspaceID: workspaceB,
+				Email:       "someone@else.com",
+				Role:        models.WorkspaceRoleMember,
+				InvitedByID: signInUser2Resp.User.ID,
+			}
+			Expect(db.Omit("Workspace").Create(&invitation).Error).To(BeNil())
+
+			var accept acceptWorkspaceInvitation
+			variables := map[string]interface{}{
+				"invitationID": invitation.ID.String(),
+			}
+
+			err := tools.DoMutate(&accept, variables, signInUser1Resp.Auth.Data.AccessToken, router)
+			Expect(err.Error()).To(Equal("Message: invitation not found, Locations: [], Extensions: map[]"))
+		})
+	})
+})
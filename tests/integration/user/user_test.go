@@ -94,7 +94,7 @@ var _ = BeforeSuite(func() {
 	router = echo.New()
 
 	// Initialize Echo instance
-	graphql.NewGraphqlRouter(router, useCase)
+	graphql.NewGraphqlRouter(router, useCase, logger)
 })
 
 func SignIn(email, pwd string) (signIn, error) {
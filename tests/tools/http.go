@@ -1,9 +1,13 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"time"
@@ -56,6 +60,132 @@ func DoMutate(q interface{}, variables map[string]interface{}, access string, e
 	return nil
 }
 
+// DoQueryInWorkspace runs a query with the given workspace selected through
+// the X-Workspace-ID header.
+func DoQueryInWorkspace(q interface{}, variables map[string]interface{}, access string, workspace string, e *echo.Echo) error {
+	client := graphql.NewClient("/api/v1/query", &http.Client{Transport: localRoundTripper{handler: e}}).
+		WithRequestModifier(func(r *http.Request) {
+			r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", access))
+			r.Header.Set("X-Workspace-ID", workspace)
+		})
+
+	return client.Query(context.Background(), q, variables)
+}
+
+// DoMutateInWorkspace runs a mutation with the given workspace selected
+// through the X-Workspace-ID header.
+func DoMutateInWorkspace(q interface{}, variables map[string]interface{}, access string, workspace string, e *echo.Echo) error {
+	client := graphql.NewClient("/api/v1/query", &http.Client{Transport: localRoundTripper{handler: e}}).
+		WithRequestModifier(func(r *http.Request) {
+			r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", access))
+			r.Header.Set("X-Workspace-ID", workspace)
+		})
+
+	return client.Mutate(context.Background(), q, variables)
+}
+
+// DoUpload sends a GraphQL multipart request that uploads content as the
+// variable named file, and decodes the data of the response into q.
+func DoUpload(q interface{}, query string, variables map[string]interface{}, filename string, content []byte, access string, e *echo.Echo) error {
+	variables["file"] = nil
+	operations, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	if err := form.WriteField("operations", string(operations)); err != nil {
+		return err
+	}
+	if err := form.WriteField("map", `{"0": ["variables.file"]}`); err != nil {
+		return err
+	}
+
+	part, err := form.CreateFormFile("0", filename)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(content); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if access != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", access))
+	}
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Errors) > 0 {
+		return errors.New(resp.Errors[0].Message)
+	}
+
+	return json.Unmarshal(resp.Data, q)
+}
+
+// DoRequest posts a GraphQL query as is and decodes the data of the response
+// into q and its extensions into extensions.
+func DoRequest(q interface{}, extensions interface{}, query string, variables map[string]interface{}, access string, e *echo.Echo) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if access != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", access))
+	}
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	var resp struct {
+		Data       json.RawMessage `json:"data"`
+		Extensions json.RawMessage `json:"extensions"`
+		Errors     []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Errors) > 0 {
+		return errors.New(resp.Errors[0].Message)
+	}
+
+	if extensions != nil && len(resp.Extensions) > 0 {
+		if err := json.Unmarshal(resp.Extensions, extensions); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(resp.Data, q)
+}
+
 const (
 	letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 )
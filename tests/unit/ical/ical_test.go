@@ -0,0 +1,167 @@
+package ical
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"todo-service/utils/ical"
+	"unicode/utf8"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestICal(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ICal Suite")
+}
+
+func decodeAll(calendar string, loc *time.Location) []*ical.Todo {
+	decoder := ical.NewDecoder(strings.NewReader(calendar), loc)
+	todos := make([]*ical.Todo, 0)
+	for {
+		todo, err := decoder.Next()
+		if err == io.EOF {
+			return todos
+		}
+		Expect(err).To(BeNil())
+		todos = append(todos, todo)
+	}
+}
+
+var _ = Describe("ICal", func() {
+
+	stamp := time.Date(2030, 5, 1, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2030, 5, 2, 17, 30, 0, 0, time.UTC)
+
+	Describe("Encoder", func() {
+		It("writes an empty calendar", func() {
+			var b bytes.Buffer
+			Expect(ical.NewEncoder(&b, "").Close()).To(Succeed())
+			Expect(b.String()).To(Equal("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:" + ical.ProdID +
+				"\r\nCALSCALE:GREGORIAN\r\nEND:VCALENDAR\r\n"))
+		})
+
+		It("writes the properties of a todo", func() {
+			var b bytes.Buffer
+			encoder := ical.NewEncoder(&b, "Todos")
+			Expect(encoder.Encode(&ical.Todo{
+				UID:        "1",
+				Summary:    "Buy milk, eggs; bread",
+				Status:     ical.StatusNeedsAction,
+				Due:        &due,
+				RRule:      "FREQ=WEEKLY",
+				Priority:   1,
+				Categories: []string{"home", "a,b"},
+				RelatedTo:  "0",
+				Stamp:      stamp,
+			})).To(Succeed())
+			Expect(encoder.Close()).To(Succeed())
+
+			Expect(b.String()).To(ContainSubstring("X-WR-CALNAME:Todos\r\n"))
+			Expect(b.String()).To(ContainSubstring("BEGIN:VTODO\r\nUID:1\r\nDTSTAMP:20300501T080000Z\r\n"))
+			Expect(b.String()).To(ContainSubstring("SUMMARY:Buy milk\\, eggs\\; bread\r\n"))
+			Expect(b.String()).To(ContainSubstring("DUE:20300502T173000Z\r\n"))
+			Expect(b.String()).To(ContainSubstring("RRULE:FREQ=WEEKLY\r\n"))
+			Expect(b.String()).To(ContainSubstring("PRIORITY:1\r\n"))
+			Expect(b.String()).To(ContainSubstring("CATEGORIES:home,a\\,b\r\n"))
+			Expect(b.String()).To(ContainSubstring("RELATED-TO;RELTYPE=PARENT:0\r\n"))
+			Expect(b.String()).NotTo(ContainSubstring("COMPLETED"))
+		})
+
+		It("folds long lines without splitting characters", func() {
+			var b bytes.Buffer
+			encoder := ical.NewEncoder(&b, "")
+			Expect(encoder.Encode(&ical.Todo{UID: "1", Summary: strings.Repeat("ä", 100), Stamp: stamp})).To(Succeed())
+			Expect(encoder.Close()).To(Succeed())
+
+			Expect(decodeAll(b.String(), time.UTC)[0].Summary).To(Equal(strings.Repeat("ä", 100)))
+			for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+				Expect(len(line)).To(BeNumerically("<=", 75))
+				Expect(utf8.ValidString(line)).To(BeTrue())
+			}
+		})
+	})
+
+	Describe("Decoder", func() {
+		It("round-trips what the encoder writes", func() {
+			completed := time.Date(2030, 5, 3, 9, 0, 0, 0, time.UTC)
+			todos := []*ical.Todo{
+				{UID: "a", Summary: "Plan trip\nwith notes, \\ and ;", Status: ical.StatusNeedsAction, Due: &due,
+					RRule: "FREQ=DAILY;COUNT=3", Priority: 5, Categories: []string{"travel", "x,y"}, Stamp: stamp},
+				{UID: "b", Summary: strings.Repeat("long ", 40), Status: ical.StatusCompleted, Completed: &completed,
+					RelatedTo: "a", Stamp: stamp},
+			}
+
+			var b bytes.Buffer
+			encoder := ical.NewEncoder(&b, "")
+			for _, todo := range todos {
+				Expect(encoder.Encode(todo)).To(Succeed())
+			}
+			Expect(encoder.Close()).To(Succeed())
+
+			decoded := decodeAll(b.String(), time.UTC)
+			Expect(decoded).To(HaveLen(2))
+			for i := range todos {
+				Expect(decoded[i].UID).To(Equal(todos[i].UID))
+				Expect(decoded[i].Summary).To(Equal(todos[i].Summary))
+				Expect(decoded[i].Status).To(Equal(todos[i].Status))
+				Expect(decoded[i].RRule).To(Equal(todos[i].RRule))
+				Expect(decoded[i].Priority).To(Equal(todos[i].Priority))
+				Expect(decoded[i].Categories).To(Equal(todos[i].Categories))
+				Expect(decoded[i].RelatedTo).To(Equal(todos[i].RelatedTo))
+				Expect(decoded[i].Stamp).To(Equal(stamp))
+				Expect(decoded[i].Invalid).To(BeEmpty())
+			}
+			Expect(*decoded[0].Due).To(Equal(due))
+			Expect(*decoded[1].Completed).To(Equal(completed))
+		})
+
+		It("reads dates and times with and without a time zone", func() {
+			berlin, err := time.LoadLocation("Europe/Berlin")
+			Expect(err).To(BeNil())
+
+			todos := decodeAll("BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\nDUE;TZID=Europe/Berlin:20300502T090000\nEND:VTODO\n"+
+				"BEGIN:VTODO\nUID:2\nDUE;VALUE=DATE:20300502\nEND:VTODO\n"+
+				"BEGIN:VTODO\nUID:3\nDUE:20300502T090000\nEND:VTODO\nEND:VCALENDAR\n", berlin)
+
+			Expect(todos).To(HaveLen(3))
+			Expect(todos[0].Due.UTC()).To(Equal(time.Date(2030, 5, 2, 7, 0, 0, 0, time.UTC)))
+			Expect(todos[1].Due.Equal(time.Date(2030, 5, 2, 0, 0, 0, 0, berlin))).To(BeTrue())
+			Expect(todos[2].Due.Equal(time.Date(2030, 5, 2, 9, 0, 0, 0, berlin))).To(BeTrue())
+		})
+
+		It("unfolds lines and skips other components", func() {
+			todos := decodeAll("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:event\r\nSUMMARY:Not a todo\r\nEND:VEVENT\r\n"+
+				"BEGIN:VTODO\r\nUID:1\r\nSUMMARY:Water\r\n  the plants\r\nBEGIN:VALARM\r\nSUMMARY:Alarm\r\nEND:VALARM\r\n"+
+				"END:VTODO\r\nEND:VCALENDAR\r\n", time.UTC)
+
+			Expect(todos).To(HaveLen(1))
+			Expect(todos[0].UID).To(Equal("1"))
+			Expect(todos[0].Summary).To(Equal("Water the plants"))
+		})
+
+		It("notes values it can't read", func() {
+			todos := decodeAll("BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\nDUE:someday\nPRIORITY:12\nEND:VTODO\nEND:VCALENDAR\n", time.UTC)
+
+			Expect(todos).To(HaveLen(1))
+			Expect(todos[0].Due).To(BeNil())
+			Expect(todos[0].Priority).To(Equal(0))
+			Expect(todos[0].Invalid).To(Equal([]string{"DUE", "PRIORITY"}))
+		})
+
+		It("error: unterminated todo", func() {
+			decoder := ical.NewDecoder(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\n"), time.UTC)
+			_, err := decoder.Next()
+			Expect(err).To(Equal(ical.ErrInvalidCalendar))
+		})
+
+		It("error: not a calendar", func() {
+			decoder := ical.NewDecoder(strings.NewReader("text\nnot a calendar\n"), time.UTC)
+			_, err := decoder.Next()
+			Expect(err).To(Equal(ical.ErrInvalidCalendar))
+		})
+	})
+})
@@ -0,0 +1,193 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+	"todo-service/utils/quickadd"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQuickAdd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Quick-add Suite")
+}
+
+func mustLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+var berlin = mustLocation("Europe/Berlin")
+
+// now is Wednesday, 15 May 2024, 10:30 in Berlin.
+var now = time.Date(2024, time.May, 15, 10, 30, 0, 0, berlin)
+
+func at(year int, month time.Month, day int, hour int, minute int) *time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, berlin)
+	return &t
+}
+
+type expected struct {
+	text     string
+	due      *time.Time
+	priority string
+	tags     []string
+	rrule    string
+}
+
+var _ = Describe("Quick-add", func() {
+
+	DescribeTable("Parse",
+		func(input string, want expected) {
+			got := quickadd.Parse(input, now, berlin)
+
+			Expect(got.Text).To(Equal(want.text))
+			Expect(got.Priority).To(Equal(want.priority))
+			Expect(got.RRule).To(Equal(want.rrule))
+			if want.tags == nil {
+				Expect(got.Tags).To(BeEmpty())
+			} else {
+				Expect(got.Tags).To(Equal(want.tags))
+			}
+			if want.due == nil {
+				Expect(got.DueAt).To(BeNil())
+			} else {
+				Expect(got.DueAt).NotTo(BeNil())
+				Expect(got.DueAt.Equal(*want.due)).To(BeTrue(), "due %s, want %s", got.DueAt, want.due)
+			}
+		},
+
+		// Plain text
+		Entry("text without details", "Buy milk",
+			expected{text: "Buy milk"}),
+		Entry("collapses whitespace", "  Buy   milk ",
+			expected{text: "Buy milk"}),
+		Entry("keeps numbers that aren't times", "Buy 6 eggs",
+			expected{text: "Buy 6 eggs"}),
+		Entry("keeps a lone exclamation mark", "Call mum !",
+			expected{text: "Call mum !"}),
+		Entry("keeps daily as a word", "Write daily report",
+			expected{text: "Write daily report"}),
+		Entry("keeps the text when it's only details", "tomorrow",
+			expected{text: "tomorrow", due: at(2024, time.May, 16, 9, 0)}),
+
+		// The example from the request
+		Entry("everything at once", "Pay rent tomorrow 9am !high #finance every month",
+			expected{text: "Pay rent", due: at(2024, time.May, 16, 9, 0), priority: "HIGH",
+				tags: []string{"finance"}, rrule: "FREQ=MONTHLY"}),
+
+		// Priorities
+		Entry("!high", "Fix prod !high", expected{text: "Fix prod", priority: "HIGH"}),
+		Entry("!h", "Fix prod !h", expected{text: "Fix prod", priority: "HIGH"}),
+		Entry("!3", "Fix prod !3", expected{text: "Fix prod", priority: "HIGH"}),
+		Entry("!!!", "Fix prod !!!", expected{text: "Fix prod", priority: "HIGH"}),
+		Entry("!medium", "Fix prod !medium", expected{text: "Fix prod", priority: "MEDIUM"}),
+		Entry("!!", "Fix prod !!", expected{text: "Fix prod", priority: "MEDIUM"}),
+		Entry("!low", "!low Fix prod", expected{text: "Fix prod", priority: "LOW"}),
+		Entry("!1", "Fix !1 prod", expected{text: "Fix prod", priority: "LOW"}),
+		Entry("last priority wins", "Fix prod !low !HIGH", expected{text: "Fix prod", priority: "HIGH"}),
+
+		// Tags
+		Entry("one tag", "Pay rent #finance", expected{text: "Pay rent", tags: []string{"finance"}}),
+		Entry("several tags in order", "#home Pay rent #Finance",
+			expected{text: "Pay rent", tags: []string{"home", "finance"}}),
+		Entry("duplicate tags", "Pay rent #finance #FINANCE",
+			expected{text: "Pay rent", tags: []string{"finance"}}),
+		Entry("tag with punctuation after it", "Pay rent #finance, now",
+			expected{text: "Pay rent now", tags: []string{"finance"}}),
+		Entry("nested tag", "Plan #work/q3-roadmap", expected{text: "Plan", tags: []string{"work/q3-roadmap"}}),
+		Entry("lone hash", "Call # later", expected{text: "Call # later"}),
+		Entry("hash inside a word", "Learn C#", expected{text: "Learn C#"}),
+
+		// Dates
+		Entry("today", "Call Bob today", expected{text: "Call Bob", due: at(2024, time.May, 15, 9, 0)}),
+		Entry("tonight", "Call Bob tonight", expected{text: "Call Bob", due: at(2024, time.May, 15, 20, 0)}),
+		Entry("tonight with a time", "Call Bob tonight at 10pm",
+			expected{text: "Call Bob", due: at(2024, time.May, 15, 22, 0)}),
+		Entry("tomorrow", "Call Bob tomorrow", expected{text: "Call Bob", due: at(2024, time.May, 16, 9, 0)}),
+		Entry("tmrw", "Call Bob tmrw", expected{text: "Call Bob", due: at(2024, time.May, 16, 9, 0)}),
+		Entry("weekday later this week", "Call Bob friday", expected{text: "Call Bob", due: at(2024, time.May, 17, 9, 0)}),
+		Entry("weekday earlier in the week", "Call Bob monday", expected{text: "Call Bob", due: at(2024, time.May, 20, 9, 0)}),
+		Entry("same weekday is next week", "Call Bob wednesday", expected{text: "Call Bob", due: at(2024, time.May, 22, 9, 0)}),
+		Entry("next weekday", "Call Bob next fri", expected{text: "Call Bob", due: at(2024, time.May, 17, 9, 0)}),
+		Entry("next week", "Call Bob next week", expected{text: "Call Bob", due: at(2024, time.May, 20, 9, 0)}),
+		Entry("next month", "Call Bob next month", expected{text: "Call Bob", due: at(2024, time.June, 1, 9, 0)}),
+		Entry("on connector", "Call Bob on friday", expected{text: "Call Bob", due: at(2024, time.May, 17, 9, 0)}),
+		Entry("due connector", "Report due tomorrow", expected{text: "Report", due: at(2024, time.May, 16, 9, 0)}),
+		Entry("connector without a date stays", "Meet at the station", expected{text: "Meet at the station"}),
+		Entry("iso date", "Renew passport 2024-09-01", expected{text: "Renew passport", due: at(2024, time.September, 1, 9, 0)}),
+		Entry("invalid iso date stays", "Renew passport 2024-02-30", expected{text: "Renew passport 2024-02-30"}),
+		Entry("month day", "Party on June 3", expected{text: "Party", due: at(2024, time.June, 3, 9, 0)}),
+		Entry("month day with suffix and year", "Party Dec 31st, 2025", expected{text: "Party", due: at(2025, time.December, 31, 9, 0)}),
+		Entry("day month", "Party 3rd june", expected{text: "Party", due: at(2024, time.June, 3, 9, 0)}),
+		Entry("past month day is next year", "Party march 1", expected{text: "Party", due: at(2025, time.March, 1, 9, 0)}),
+		Entry("month day that doesn't exist stays", "Party feb 30", expected{text: "Party feb 30"}),
+		Entry("month name without a day stays", "Plan for May", expected{text: "Plan for May"}),
+		Entry("in days", "Follow up in 3 days", expected{text: "Follow up", due: at(2024, time.May, 18, 9, 0)}),
+		Entry("in a week", "Follow up in a week", expected{text: "Follow up", due: at(2024, time.May, 22, 9, 0)}),
+		Entry("in months", "Follow up in 2 months", expected{text: "Follow up", due: at(2024, time.July, 15, 9, 0)}),
+		Entry("in hours is exact", "Take the cake out in 2 hours", expected{text: "Take the cake out", due: at(2024, time.May, 15, 12, 30)}),
+		Entry("in minutes is exact", "Stretch in 45 min", expected{text: "Stretch", due: at(2024, time.May, 15, 11, 15)}),
+		Entry("in without an amount stays", "Check in with Bob", expected{text: "Check in with Bob"}),
+
+		// Times
+		Entry("time later today", "Call Bob 3pm", expected{text: "Call Bob", due: at(2024, time.May, 15, 15, 0)}),
+		Entry("time that has passed is tomorrow", "Call Bob 9am", expected{text: "Call Bob", due: at(2024, time.May, 16, 9, 0)}),
+		Entry("time with minutes", "Call Bob at 4:45pm", expected{text: "Call Bob", due: at(2024, time.May, 15, 16, 45)}),
+		Entry("time with a separate suffix", "Call Bob at 5 PM", expected{text: "Call Bob", due: at(2024, time.May, 15, 17, 0)}),
+		Entry("24 hour time", "Call Bob 21:15", expected{text: "Call Bob", due: at(2024, time.May, 15, 21, 15)}),
+		Entry("12am is midnight", "Deploy tomorrow 12am", expected{text: "Deploy", due: at(2024, time.May, 16, 0, 0)}),
+		Entry("12pm is noon", "Lunch tomorrow 12pm", expected{text: "Lunch", due: at(2024, time.May, 16, 12, 0)}),
+		Entry("noon", "Lunch at noon", expected{text: "Lunch", due: at(2024, time.May, 15, 12, 0)}),
+		Entry("midnight", "Backup at midnight", expected{text: "Backup", due: at(2024, time.May, 16, 0, 0)}),
+		Entry("time before the date", "Call Bob 8am friday", expected{text: "Call Bob", due: at(2024, time.May, 17, 8, 0)}),
+		Entry("invalid time stays", "Call Bob 13pm", expected{text: "Call Bob 13pm"}),
+		Entry("invalid 24 hour time stays", "Score was 25:10", expected{text: "Score was 25:10"}),
+
+		// Recurrence
+		Entry("every day", "Stand-up every day at 9:15am",
+			expected{text: "Stand-up", due: at(2024, time.May, 16, 9, 15), rrule: "FREQ=DAILY"}),
+		Entry("every week starts today", "Review every week",
+			expected{text: "Review", due: at(2024, time.May, 15, 9, 0), rrule: "FREQ=WEEKLY"}),
+		Entry("every other week", "Payroll every other week friday",
+			expected{text: "Payroll", due: at(2024, time.May, 17, 9, 0), rrule: "FREQ=WEEKLY;INTERVAL=2"}),
+		Entry("every n months", "Dentist every 6 months",
+			expected{text: "Dentist", due: at(2024, time.May, 15, 9, 0), rrule: "FREQ=MONTHLY;INTERVAL=6"}),
+		Entry("every year", "Birthday every year June 3",
+			expected{text: "Birthday", due: at(2024, time.June, 3, 9, 0), rrule: "FREQ=YEARLY"}),
+		Entry("every weekday", "Check mail every weekday",
+			expected{text: "Check mail", due: at(2024, time.May, 16, 9, 0), rrule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}),
+		Entry("every weekday later today", "Check mail every weekday 5pm",
+			expected{text: "Check mail", due: at(2024, time.May, 15, 17, 0), rrule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}),
+		Entry("every monday", "Plan the week every monday",
+			expected{text: "Plan the week", due: at(2024, time.May, 20, 9, 0), rrule: "FREQ=WEEKLY;BYDAY=MO"}),
+		Entry("every monday and thursday", "Gym every monday and thursday 7am",
+			expected{text: "Gym", due: at(2024, time.May, 16, 7, 0), rrule: "FREQ=WEEKLY;BYDAY=MO,TH"}),
+		Entry("every list of days", "Gym every tue, thu, saturday",
+			expected{text: "Gym", due: at(2024, time.May, 16, 9, 0), rrule: "FREQ=WEEKLY;BYDAY=TU,TH,SA"}),
+		Entry("every without a unit stays", "Read every page", expected{text: "Read every page"}),
+		Entry("every hour isn't supported", "Drink water every hour", expected{text: "Drink water every hour"}),
+	)
+
+	It("reads dates in the given time zone", func() {
+		// 23:30 UTC on the 15th is already the 16th in Berlin.
+		got := quickadd.Parse("Call Bob today", time.Date(2024, time.May, 15, 23, 30, 0, 0, time.UTC), berlin)
+		Expect(got.DueAt.Equal(*at(2024, time.May, 16, 9, 0))).To(BeTrue())
+	})
+
+	It("falls back to UTC without a time zone", func() {
+		got := quickadd.Parse("Call Bob tomorrow 9am", time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC), nil)
+		Expect(got.DueAt.Equal(time.Date(2024, time.May, 16, 9, 0, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	It("keeps the wall-clock time across a DST change", func() {
+		got := quickadd.Parse("Call Bob in 1 week", time.Date(2024, time.March, 27, 10, 0, 0, 0, berlin), berlin)
+		Expect(got.DueAt.Equal(time.Date(2024, time.April, 3, 9, 0, 0, 0, berlin))).To(BeTrue())
+		Expect(got.DueAt.UTC().Hour()).To(Equal(7))
+	})
+})
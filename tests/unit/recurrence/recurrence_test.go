@@ -0,0 +1,89 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+	"todo-service/utils/recurrence"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRecurrence(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Recurrence Suite")
+}
+
+func mustLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+var _ = Describe("Recurrence", func() {
+
+	Describe("Normalize", func() {
+		It("strips the RRULE prefix", func() {
+			rule, err := recurrence.Normalize("RRULE:FREQ=DAILY")
+			Expect(err).To(BeNil())
+			Expect(rule).To(Equal("FREQ=DAILY"))
+		})
+
+		It("keeps an empty rule empty", func() {
+			rule, err := recurrence.Normalize("  ")
+			Expect(err).To(BeNil())
+			Expect(rule).To(BeEmpty())
+		})
+
+		It("rejects an invalid rule", func() {
+			_, err := recurrence.Normalize("FREQ=SOMETIMES")
+			Expect(err).To(Equal(recurrence.ErrInvalidRule))
+		})
+	})
+
+	Describe("Next", func() {
+		newYork := mustLocation("America/New_York")
+
+		It("keeps the wall-clock time of a daily rule across DST", func() {
+			start := time.Date(2026, 3, 1, 9, 0, 0, 0, newYork)
+			after := time.Date(2026, 3, 7, 9, 0, 0, 0, newYork)
+
+			next, ok, err := recurrence.Next("FREQ=DAILY", start, after, newYork)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+			Expect(next.In(newYork).Hour()).To(Equal(9))
+			Expect(next.UTC()).To(Equal(time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC)))
+		})
+
+		It("skips the weekend for a weekly rule on weekdays", func() {
+			start := time.Date(2026, 1, 5, 8, 0, 0, 0, newYork)
+			friday := time.Date(2026, 1, 9, 8, 0, 0, 0, newYork)
+
+			next, ok, err := recurrence.Next("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", start, friday, newYork)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+			Expect(next.Weekday()).To(Equal(time.Monday))
+			Expect(next.Day()).To(Equal(12))
+		})
+
+		It("picks the last Friday of the next month", func() {
+			start := time.Date(2026, 1, 30, 17, 0, 0, 0, newYork)
+
+			next, ok, err := recurrence.Next("FREQ=MONTHLY;BYDAY=-1FR", start, start, newYork)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+			Expect(next).To(BeTemporally("==", time.Date(2026, 2, 27, 17, 0, 0, 0, newYork)))
+		})
+
+		It("ends a series exhausted by COUNT", func() {
+			start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+			second := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+
+			_, ok, err := recurrence.Next("FREQ=DAILY;COUNT=2", start, second, time.UTC)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"testing"
+	"todo-service/utils/search"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSearch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Search Suite")
+}
+
+var _ = Describe("Search", func() {
+
+	DescribeTable("Query",
+		func(input string, want string) {
+			Expect(search.Query(input)).To(Equal(want))
+		},
+
+		// Words
+		Entry("empty input", "", ""),
+		Entry("only whitespace", " \t\n ", ""),
+		Entry("a single word", "milk", "milk"),
+		Entry("ANDs words", "buy milk", "buy & milk"),
+		Entry("collapses whitespace", "  buy \t milk  ", "buy & milk"),
+		Entry("keeps digits", "room 101", "room & 101"),
+		Entry("keeps letters of other scripts", "Müsli café", "Müsli & café"),
+
+		// Phrases
+		Entry("a quoted phrase", `"buy milk"`, "(buy <-> milk)"),
+		Entry("a phrase and a word", `"buy milk" today`, "(buy <-> milk) & today"),
+		Entry("a quoted single word", `"milk"`, "milk"),
+		Entry("an unclosed quote runs to the end", `"buy milk`, "(buy <-> milk)"),
+		Entry("an empty phrase", `""`, ""),
+
+		// Prefixes
+		Entry("a prefix", "mil*", "mil:*"),
+		Entry("a prefix at the end of a phrase", `"buy mil*"`, "(buy <-> mil:*)"),
+		Entry("a lone star", "*", ""),
+
+		// Exclusions
+		Entry("an excluded word", "milk -eggs", "milk & !eggs"),
+		Entry("an excluded phrase", `-"buy milk"`, "!(buy <-> milk)"),
+		Entry("an excluded prefix", "-mil*", "!mil:*"),
+		Entry("a lone minus", "milk -", "milk"),
+
+		// Punctuation
+		Entry("drops punctuation around words", "milk, eggs!", "milk & eggs"),
+		Entry("splits words joined by punctuation into a phrase", "e-mail", "(e <-> mail)"),
+		Entry("splits a word at an apostrophe", "it's", "(it <-> s)"),
+
+		// tsquery operators
+		Entry("drops a standalone operator", "milk & eggs", "milk & eggs"),
+		Entry("drops every operator", "&|!():", ""),
+		Entry("drops operators around words", "!(milk|eggs)", "(milk <-> eggs)"),
+		Entry("drops a weight", "milk:A", "(milk <-> A)"),
+		Entry("drops a prefix operator in the middle", "mil:*k", "(mil <-> k)"),
+	)
+
+	DescribeTable("Tokenize",
+		func(input string, want []string) {
+			if want == nil {
+				Expect(search.Tokenize(input)).To(BeEmpty())
+			} else {
+				Expect(search.Tokenize(input)).To(Equal(want))
+			}
+		},
+
+		Entry("empty input", "", nil),
+		Entry("only whitespace", " \t\n ", nil),
+		Entry("splits on any whitespace", "buy\tmilk\ntoday", []string{"buy", "milk", "today"}),
+		Entry("keeps a phrase together", `"buy  milk" today`, []string{"buy  milk", "today"}),
+		Entry("drops an empty phrase", `"" milk`, []string{"milk"}),
+		Entry("ends a token at a closing quote", `a"b c"d`, []string{"ab c", "d"}),
+		Entry("keeps punctuation", "milk, -eggs*", []string{"milk,", "-eggs*"}),
+		Entry("keeps an unclosed phrase", `milk "buy eggs`, []string{"milk", "buy eggs"}),
+	)
+})
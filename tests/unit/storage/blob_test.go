@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"todo-service/src/infrastructure/storage"
+	"todo-service/src/usecase/repository"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestStorage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Storage Suite")
+}
+
+// behavesLikeBlobStore runs the specs every blob store has to pass.
+func behavesLikeBlobStore(store func() repository.BlobStore) {
+	It("reads back what was put", func() {
+		content := []byte("hello attachment")
+		Expect(store().Put("user/todo/file", bytes.NewReader(content), int64(len(content)), "text/plain")).To(Succeed())
+
+		reader, err := store().Get("user/todo/file")
+		Expect(err).To(BeNil())
+		defer reader.Close()
+
+		read, err := io.ReadAll(reader)
+		Expect(err).To(BeNil())
+		Expect(read).To(Equal(content))
+	})
+
+	It("deletes blobs and ignores missing ones", func() {
+		content := []byte("short lived")
+		Expect(store().Put("user/todo/gone", bytes.NewReader(content), int64(len(content)), "text/plain")).To(Succeed())
+
+		Expect(store().Delete("user/todo/gone")).To(Succeed())
+		Expect(store().Delete("user/todo/gone")).To(Succeed())
+	})
+}
+
+var _ = Describe("Blob stores", func() {
+
+	Describe("LocalBlobStore", func() {
+		var local *storage.LocalBlobStore
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			local = storage.NewLocalBlobStore(dir, "http://files.test", "secret")
+		})
+
+		behavesLikeBlobStore(func() repository.BlobStore { return local })
+
+		It("keeps keys inside its directory", func() {
+			content := []byte("escape")
+			Expect(local.Put("../../outside", bytes.NewReader(content), int64(len(content)), "text/plain")).To(Succeed())
+
+			reader, err := local.Get("outside")
+			Expect(err).To(BeNil())
+			reader.Close()
+		})
+
+		It("signs links that verify until they expire", func() {
+			signed, err := local.SignedURL("user/todo/file", "report.pdf", time.Minute)
+			Expect(err).To(BeNil())
+
+			link, err := url.Parse(signed)
+			Expect(err).To(BeNil())
+			Expect(link.Path).To(Equal("/api/v1/files/user/todo/file"))
+
+			expires, signature := link.Query().Get("expires"), link.Query().Get("signature")
+			Expect(local.Verify("user/todo/file", expires, signature)).To(BeTrue())
+			Expect(local.Verify("user/todo/other", expires, signature)).To(BeFalse())
+			Expect(local.Verify("user/todo/file", expires+"0", signature)).To(BeFalse())
+
+			expired, err := local.SignedURL("user/todo/file", "report.pdf", -time.Minute)
+			Expect(err).To(BeNil())
+			link, _ = url.Parse(expired)
+			Expect(local.Verify("user/todo/file", link.Query().Get("expires"), link.Query().Get("signature"))).To(BeFalse())
+		})
+	})
+
+	Describe("S3BlobStore", func() {
+		var s3 *storage.S3BlobStore
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			backend := s3mem.New()
+			Expect(backend.CreateBucket("attachments")).To(Succeed())
+			server = httptest.NewServer(gofakes3.New(backend).Server())
+
+			var err error
+			s3, err = storage.NewS3BlobStore(strings.TrimPrefix(server.URL, "http://"), "key", "secret",
+				"us-east-1", "attachments", false)
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		behavesLikeBlobStore(func() repository.BlobStore { return s3 })
+
+		It("presigns downloads with the file name", func() {
+			content := []byte("%PDF-1.4")
+			Expect(s3.Put("user/todo/report", bytes.NewReader(content), int64(len(content)), "application/pdf")).To(Succeed())
+
+			signed, err := s3.SignedURL("user/todo/report", "report.pdf", time.Minute)
+			Expect(err).To(BeNil())
+			Expect(signed).To(ContainSubstring("X-Amz-Signature="))
+			Expect(signed).To(ContainSubstring("response-content-disposition="))
+
+			resp, err := http.Get(signed)
+			Expect(err).To(BeNil())
+			defer resp.Body.Close()
+
+			read, err := io.ReadAll(resp.Body)
+			Expect(err).To(BeNil())
+			Expect(read).To(Equal(content))
+		})
+
+		It("never verifies links itself", func() {
+			Expect(s3.Verify("user/todo/report", "0", "")).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,249 @@
+package todotxt
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todo-service/utils/todotxt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTodoTxt(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TodoTxt Suite")
+}
+
+func readAll(content string) []*todotxt.Task {
+	reader := todotxt.NewReader(strings.NewReader(content))
+	tasks := make([]*todotxt.Task, 0)
+	for {
+		task, err := reader.Read()
+		if err == io.EOF {
+			return tasks
+		}
+		Expect(err).To(BeNil())
+		tasks = append(tasks, task)
+	}
+}
+
+func writeAll(tasks []*todotxt.Task) string {
+	var b bytes.Buffer
+	writer := todotxt.NewWriter(&b)
+	for _, task := range tasks {
+		Expect(writer.Write(task)).To(Succeed())
+	}
+	Expect(writer.Close()).To(Succeed())
+	return b.String()
+}
+
+// lines are the lines of a file a reader returns tasks for.
+func lines(content string) []string {
+	kept := make([]string, 0)
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if i == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) != "" {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// corpus combines the parts a line can start with and descriptions into
+// every line they make.
+func corpus() []string {
+	markers := []string{"", "x ", "X ", "x  "}
+	priorities := []string{"", "(A) ", "(B) ", "(Z) ", "(a) ", "(A)", "(AA) "}
+	dates := []string{"", "2030-05-01 ", "2030-05-01 2030-04-30 ", "2030-02-30 ", "2030-05-01", "2030-5-1 "}
+	descriptions := []string{
+		"Call Mom",
+		"Call Mom +Family @phone",
+		"+Family first then text",
+		"@phone first then text",
+		"Pay rent due:2030-06-01 rec:1m",
+		"Review https://example.com/pr/1 lang:en",
+		"Meet at 3:30pm",
+		"a:b:c :x y: z:",
+		"2030-05-01 starts with a date",
+		"(B) starts with a priority",
+		"x starts with a marker",
+		"Café 日本語 🎉 +Fête @maison",
+		"  leading and trailing spaces  ",
+		"",
+	}
+
+	lines := make([]string, 0)
+	for _, marker := range markers {
+		for _, priority := range priorities {
+			for _, date := range dates {
+				for _, description := range descriptions {
+					line := marker + priority + date + description
+					if strings.TrimSpace(line) != "" {
+						lines = append(lines, line)
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+var _ = Describe("TodoTxt", func() {
+
+	date := func(value string) *time.Time {
+		t, err := time.Parse(todotxt.DateFormat, value)
+		Expect(err).To(BeNil())
+		return &t
+	}
+
+	Describe("Parse", func() {
+		It("reads an open task", func() {
+			task := todotxt.Parse("(A) 2011-03-02 Call Mom +Family @phone due:2011-03-05")
+			Expect(task.Done).To(BeFalse())
+			Expect(task.Priority).To(Equal("A"))
+			Expect(task.Completed).To(BeNil())
+			Expect(task.Created).To(Equal(date("2011-03-02")))
+			Expect(task.Description).To(Equal("Call Mom +Family @phone due:2011-03-05"))
+			Expect(task.Projects()).To(Equal([]string{"Family"}))
+			Expect(task.Contexts()).To(Equal([]string{"phone"}))
+			Expect(task.Extensions()).To(Equal([]todotxt.Extension{{Key: "due", Value: "2011-03-05"}}))
+		})
+
+		It("reads a done task", func() {
+			task := todotxt.Parse("x 2011-03-02 2011-03-01 Review Tim's pull request +TodoTxtTouch @github")
+			Expect(task.Done).To(BeTrue())
+			Expect(task.Priority).To(BeEmpty())
+			Expect(task.Completed).To(Equal(date("2011-03-02")))
+			Expect(task.Created).To(Equal(date("2011-03-01")))
+			Expect(task.Description).To(Equal("Review Tim's pull request +TodoTxtTouch @github"))
+		})
+
+		It("reads a single date of a done task as its completion", func() {
+			task := todotxt.Parse("x 2011-03-03 Call Mom")
+			Expect(task.Completed).To(Equal(date("2011-03-03")))
+			Expect(task.Created).To(BeNil())
+		})
+
+		It("only reads markers, priorities and dates where they belong", func() {
+			for _, line := range []string{
+				"Really gotta call Mom (A) @phone @someday",
+				"(b) Get back to the boss",
+				"(B)->Submit TPS report",
+				"X 2012-01-01 Make resolutions",
+				"xylophone practice",
+				"(A) Call Mom 2011-03-02",
+			} {
+				task := todotxt.Parse(line)
+				Expect(task.Done).To(BeFalse(), line)
+				Expect(task.Completed).To(BeNil(), line)
+			}
+
+			Expect(todotxt.Parse("(b) Get back to the boss").Priority).To(BeEmpty())
+			Expect(todotxt.Parse("(A) Call Mom 2011-03-02").Created).To(BeNil())
+			Expect(todotxt.Parse("2024-02-30 Not a date").Created).To(BeNil())
+			Expect(todotxt.Parse("2024-02-30 Not a date").Description).To(Equal("2024-02-30 Not a date"))
+		})
+
+		It("leaves links and colons out of the extensions", func() {
+			task := todotxt.Parse("Read https://example.com lang:en a:b:c :x y:")
+			Expect(task.Extensions()).To(Equal([]todotxt.Extension{{Key: "lang", Value: "en"}}))
+
+			value, ok := task.Extension("lang")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal("en"))
+			_, ok = task.Extension("due")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("removes extensions from the description", func() {
+			task := todotxt.Parse("Pay rent due:2030-06-01 +Home rec:1m lang:en")
+			Expect(task.Without("due", "rec")).To(Equal("Pay rent +Home lang:en"))
+			Expect(task.Without()).To(Equal(task.Description))
+		})
+	})
+
+	Describe("String", func() {
+		It("writes the parts of a task in order", func() {
+			task := &todotxt.Task{
+				Done:        true,
+				Priority:    "A",
+				Completed:   date("2030-05-02"),
+				Created:     date("2030-05-01"),
+				Description: "Call Mom +Family",
+			}
+			Expect(task.String()).To(Equal("x (A) 2030-05-02 2030-05-01 Call Mom +Family"))
+
+			task.Completed = nil
+			Expect(task.String()).To(Equal("x (A) Call Mom +Family"))
+
+			task.Done = false
+			Expect(task.String()).To(Equal("(A) 2030-05-01 Call Mom +Family"))
+		})
+
+		It("writes line breaks as spaces", func() {
+			Expect(writeAll([]*todotxt.Task{{Description: "one\ntwo\r\nthree"}})).To(Equal("one two three\n"))
+		})
+	})
+
+	Describe("Reader", func() {
+		It("skips blank lines, line feeds and byte order marks", func() {
+			tasks := readAll("\ufeff(A) First\r\n\r\n   \nx Second\n")
+			Expect(tasks).To(HaveLen(2))
+			Expect(tasks[0].Priority).To(Equal("A"))
+			Expect(tasks[0].Description).To(Equal("First"))
+			Expect(tasks[1].Done).To(BeTrue())
+		})
+
+		It("error: line too long", func() {
+			reader := todotxt.NewReader(strings.NewReader(strings.Repeat("a", 1<<17)))
+			_, err := reader.Read()
+			Expect(err).To(Equal(todotxt.ErrInvalidFile))
+		})
+	})
+
+	Describe("Round trip", func() {
+		It("writes every sample file back as it was read", func() {
+			files, err := filepath.Glob("testdata/*.txt")
+			Expect(err).To(BeNil())
+			Expect(files).NotTo(BeEmpty())
+
+			for _, file := range files {
+				content, err := os.ReadFile(file)
+				Expect(err).To(BeNil())
+
+				written := writeAll(readAll(string(content)))
+				Expect(lines(written)).To(Equal(lines(string(content))), file)
+				Expect(writeAll(readAll(written))).To(Equal(written), file)
+			}
+		})
+
+		It("writes every line of the generated corpus back as it was read", func() {
+			lines := corpus()
+			Expect(len(lines)).To(BeNumerically(">", 2000))
+
+			for _, line := range lines {
+				Expect(todotxt.Parse(line).String()).To(Equal(line))
+			}
+
+			content := strings.Join(lines, "\n") + "\n"
+			Expect(writeAll(readAll(content))).To(Equal(content))
+		})
+
+		It("reads a written task back", func() {
+			task := &todotxt.Task{
+				Priority:    "C",
+				Created:     date("2030-05-01"),
+				Description: "2030-04-01 starts with a date",
+			}
+			Expect(todotxt.Parse(task.String())).To(Equal(task))
+		})
+	})
+})
@@ -0,0 +1,416 @@
+// Package ical reads and writes the VTODO components of iCalendar files as
+// defined by RFC 5545, one component at a time.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+// ProdID identifies this service as the producer of a calendar.
+const ProdID = "-//todo-service//todo-service//EN"
+
+// Statuses of a VTODO.
+const (
+	StatusNeedsAction = "NEEDS-ACTION"
+	StatusInProcess   = "IN-PROCESS"
+	StatusCompleted   = "COMPLETED"
+	StatusCancelled   = "CANCELLED"
+)
+
+const (
+	dateTimeFormat = "20060102T150405Z"
+	localFormat    = "20060102T150405"
+	dateFormat     = "20060102"
+
+	// maxLine is the longest a line may be in octets before it is folded.
+	maxLine = 75
+)
+
+// Todo is a VTODO component. Priority runs from 1, the highest, to 9, with 0
+// for none. RelatedTo is the UID of the parent of the todo. Invalid lists the
+// properties of a decoded VTODO whose value couldn't be read.
+type Todo struct {
+	UID          string
+	Summary      string
+	Status       string
+	Completed    *time.Time
+	Due          *time.Time
+	RRule        string
+	Priority     int
+	Categories   []string
+	RelatedTo    string
+	Created      *time.Time
+	LastModified *time.Time
+	Stamp        time.Time
+	Invalid      []string
+}
+
+// Encoder writes a VCALENDAR of VTODO components.
+type Encoder struct {
+	w       *bufio.Writer
+	name    string
+	started bool
+	err     error
+}
+
+// NewEncoder writes a calendar to w. name becomes the display name of the
+// calendar when it is not empty.
+func NewEncoder(w io.Writer, name string) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w), name: name}
+}
+
+// Encode writes a VTODO, after the header of the calendar for the first one.
+func (e *Encoder) Encode(todo *Todo) error {
+	e.start()
+
+	e.line("BEGIN", "VTODO")
+	e.line("UID", escape(todo.UID))
+	e.line("DTSTAMP", todo.Stamp.UTC().Format(dateTimeFormat))
+	if todo.Created != nil {
+		e.line("CREATED", todo.Created.UTC().Format(dateTimeFormat))
+	}
+	if todo.LastModified != nil {
+		e.line("LAST-MODIFIED", todo.LastModified.UTC().Format(dateTimeFormat))
+	}
+	e.line("SUMMARY", escape(todo.Summary))
+	if todo.Status != "" {
+		e.line("STATUS", todo.Status)
+	}
+	if todo.Completed != nil {
+		e.line("COMPLETED", todo.Completed.UTC().Format(dateTimeFormat))
+	}
+	if todo.Due != nil {
+		e.line("DUE", todo.Due.UTC().Format(dateTimeFormat))
+	}
+	if todo.RRule != "" {
+		e.line("RRULE", todo.RRule)
+	}
+	if todo.Priority > 0 {
+		e.line("PRIORITY", strconv.Itoa(todo.Priority))
+	}
+	if len(todo.Categories) > 0 {
+		categories := make([]string, len(todo.Categories))
+		for i, category := range todo.Categories {
+			categories[i] = escape(category)
+		}
+		e.line("CATEGORIES", strings.Join(categories, ","))
+	}
+	if todo.RelatedTo != "" {
+		e.line("RELATED-TO;RELTYPE=PARENT", escape(todo.RelatedTo))
+	}
+	e.line("END", "VTODO")
+
+	return e.err
+}
+
+// Close ends the calendar and flushes it to the writer.
+func (e *Encoder) Close() error {
+	e.start()
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+func (e *Encoder) start() {
+	if e.started {
+		return
+	}
+
+	e.started = true
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", ProdID)
+	e.line("CALSCALE", "GREGORIAN")
+	if e.name != "" {
+		e.line("X-WR-CALNAME", escape(e.name))
+	}
+}
+
+// line writes a content line, folded after maxLine octets without breaking
+// a character apart.
+func (e *Encoder) line(name string, value string) {
+	if e.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > maxLine {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+
+	_, e.err = e.w.WriteString(b.String())
+}
+
+// Decoder reads the VTODO components of a calendar and skips every other
+// component.
+type Decoder struct {
+	r   *bufio.Reader
+	loc *time.Location
+	// next is a line read ahead while unfolding.
+	next *string
+}
+
+// NewDecoder reads a calendar from r. Floating times and dates without a
+// time zone are read in loc.
+func NewDecoder(r io.Reader, loc *time.Location) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), loc: loc}
+}
+
+// Next returns the next VTODO of the calendar, or io.EOF after the last one.
+func (d *Decoder) Next() (*Todo, error) {
+	depth := 0
+	for {
+		line, err := d.line()
+		if err != nil {
+			return nil, err
+		}
+
+		name, _, value, err := splitLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO") && depth == 0:
+			return d.todo()
+		case name == "BEGIN" && !strings.EqualFold(value, "VCALENDAR"):
+			depth++
+		case name == "END" && !strings.EqualFold(value, "VCALENDAR") && depth > 0:
+			depth--
+		}
+	}
+}
+
+func (d *Decoder) todo() (*Todo, error) {
+	todo := &Todo{}
+	depth := 0
+	for {
+		line, err := d.line()
+		if err == io.EOF {
+			return nil, ErrInvalidCalendar
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name, params, value, err := splitLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "BEGIN" {
+			depth++
+			continue
+		}
+		if name == "END" {
+			if depth > 0 {
+				depth--
+				continue
+			}
+			return todo, nil
+		}
+		if depth > 0 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			todo.UID = unescape(value)
+		case "SUMMARY":
+			todo.Summary = unescape(value)
+		case "STATUS":
+			todo.Status = strings.ToUpper(value)
+		case "RRULE":
+			todo.RRule = value
+		case "PRIORITY":
+			priority, err := strconv.Atoi(value)
+			if err != nil || priority < 0 || priority > 9 {
+				todo.Invalid = append(todo.Invalid, name)
+				continue
+			}
+			todo.Priority = priority
+		case "CATEGORIES":
+			for _, category := range splitList(value) {
+				todo.Categories = append(todo.Categories, unescape(category))
+			}
+		case "RELATED-TO":
+			if reltype := params["RELTYPE"]; reltype == "" || strings.EqualFold(reltype, "PARENT") {
+				todo.RelatedTo = unescape(value)
+			}
+		case "COMPLETED", "DUE", "CREATED", "LAST-MODIFIED", "DTSTAMP":
+			t, err := d.time(params, value)
+			if err != nil {
+				todo.Invalid = append(todo.Invalid, name)
+				continue
+			}
+
+			switch name {
+			case "COMPLETED":
+				todo.Completed = &t
+			case "DUE":
+				todo.Due = &t
+			case "CREATED":
+				todo.Created = &t
+			case "LAST-MODIFIED":
+				todo.LastModified = &t
+			case "DTSTAMP":
+				todo.Stamp = t
+			}
+		}
+	}
+}
+
+// time reads a DATE or DATE-TIME value in UTC, in the zone of its TZID or
+// otherwise in the location of the decoder.
+func (d *Decoder) time(params map[string]string, value string) (time.Time, error) {
+	loc := d.loc
+	if tzid := params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(strings.Trim(tzid, "/")); err == nil {
+			loc = tz
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(dateTimeFormat, value)
+	case len(value) == len(dateFormat) || strings.EqualFold(params["VALUE"], "DATE"):
+		return time.ParseInLocation(dateFormat, value, loc)
+	default:
+		return time.ParseInLocation(localFormat, value, loc)
+	}
+}
+
+// line returns the next unfolded content line, skipping empty lines.
+func (d *Decoder) line() (string, error) {
+	var line string
+	if d.next != nil {
+		line, d.next = *d.next, nil
+	} else {
+		raw, err := d.raw()
+		if err != nil {
+			return "", err
+		}
+		line = raw
+	}
+
+	for {
+		raw, err := d.raw()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t") {
+			line += raw[1:]
+			continue
+		}
+
+		d.next = &raw
+		break
+	}
+
+	if line == "" {
+		return d.line()
+	}
+
+	return line, nil
+}
+
+func (d *Decoder) raw() (string, error) {
+	raw, err := d.r.ReadString('\n')
+	if err == io.EOF && raw != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(raw, "\r\n"), nil
+}
+
+// splitLine splits a content line into its upper-cased name, its parameters
+// and its value.
+func splitLine(line string) (string, map[string]string, string, error) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", ErrInvalidCalendar
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		if eq := strings.IndexByte(param, '='); eq > 0 {
+			params[strings.ToUpper(param[:eq])] = strings.Trim(param[eq+1:], `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, line[colon+1:], nil
+}
+
+// splitList splits a list value at the commas that aren't escaped.
+func splitList(value string) []string {
+	var items []string
+	var item strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			item.WriteRune('\\')
+			item.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			items = append(items, item.String())
+			item.Reset()
+		default:
+			item.WriteRune(r)
+		}
+	}
+
+	return append(items, item.String())
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func escape(text string) string {
+	return escaper.Replace(text)
+}
+
+func unescape(text string) string {
+	return unescaper.Replace(text)
+}
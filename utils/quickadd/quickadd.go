@@ -0,0 +1,577 @@
+// Package quickadd extracts the details of a todo from free text, so that
+// "Pay rent tomorrow 9am !high #finance every month" becomes a todo "Pay
+// rent" due tomorrow at 9am with a high priority, the tag finance and a
+// monthly recurrence.
+package quickadd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Priorities the text can set with !low, !medium and !high or their short
+// forms.
+const (
+	PriorityLow    = "LOW"
+	PriorityMedium = "MEDIUM"
+	PriorityHigh   = "HIGH"
+)
+
+// DefaultHour is the hour of the day a todo is due when the text only gives
+// its date.
+const DefaultHour = 9
+
+// Result is what Parse found in the text. Text is what is left once the
+// details are taken out.
+type Result struct {
+	Text     string
+	DueAt    *time.Time
+	Priority string
+	Tags     []string
+	RRule    string
+}
+
+var priorities = map[string]string{
+	"!low": PriorityLow, "!l": PriorityLow, "!1": PriorityLow,
+	"!medium": PriorityMedium, "!med": PriorityMedium, "!m": PriorityMedium, "!2": PriorityMedium, "!!": PriorityMedium,
+	"!high": PriorityHigh, "!h": PriorityHigh, "!3": PriorityHigh, "!!!": PriorityHigh,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "mon": time.Monday, "tuesday": time.Tuesday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January, "february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March, "april": time.April, "apr": time.April, "may": time.May,
+	"june": time.June, "jun": time.June, "july": time.July, "jul": time.July, "august": time.August,
+	"aug": time.August, "september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October, "november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var units = map[string]string{
+	"minute": "minute", "minutes": "minute", "min": "minute", "mins": "minute",
+	"hour": "hour", "hours": "hour", "hr": "hour", "hrs": "hour",
+	"day": "day", "days": "day", "week": "week", "weeks": "week",
+	"month": "month", "months": "month", "year": "year", "years": "year",
+}
+
+var frequencies = map[string]string{
+	"day": "DAILY", "week": "WEEKLY", "month": "MONTHLY", "year": "YEARLY",
+}
+
+var byDay = map[time.Weekday]string{
+	time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+	time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+}
+
+// connectors are dropped along with the date or time they introduce.
+var connectors = map[string]bool{"at": true, "on": true, "by": true, "due": true}
+
+var (
+	tagPattern   = regexp.MustCompile(`^#([\p{L}\p{N}_\-/]+)$`)
+	isoDate      = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	clock12      = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	clock24      = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	bareHour     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?$`)
+	dayOfMonth   = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?,?$`)
+	yearPattern  = regexp.MustCompile(`^(\d{4})$`)
+	countPattern = regexp.MustCompile(`^\d{1,3}$`)
+)
+
+type parser struct {
+	words []string
+	lower []string
+	used  []bool
+
+	now time.Time
+	loc *time.Location
+
+	date    *time.Time
+	clock   *[2]int
+	exact   *time.Time
+	weekday []time.Weekday
+
+	result Result
+}
+
+// Parse extracts the due date and time, priority, tags and recurrence from
+// text. Dates and times are read in loc relative to now. A time without a
+// date is due today, or tomorrow once it has passed, and a date without a
+// time is due at DefaultHour.
+func Parse(text string, now time.Time, loc *time.Location) Result {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	p := &parser{
+		words: strings.Fields(text),
+		now:   now.In(loc),
+		loc:   loc,
+	}
+	p.lower = make([]string, len(p.words))
+	p.used = make([]bool, len(p.words))
+	for i, word := range p.words {
+		p.lower[i] = strings.ToLower(word)
+	}
+
+	for i := 0; i < len(p.words); {
+		n, when := p.match(i)
+		if n == 0 {
+			i++
+			continue
+		}
+
+		if when && i > 0 && !p.used[i-1] && connectors[p.lower[i-1]] {
+			p.used[i-1] = true
+		}
+		for k := i; k < i+n; k++ {
+			p.used[k] = true
+		}
+		i += n
+	}
+
+	left := make([]string, 0, len(p.words))
+	for i, word := range p.words {
+		if !p.used[i] {
+			left = append(left, word)
+		}
+	}
+
+	p.result.Text = strings.Join(left, " ")
+	if p.result.Text == "" {
+		p.result.Text = strings.TrimSpace(text)
+	}
+	p.result.DueAt = p.due()
+
+	return p.result
+}
+
+// match tries every kind of detail at word i and returns the number of words
+// it took, and whether they were a date or a time.
+func (p *parser) match(i int) (int, bool) {
+	word := p.lower[i]
+
+	if priority, ok := priorities[word]; ok {
+		p.result.Priority = priority
+		return 1, false
+	}
+
+	if m := tagPattern.FindStringSubmatch(strings.TrimRight(p.words[i], ",.")); m != nil {
+		p.addTag(strings.ToLower(m[1]))
+		return 1, false
+	}
+
+	if word == "every" {
+		return p.recurrence(i), false
+	}
+
+	for _, match := range []func(int) int{p.relative, p.named, p.weekdayDate, p.iso, p.monthDay, p.dayMonth, p.timeOfDay} {
+		if n := match(i); n > 0 {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+func (p *parser) addTag(tag string) {
+	for _, existing := range p.result.Tags {
+		if existing == tag {
+			return
+		}
+	}
+
+	p.result.Tags = append(p.result.Tags, tag)
+}
+
+func (p *parser) word(i int) string {
+	if i >= len(p.lower) || p.used[i] {
+		return ""
+	}
+
+	return strings.TrimRight(p.lower[i], ",.")
+}
+
+// recurrence reads "every [N|other] day|week|month|year", "every weekday"
+// and "every monday [and|, friday]...".
+func (p *parser) recurrence(i int) int {
+	n, interval := 1, 1
+	switch word := p.word(i + 1); {
+	case word == "other":
+		n, interval = 2, 2
+	case countPattern.MatchString(word):
+		count, _ := strconv.Atoi(word)
+		if count < 1 {
+			return 0
+		}
+		n, interval = 2, count
+	}
+
+	unit := p.word(i + n)
+	if unit == "weekday" || unit == "weekdays" {
+		p.weekday = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		p.result.RRule = rule("WEEKLY", interval, p.weekday)
+		return n + 1
+	}
+
+	if day, ok := weekdays[unit]; ok {
+		days := []time.Weekday{day}
+		taken := n + 1
+		for {
+			next := p.word(i + taken)
+			if next == "and" || next == "&" {
+				if another, ok := weekdays[p.word(i+taken+1)]; ok {
+					days = append(days, another)
+					taken += 2
+					continue
+				}
+				break
+			}
+
+			another, ok := weekdays[next]
+			if !ok {
+				break
+			}
+			days = append(days, another)
+			taken++
+		}
+
+		p.weekday = days
+		p.result.RRule = rule("WEEKLY", interval, days)
+		return taken
+	}
+
+	if freq, ok := frequencies[units[unit]]; ok && units[unit] != "minute" && units[unit] != "hour" {
+		p.result.RRule = rule(freq, interval, nil)
+		return n + 1
+	}
+
+	return 0
+}
+
+func rule(freq string, interval int, days []time.Weekday) string {
+	r := "FREQ=" + freq
+	if interval > 1 {
+		r += fmt.Sprintf(";INTERVAL=%d", interval)
+	}
+
+	if len(days) > 0 {
+		codes := make([]string, 0, len(days))
+		for _, day := range days {
+			codes = append(codes, byDay[day])
+		}
+		r += ";BYDAY=" + strings.Join(codes, ",")
+	}
+
+	return r
+}
+
+// relative reads "in N|a|an minutes|hours|days|weeks|months|years".
+func (p *parser) relative(i int) int {
+	if p.word(i) != "in" {
+		return 0
+	}
+
+	amount := p.word(i + 1)
+	count := 1
+	if amount != "a" && amount != "an" {
+		if !countPattern.MatchString(amount) {
+			return 0
+		}
+		count, _ = strconv.Atoi(amount)
+	}
+
+	switch units[p.word(i+2)] {
+	case "minute":
+		p.setExact(p.now.Add(time.Duration(count) * time.Minute))
+	case "hour":
+		p.setExact(p.now.Add(time.Duration(count) * time.Hour))
+	case "day":
+		p.setDate(p.today().AddDate(0, 0, count))
+	case "week":
+		p.setDate(p.today().AddDate(0, 0, 7*count))
+	case "month":
+		p.setDate(p.today().AddDate(0, count, 0))
+	case "year":
+		p.setDate(p.today().AddDate(count, 0, 0))
+	default:
+		return 0
+	}
+
+	return 3
+}
+
+// named reads today, tonight, tomorrow, next week and next month.
+func (p *parser) named(i int) int {
+	switch p.word(i) {
+	case "today":
+		p.setDate(p.today())
+		return 1
+	case "tonight":
+		p.setDate(p.today())
+		if p.clock == nil {
+			p.clock = &[2]int{20, 0}
+		}
+		return 1
+	case "tomorrow", "tmrw", "tmr":
+		p.setDate(p.today().AddDate(0, 0, 1))
+		return 1
+	case "next":
+		switch p.word(i + 1) {
+		case "week":
+			today := p.today()
+			days := (int(time.Monday) - int(today.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			p.setDate(today.AddDate(0, 0, days))
+			return 2
+		case "month":
+			today := p.today()
+			p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, p.loc))
+			return 2
+		}
+	}
+
+	return 0
+}
+
+// weekdayDate reads "[next] monday", the next such day after today.
+func (p *parser) weekdayDate(i int) int {
+	n := 0
+	if p.word(i) == "next" {
+		n = 1
+	}
+
+	day, ok := weekdays[p.word(i+n)]
+	if !ok {
+		return 0
+	}
+
+	today := p.today()
+	days := (int(day) - int(today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	p.setDate(today.AddDate(0, 0, days))
+
+	return n + 1
+}
+
+// iso reads 2024-05-06.
+func (p *parser) iso(i int) int {
+	m := isoDate.FindStringSubmatch(p.word(i))
+	if m == nil {
+		return 0
+	}
+
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	date, ok := p.calendarDate(year, time.Month(month), day)
+	if !ok {
+		return 0
+	}
+
+	p.setDate(date)
+	return 1
+}
+
+// monthDay reads "may 6[th][,] [2025]".
+func (p *parser) monthDay(i int) int {
+	month, ok := months[p.word(i)]
+	if !ok {
+		return 0
+	}
+
+	m := dayOfMonth.FindStringSubmatch(p.word(i + 1))
+	if m == nil {
+		return 0
+	}
+
+	return p.dayOf(i+2, month, m[1], 2)
+}
+
+// dayMonth reads "6[th] may [2025]".
+func (p *parser) dayMonth(i int) int {
+	m := dayOfMonth.FindStringSubmatch(p.word(i))
+	if m == nil {
+		return 0
+	}
+
+	month, ok := months[p.word(i+1)]
+	if !ok {
+		return 0
+	}
+
+	return p.dayOf(i+2, month, m[1], 2)
+}
+
+// dayOf sets the date of a day of a month, with the year at word i if there
+// is one. Without a year it is the next such day from today on.
+func (p *parser) dayOf(i int, month time.Month, day string, n int) int {
+	d, _ := strconv.Atoi(day)
+
+	if m := yearPattern.FindStringSubmatch(p.word(i)); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		date, ok := p.calendarDate(year, month, d)
+		if !ok {
+			return 0
+		}
+		p.setDate(date)
+		return n + 1
+	}
+
+	today := p.today()
+	date, ok := p.calendarDate(today.Year(), month, d)
+	if !ok {
+		return 0
+	}
+	if date.Before(today) {
+		if date, ok = p.calendarDate(today.Year()+1, month, d); !ok {
+			return 0
+		}
+	}
+
+	p.setDate(date)
+	return n
+}
+
+// calendarDate returns midnight of a date, which has to exist.
+func (p *parser) calendarDate(year int, month time.Month, day int) (time.Time, bool) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, p.loc)
+	if date.Year() != year || date.Month() != month || date.Day() != day {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+// timeOfDay reads 9am, 9:30pm, 9 am, 21:00, noon and midnight.
+func (p *parser) timeOfDay(i int) int {
+	word := p.word(i)
+
+	switch word {
+	case "noon":
+		p.clock = &[2]int{12, 0}
+		return 1
+	case "midnight":
+		p.clock = &[2]int{0, 0}
+		return 1
+	}
+
+	if m := clock12.FindStringSubmatch(word); m != nil {
+		return p.setClock12(m[1], m[2], m[3], 1)
+	}
+
+	if m := clock24.FindStringSubmatch(word); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return 0
+		}
+		p.clock = &[2]int{hour, minute}
+		return 1
+	}
+
+	if m := bareHour.FindStringSubmatch(word); m != nil {
+		if suffix := p.word(i + 1); suffix == "am" || suffix == "pm" {
+			return p.setClock12(m[1], m[2], suffix, 2)
+		}
+	}
+
+	return 0
+}
+
+func (p *parser) setClock12(h string, min string, suffix string, n int) int {
+	hour, _ := strconv.Atoi(h)
+	minute := 0
+	if min != "" {
+		minute, _ = strconv.Atoi(min)
+	}
+
+	if hour < 1 || hour > 12 || minute > 59 {
+		return 0
+	}
+
+	hour %= 12
+	if suffix == "pm" {
+		hour += 12
+	}
+
+	p.clock = &[2]int{hour, minute}
+	return n
+}
+
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.loc)
+}
+
+func (p *parser) setDate(date time.Time) {
+	p.date, p.exact = &date, nil
+}
+
+func (p *parser) setExact(at time.Time) {
+	p.exact, p.date, p.clock = &at, nil, nil
+}
+
+// due puts the date and time that were found together.
+func (p *parser) due() *time.Time {
+	if p.exact != nil {
+		due := p.exact.Truncate(time.Minute)
+		return &due
+	}
+
+	if p.date == nil && p.clock == nil && p.result.RRule == "" {
+		return nil
+	}
+
+	clock := [2]int{DefaultHour, 0}
+	if p.clock != nil {
+		clock = *p.clock
+	}
+
+	at := func(date time.Time) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), clock[0], clock[1], 0, 0, p.loc)
+	}
+
+	if p.date != nil {
+		due := at(*p.date)
+		return &due
+	}
+
+	// Without a date the todo is due the next time the clock and the days
+	// of the recurrence come around.
+	date := p.today()
+	for days := 0; days < 8; days++ {
+		due := at(date.AddDate(0, 0, days))
+		if (p.clock == nil && p.weekday == nil || !due.Before(p.now)) && p.onWeekday(due) {
+			return &due
+		}
+	}
+
+	due := at(date)
+	return &due
+}
+
+func (p *parser) onWeekday(t time.Time) bool {
+	if len(p.weekday) == 0 {
+		return true
+	}
+
+	for _, day := range p.weekday {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+
+	return false
+}
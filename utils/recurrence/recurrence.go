@@ -0,0 +1,59 @@
+package recurrence
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Normalize validates an RFC 5545 RRULE and returns it in canonical form
+// without the "RRULE:" prefix. An empty rule stays empty.
+func Normalize(rule string) (string, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return "", nil
+	}
+
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return "", ErrInvalidRule
+	}
+
+	if _, err := rrule.NewRRule(*option); err != nil {
+		return "", ErrInvalidRule
+	}
+
+	return option.RRuleString(), nil
+}
+
+// Next returns the first occurrence of a series strictly after the given
+// time. The series starts at start and is expanded in loc, so occurrences
+// keep their wall-clock time across DST changes. The second result is false
+// once the series is exhausted by COUNT or UNTIL.
+func Next(rule string, start time.Time, after time.Time, loc *time.Location) (time.Time, bool, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	option, err := rrule.StrToROptionInLocation(rule, loc)
+	if err != nil {
+		return time.Time{}, false, ErrInvalidRule
+	}
+	option.Dtstart = start.In(loc)
+
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return time.Time{}, false, ErrInvalidRule
+	}
+
+	next := r.After(after.In(loc), false)
+	if next.IsZero() {
+		return time.Time{}, false, nil
+	}
+
+	return next, true, nil
+}
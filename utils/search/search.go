@@ -0,0 +1,76 @@
+// Package search turns what a user types into a search box into a Postgres
+// full-text query.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Query turns a user search into a to_tsquery expression. Words are ANDed,
+// "quoted words" must appear as a phrase, a trailing * matches a prefix and
+// a leading - excludes the term. Any other punctuation is dropped, so the
+// input can't smuggle tsquery operators into the query.
+func Query(input string) string {
+	var terms []string
+	for _, token := range Tokenize(input) {
+		negate := strings.HasPrefix(token, "-")
+		token = strings.TrimPrefix(token, "-")
+
+		prefix := strings.HasSuffix(token, "*")
+		words := strings.FieldsFunc(token, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+
+		if prefix {
+			words[len(words)-1] += ":*"
+		}
+
+		term := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			term = "(" + term + ")"
+		}
+		if negate {
+			term = "!" + term
+		}
+
+		terms = append(terms, term)
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// Tokenize splits the input on whitespace, keeping "quoted phrases"
+// together as one token.
+func Tokenize(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			if !quoted {
+				flush()
+			}
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
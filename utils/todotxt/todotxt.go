@@ -0,0 +1,232 @@
+// Package todotxt reads and writes todo.txt files, one task per line, as
+// described at https://github.com/todotxt/todo.txt. Parsing is lossless: a
+// task written back gives the line it was read from.
+package todotxt
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidFile = errors.New("invalid file")
+
+// DateFormat is the format of the dates of a task and of date extensions
+// like due:.
+const DateFormat = "2006-01-02"
+
+// maxLine is the longest line a file may have in bytes.
+const maxLine = 1 << 16
+
+// Task is one line of a todo.txt file. Completed is only read for a done
+// task, Created follows it. Dates have no zone and are read as midnight UTC.
+// Description is the rest of the line as it is, with its projects, contexts
+// and extensions.
+type Task struct {
+	Done        bool
+	Priority    string
+	Completed   *time.Time
+	Created     *time.Time
+	Description string
+}
+
+// Extension is a key:value pair of a description, like due:2030-05-01.
+type Extension struct {
+	Key   string
+	Value string
+}
+
+// Parse reads a line into a task. Every line is a task, a line that doesn't
+// start with a marker, priority or date only has a description.
+func Parse(line string) *Task {
+	task := &Task{}
+	rest := line
+
+	if strings.HasPrefix(rest, "x ") {
+		task.Done, rest = true, rest[2:]
+	}
+
+	if len(rest) >= 4 && rest[0] == '(' && rest[1] >= 'A' && rest[1] <= 'Z' && rest[2] == ')' && rest[3] == ' ' {
+		task.Priority, rest = rest[1:2], rest[4:]
+	}
+
+	if task.Done {
+		if date, after, ok := leadingDate(rest); ok {
+			task.Completed, rest = &date, after
+		}
+	}
+	if task.Completed != nil || !task.Done {
+		if date, after, ok := leadingDate(rest); ok {
+			task.Created, rest = &date, after
+		}
+	}
+
+	task.Description = rest
+	return task
+}
+
+// leadingDate reads the date a text starts with, as long as a space follows
+// it.
+func leadingDate(text string) (time.Time, string, bool) {
+	if len(text) <= len(DateFormat) || text[len(DateFormat)] != ' ' {
+		return time.Time{}, text, false
+	}
+
+	date, err := time.Parse(DateFormat, text[:len(DateFormat)])
+	if err != nil {
+		return time.Time{}, text, false
+	}
+
+	return date, text[len(DateFormat)+1:], true
+}
+
+// String writes the task as a line. A done task only has a creation date
+// with a completion date, as the format asks.
+func (t *Task) String() string {
+	var b strings.Builder
+	if t.Done {
+		b.WriteString("x ")
+	}
+	if t.Priority != "" {
+		b.WriteString("(" + t.Priority + ") ")
+	}
+	if t.Done && t.Completed != nil {
+		b.WriteString(t.Completed.Format(DateFormat) + " ")
+	}
+	if t.Created != nil && (!t.Done || t.Completed != nil) {
+		b.WriteString(t.Created.Format(DateFormat) + " ")
+	}
+	b.WriteString(t.Description)
+
+	return b.String()
+}
+
+// Projects returns the +project tags of the description without their +, in
+// the order they are written.
+func (t *Task) Projects() []string {
+	return t.words('+')
+}
+
+// Contexts returns the @context tags of the description without their @.
+func (t *Task) Contexts() []string {
+	return t.words('@')
+}
+
+func (t *Task) words(prefix byte) []string {
+	words := make([]string, 0)
+	for _, word := range strings.Fields(t.Description) {
+		if len(word) > 1 && word[0] == prefix {
+			words = append(words, word[1:])
+		}
+	}
+	return words
+}
+
+// Extensions returns the key:value pairs of the description. Neither side
+// may be empty or hold another colon, and links like https://example.com
+// aren't extensions.
+func (t *Task) Extensions() []Extension {
+	extensions := make([]Extension, 0)
+	for _, word := range strings.Fields(t.Description) {
+		if extension, ok := parseExtension(word); ok {
+			extensions = append(extensions, extension)
+		}
+	}
+	return extensions
+}
+
+// Extension returns the value of the first extension with a key.
+func (t *Task) Extension(key string) (string, bool) {
+	for _, extension := range t.Extensions() {
+		if extension.Key == key {
+			return extension.Value, true
+		}
+	}
+	return "", false
+}
+
+// Without returns the description without the extensions with one of the
+// keys, together with the space before each of them.
+func (t *Task) Without(keys ...string) string {
+	words := strings.Split(t.Description, " ")
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		if extension, ok := parseExtension(word); ok && contains(keys, extension.Key) {
+			continue
+		}
+		kept = append(kept, word)
+	}
+
+	return strings.Join(kept, " ")
+}
+
+func parseExtension(word string) (Extension, bool) {
+	i := strings.IndexByte(word, ':')
+	if i <= 0 || i == len(word)-1 || strings.IndexByte(word[i+1:], ':') >= 0 || strings.HasPrefix(word[i+1:], "//") {
+		return Extension{}, false
+	}
+
+	return Extension{Key: word[:i], Value: word[i+1:]}, true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Reader reads the tasks of a file and skips its blank lines.
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	return &Reader{scanner}
+}
+
+// Read returns the next task and io.EOF after the last one.
+func (r *Reader) Read() (*Task, error) {
+	for r.scanner.Scan() {
+		// Files saved on Windows end lines with \r\n and may start with a
+		// byte order mark.
+		line := strings.TrimSuffix(r.scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return Parse(strings.TrimPrefix(line, "\ufeff")), nil
+	}
+
+	if r.scanner.Err() != nil {
+		return nil, ErrInvalidFile
+	}
+	return nil, io.EOF
+}
+
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// Writer writes tasks one line each. Close flushes the file.
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{bufio.NewWriter(w)}
+}
+
+// Write writes a task as one line, line breaks of its description become
+// spaces.
+func (w *Writer) Write(task *Task) error {
+	_, err := w.w.WriteString(lineBreaks.Replace(task.String()) + "\n")
+	return err
+}
+
+func (w *Writer) Close() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,297 @@
+// Package transfer reads and writes lists of todos as JSON or CSV files, one
+// record at a time so that neither side has to hold the whole list.
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidFile = errors.New("invalid file")
+
+// Columns of an export. They are also the column names an import maps by
+// default.
+const (
+	ColumnID       = "id"
+	ColumnParentID = "parentId"
+	ColumnText     = "text"
+	ColumnDone     = "done"
+	ColumnDueAt    = "dueAt"
+	ColumnPriority = "priority"
+	ColumnTags     = "tags"
+	ColumnRRule    = "rrule"
+	ColumnCreated  = "created"
+)
+
+// Columns lists the columns of an export in the order of a CSV file.
+var Columns = []string{
+	ColumnID, ColumnParentID, ColumnText, ColumnDone, ColumnDueAt, ColumnPriority, ColumnTags, ColumnRRule, ColumnCreated,
+}
+
+// TagSeparator joins the tags of a todo within a CSV cell.
+const TagSeparator = ","
+
+// Record is one exported todo.
+type Record struct {
+	ID       string     `json:"id"`
+	ParentID string     `json:"parentId,omitempty"`
+	Text     string     `json:"text"`
+	Done     bool       `json:"done"`
+	DueAt    *time.Time `json:"dueAt,omitempty"`
+	Priority string     `json:"priority"`
+	Tags     []string   `json:"tags"`
+	RRule    string     `json:"rrule,omitempty"`
+	Created  time.Time  `json:"created"`
+}
+
+// Writer writes the records of an export. Close finishes the file and has to
+// be called even if there were no records.
+type Writer interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// Row is one record of an import, keyed by the columns of the file.
+type Row map[string]string
+
+// Reader reads the records of an import and returns io.EOF after the last
+// one.
+type Reader interface {
+	Read() (Row, error)
+}
+
+type jsonWriter struct {
+	w       io.Writer
+	written int
+}
+
+// NewJSONWriter writes records as a JSON array of objects.
+func NewJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{w: w}
+}
+
+func (jw *jsonWriter) Write(record *Record) error {
+	if record.Tags == nil {
+		record.Tags = []string{}
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if jw.written == 0 {
+		sep = "[\n"
+	}
+	jw.written++
+
+	if _, err := io.WriteString(jw.w, sep); err != nil {
+		return err
+	}
+	_, err = jw.w.Write(b)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	end := "\n]\n"
+	if jw.written == 0 {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter writes records as CSV with a header line of Columns.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *csvWriter) Write(record *Record) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	var due string
+	if record.DueAt != nil {
+		due = record.DueAt.UTC().Format(time.RFC3339)
+	}
+
+	return cw.w.Write([]string{
+		record.ID,
+		record.ParentID,
+		record.Text,
+		strconv.FormatBool(record.Done),
+		due,
+		record.Priority,
+		strings.Join(record.Tags, TagSeparator),
+		record.RRule,
+		record.Created.UTC().Format(time.RFC3339),
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.header {
+		return nil
+	}
+
+	cw.header = true
+	return cw.w.Write(Columns)
+}
+
+type jsonReader struct {
+	dec *json.Decoder
+}
+
+// NewJSONReader reads a JSON array of objects. Strings are taken as they
+// are, arrays are joined with TagSeparator and null is empty.
+func NewJSONReader(r io.Reader) (Reader, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	token, err := dec.Token()
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, ErrInvalidFile
+	}
+
+	return &jsonReader{dec}, nil
+}
+
+func (jr *jsonReader) Read() (Row, error) {
+	if !jr.dec.More() {
+		return nil, io.EOF
+	}
+
+	var object map[string]interface{}
+	if err := jr.dec.Decode(&object); err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	row := make(Row, len(object))
+	for key, value := range object {
+		text, err := jsonText(value)
+		if err != nil {
+			return nil, err
+		}
+		row[key] = text
+	}
+
+	return row, nil
+}
+
+func jsonText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			text, err := jsonText(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, text)
+		}
+		return strings.Join(parts, TagSeparator), nil
+	default:
+		return "", ErrInvalidFile
+	}
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+// NewCSVReader reads CSV whose first line names the columns.
+func NewCSVReader(r io.Reader) (Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	columns, err := cr.Read()
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	if len(columns) > 0 {
+		columns[0] = strings.TrimPrefix(columns[0], "\ufeff")
+	}
+	for i, column := range columns {
+		columns[i] = strings.TrimSpace(column)
+	}
+
+	return &csvReader{cr, columns}, nil
+}
+
+func (cr *csvReader) Read() (Row, error) {
+	values, err := cr.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	row := make(Row, len(cr.columns))
+	for i, column := range cr.columns {
+		if i < len(values) {
+			row[column] = values[i]
+		}
+	}
+
+	return row, nil
+}
+
+// Get returns the value of a column, matching its name case-insensitively.
+func (r Row) Get(column string) (string, bool) {
+	if value, ok := r[column]; ok {
+		return value, true
+	}
+
+	for key, value := range r {
+		if strings.EqualFold(key, column) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// SplitTags splits a cell of tags written with TagSeparator.
+func SplitTags(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	return strings.Split(value, TagSeparator)
+}